@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/joho/godotenv"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/actions"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/app"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/metrics"
+)
+
+// report is the decision for a finding, printed as JSON.
+type report struct {
+	FindingUID    string       `json:"finding_uid"`
+	Rule          string       `json:"rule,omitempty"`
+	Action        string       `json:"action"`
+	BlockedReason string       `json:"blocked_reason,omitempty"`
+	Close         bool         `json:"close"`
+	CloseAfter    int64        `json:"close_after_seconds,omitempty"`
+	StatusID      int32        `json:"status_id,omitempty"`
+	Status        string       `json:"status,omitempty"`
+	Comment       string       `json:"comment,omitempty"`
+	Notify        bool         `json:"notify"`
+	Notifier      string       `json:"notifier,omitempty"`
+	Rules         []ruleReport `json:"rules"`
+}
+
+type ruleReport struct {
+	Name    string `json:"name"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+
+	envpath := filepath.Join(".env")
+	if _, err := os.Stat(envpath); err == nil {
+		_ = godotenv.Load(envpath)
+	}
+
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, logger); err != nil {
+		logger.Error("failed to evaluate finding", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run evaluates one finding against the configured rules and prints the
+// decision. nothing is sent to aws or slack.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	path := fs.String("f", "", "path to a finding json file (default: stdin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var raw []byte
+	var err error
+	if *path != "" {
+		raw, err = os.ReadFile(*path)
+	} else {
+		raw, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read finding")
+	}
+
+	cfg, err := app.NewConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	a, err := app.NewEvaluator(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	finding, err := events.NewSecurityHubFinding(raw, cfg.FieldMappings...)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse finding")
+	}
+	finding.NormalizeStatus(cfg.StatusMappings)
+
+	d, results := a.Evaluate(finding)
+
+	r := report{
+		FindingUID:    finding.Metadata.UID,
+		Action:        "none",
+		BlockedReason: d.BlockedReason,
+		Close:         d.Close,
+		Notify:        d.Notify && cfg.Notifier != "",
+		Notifier:      cfg.Notifier,
+		Rules:         make([]ruleReport, 0, len(results)),
+	}
+	if d.Rule != nil {
+		r.Rule = d.Rule.Name
+		r.Action = d.Action
+	}
+	if d.Action == metrics.ActionClosed {
+		r.StatusID = d.Rule.Action.StatusID
+		r.Status = a.StatusName(int(r.StatusID))
+		r.Comment = actions.FormatComment(cfg.CommentMode, finding.Comment, d.Rule.ActionComment(cfg.TicketURLTemplate, cfg.Locale), time.Now())
+
+		// a delayed close only notifies on the first match, and closes when
+		// the finding matches again after the delay
+		if d.Rule.Action.CloseAfterSeconds > 0 {
+			r.Action = metrics.ActionDelayed
+			r.Close = false
+			r.CloseAfter = d.Rule.Action.CloseAfterSeconds
+			r.Notify = cfg.Notifier != ""
+		}
+	}
+	for _, result := range results {
+		r.Rules = append(r.Rules, ruleReport{
+			Name:    result.Rule.Name,
+			Matched: result.Matched,
+			Reason:  result.Reason,
+		})
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,141 @@
+// Package main tests the eval command against fixture findings.
+//
+// Tests cover:
+// - Matched rule, close status and notification in the printed decision
+// - Per-rule explanation of the first failed filter
+// - Findings that match no rule
+// - Rules loaded from APP_AUTO_CLOSE_RULES_URL
+// - Delayed closes reported with their delay instead of as a close
+// - Uses fixtures/samples.json for realistic OCSF findings
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const evalRules = `[
+	{"name": "critical-only", "enabled": true, "filters": {"severity": ["Critical"]}, "action": {"status_id": 3}},
+	{"name": "close-runs-on", "enabled": true, "filters": {"resource_tags": [{"name": "provider", "value": "runs-on.com"}]}, "action": {"status_id": 5, "comment": "Expected runner behavior"}}
+]`
+
+func loadSample(t *testing.T, index int) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+
+	var findings []json.RawMessage
+	if err := json.Unmarshal(raw, &findings); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+	return findings[index]
+}
+
+func evaluate(t *testing.T, finding []byte) report {
+	t.Helper()
+	return evaluateRules(t, evalRules, finding)
+}
+
+func evaluateRules(t *testing.T, rules string, finding []byte) report {
+	t.Helper()
+
+	t.Setenv("APP_AUTO_CLOSE_RULES", rules)
+	t.Setenv("APP_NOTIFIER", "memory")
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := run(context.Background(), nil, bytes.NewReader(finding), &out, logger); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	var r report
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatalf("invalid report JSON: %v\n%s", err, out.String())
+	}
+	return r
+}
+
+// TestRun_MatchedFinding validates the decision printed for the runs-on.com
+// GuardDuty finding (fixtures/samples.json finding #3).
+func TestRun_MatchedFinding(t *testing.T) {
+	r := evaluate(t, loadSample(t, 2))
+
+	if r.Rule != "close-runs-on" || r.Action != "closed" || !r.Close {
+		t.Errorf("expected close by close-runs-on, got %+v", r)
+	}
+	if r.StatusID != 5 || r.Comment != "Expected runner behavior" {
+		t.Errorf("unexpected status or comment: %d, %q", r.StatusID, r.Comment)
+	}
+	if !r.Notify {
+		t.Error("expected notification for closed finding")
+	}
+
+	if len(r.Rules) != 2 {
+		t.Fatalf("expected 2 explained rules, got %d", len(r.Rules))
+	}
+	if r.Rules[0].Matched || r.Rules[0].Reason != "severity" {
+		t.Errorf("expected critical-only to fail on severity, got %+v", r.Rules[0])
+	}
+	if !r.Rules[1].Matched {
+		t.Errorf("expected close-runs-on to match, got %+v", r.Rules[1])
+	}
+}
+
+// TestRun_UnmatchedFinding validates that a finding matching no rule is
+// reported with no action and no close.
+func TestRun_UnmatchedFinding(t *testing.T) {
+	r := evaluate(t, loadSample(t, 0))
+
+	if r.Rule != "" || r.Action != "none" || r.Close {
+		t.Errorf("expected no rule to act, got %+v", r)
+	}
+	for _, rule := range r.Rules {
+		if rule.Matched {
+			t.Errorf("expected %s not to match", rule.Name)
+		}
+	}
+}
+
+// TestRun_URLRules validates that rules served from APP_AUTO_CLOSE_RULES_URL
+// are evaluated after the env rules.
+func TestRun_URLRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "close-guardduty", "enabled": true, "filters": {"product_name": ["GuardDuty"]}, "action": {"status_id": 3}}]`))
+	}))
+	defer server.Close()
+	t.Setenv("APP_AUTO_CLOSE_RULES_URL", server.URL+"/rules.json")
+
+	r := evaluate(t, loadSample(t, 0))
+
+	if len(r.Rules) != 3 || r.Rules[2].Name != "close-guardduty" {
+		t.Fatalf("expected the url rule after the env rules, got %+v", r.Rules)
+	}
+}
+
+// TestRun_DelayedClose validates that a rule with close_after_seconds is
+// reported as a delayed first match that notifies, not as a close.
+func TestRun_DelayedClose(t *testing.T) {
+	rules := `[{"name": "close-runs-on-later", "enabled": true, "filters": {"resource_tags": [{"name": "provider", "value": "runs-on.com"}]}, "action": {"status_id": 5, "close_after_seconds": 3600}, "skip_notification": true}]`
+	r := evaluateRules(t, rules, loadSample(t, 2))
+
+	if r.Rule != "close-runs-on-later" || r.Action != "delayed" || r.Close {
+		t.Errorf("expected a delayed close by close-runs-on-later, got %+v", r)
+	}
+	if r.CloseAfter != 3600 || r.StatusID != 5 {
+		t.Errorf("expected the delay and eventual status, got %d and %d", r.CloseAfter, r.StatusID)
+	}
+	if !r.Notify {
+		t.Error("expected the first match to notify")
+	}
+}
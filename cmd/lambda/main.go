@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"sync"
+	"time"
 
 	awsevents "github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -39,6 +40,9 @@ func LambdaHandler(ctx context.Context, evt awsevents.CloudWatchEvent) error {
 		}
 
 		a, initErr = app.New(ctx, cfg, logger)
+		if initErr == nil {
+			logger = a.Logger
+		}
 	})
 
 	if initErr != nil {
@@ -60,6 +64,19 @@ func LambdaHandler(ctx context.Context, evt awsevents.CloudWatchEvent) error {
 	return a.Process(ctx, input)
 }
 
+// shutdown flushes the app when lambda sends SIGTERM, which it only does when
+// an extension is registered.
+func shutdown() {
+	if a == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := a.Close(ctx); err != nil {
+		logger.Error("failed to flush app on shutdown", "error", err)
+	}
+}
+
 func main() {
-	lambda.Start(LambdaHandler)
+	lambda.StartWithOptions(LambdaHandler, lambda.WithEnableSIGTERM(shutdown))
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	awsevents "github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/cockroachdb/errors"
+	"github.com/joho/godotenv"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/app"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// processor is the part of the app replayed events are submitted to.
+type processor interface {
+	Process(ctx context.Context, evt events.SecurityHubEventInput) error
+}
+
+// result is printed as a JSON line for each replayed record.
+type result struct {
+	ID       string `json:"id"`
+	EventID  string `json:"event_id,omitempty"`
+	Replayed bool   `json:"replayed"`
+	Deleted  bool   `json:"deleted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// summary counts the outcome of a replay.
+type summary struct {
+	Succeeded int
+	Failed    int
+	Deleted   int
+}
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	envpath := filepath.Join(".env")
+	if _, err := os.Stat(envpath); err == nil {
+		_ = godotenv.Load(envpath)
+	}
+
+	if err := run(context.Background(), os.Args[1:], os.Stdout, logger); err != nil {
+		logger.Error("failed to replay dead-letter records", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run replays the failed events in a dead-letter queue or S3 prefix through
+// the app, with the same config as the deployed bot.
+func run(ctx context.Context, args []string, stdout io.Writer, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("replay-dlq", flag.ContinueOnError)
+	queueURL := fs.String("queue-url", "", "SQS dead-letter queue url")
+	bucket := fs.String("bucket", "", "S3 bucket holding one failed event per object")
+	prefix := fs.String("prefix", "", "S3 key prefix of the failed events")
+	limit := fs.Int("max", 100, "maximum number of records to replay")
+	deleteReplayed := fs.Bool("delete", false, "delete records that replayed successfully")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if (*queueURL == "") == (*bucket == "") {
+		return errors.New("set exactly one of -queue-url or -bucket")
+	}
+	if *limit <= 0 {
+		return errors.Newf("invalid -max: %d", *limit)
+	}
+
+	cfg, err := app.NewConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	a, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create app")
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load aws config - check credentials and region")
+	}
+
+	var src source
+	if *queueURL != "" {
+		src = newSQSSource(sqs.NewFromConfig(awsCfg), *queueURL)
+	} else {
+		src = newS3Source(s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+			if cfg.AWSEndpointS3 != "" {
+				o.BaseEndpoint = aws.String(cfg.AWSEndpointS3)
+			}
+		}), *bucket, *prefix)
+	}
+
+	sum, err := replay(ctx, src, a, *limit, *deleteReplayed, stdout, logger)
+	if closeErr := a.Close(ctx); closeErr != nil {
+		logger.Error("failed to flush app", "error", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("replayed dead-letter records",
+		"succeeded", sum.Succeeded,
+		"failed", sum.Failed,
+		"deleted", sum.Deleted)
+	if sum.Failed > 0 {
+		return errors.Newf("%d of %d records failed to replay", sum.Failed, sum.Succeeded+sum.Failed)
+	}
+	return nil
+}
+
+// replay processes each record and prints its result. a failed record is left
+// in the source, and so is every record unless deleteReplayed is set.
+func replay(ctx context.Context, src source, p processor, limit int, deleteReplayed bool, stdout io.Writer, logger *slog.Logger) (summary, error) {
+	var sum summary
+
+	records, err := src.Records(ctx, limit)
+	if err != nil && len(records) == 0 {
+		return sum, err
+	}
+	if err != nil {
+		logger.Warn("failed to read every record, replaying those read", "error", err, "count", len(records))
+	}
+
+	enc := json.NewEncoder(stdout)
+	for _, r := range records {
+		res := result{ID: r.ID}
+
+		evt, err := parseRecord(r.Body)
+		if err == nil {
+			res.EventID = evt.ID
+			err = p.Process(ctx, events.SecurityHubEventInput{
+				EventID:    evt.ID,
+				DetailType: evt.DetailType,
+				Detail:     evt.Detail,
+			})
+		}
+
+		if err != nil {
+			logger.Error("failed to replay record", "id", r.ID, "event_id", res.EventID, "error", err)
+			res.Error = err.Error()
+			sum.Failed++
+		} else {
+			res.Replayed = true
+			sum.Succeeded++
+
+			if deleteReplayed {
+				if err := src.Delete(ctx, r); err != nil {
+					logger.Warn("failed to delete replayed record", "id", r.ID, "error", err)
+					res.Error = err.Error()
+				} else {
+					res.Deleted = true
+					sum.Deleted++
+				}
+			}
+		}
+
+		if err := enc.Encode(res); err != nil {
+			return sum, errors.Wrap(err, "failed to write result")
+		}
+	}
+	return sum, nil
+}
+
+// parseRecord reads the EventBridge event in a dead-letter record. records
+// from a Lambda on-failure destination wrap it in requestPayload, while
+// EventBridge and Lambda dead-letter queues hold the event itself.
+func parseRecord(body []byte) (awsevents.CloudWatchEvent, error) {
+	var wrapper struct {
+		RequestPayload json.RawMessage `json:"requestPayload"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		return awsevents.CloudWatchEvent{}, errors.Wrap(err, "invalid record")
+	}
+	if len(wrapper.RequestPayload) > 0 && !bytes.Equal(wrapper.RequestPayload, []byte("null")) {
+		body = wrapper.RequestPayload
+	}
+
+	var evt awsevents.CloudWatchEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return evt, errors.Wrap(err, "invalid event")
+	}
+	if len(evt.Detail) == 0 {
+		return evt, errors.New("record has no event detail")
+	}
+	return evt, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/cockroachdb/errors"
+)
+
+// SQSClient is the subset of the SQS API used to drain a dead-letter queue.
+type SQSClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// S3Client is the subset of the S3 API used to read dead-letter objects.
+type S3Client interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// record is one failed event read from a dead-letter source.
+type record struct {
+	// ID is the SQS message id or S3 key
+	ID   string
+	Body []byte
+
+	receiptHandle string
+}
+
+// source reads failed events and removes those that replayed successfully.
+type source interface {
+	Records(ctx context.Context, limit int) ([]record, error)
+	Delete(ctx context.Context, r record) error
+}
+
+// sqsSource reads a dead-letter queue. messages that aren't deleted become
+// visible again after the queue's visibility timeout.
+type sqsSource struct {
+	client   SQSClient
+	queueURL string
+}
+
+func newSQSSource(client SQSClient, queueURL string) *sqsSource {
+	return &sqsSource{client: client, queueURL: queueURL}
+}
+
+// Records receives messages until the queue returns none or limit is reached.
+func (s *sqsSource) Records(ctx context.Context, limit int) ([]record, error) {
+	var records []record
+	for len(records) < limit {
+		out, err := s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: int32(min(10, limit-len(records))),
+			WaitTimeSeconds:     1,
+		})
+		if err != nil {
+			return records, errors.Wrapf(err, "failed to receive messages from %s", s.queueURL)
+		}
+		if len(out.Messages) == 0 {
+			break
+		}
+		for _, msg := range out.Messages {
+			records = append(records, record{
+				ID:            aws.ToString(msg.MessageId),
+				Body:          []byte(aws.ToString(msg.Body)),
+				receiptHandle: aws.ToString(msg.ReceiptHandle),
+			})
+		}
+	}
+	return records, nil
+}
+
+func (s *sqsSource) Delete(ctx context.Context, r record) error {
+	_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: aws.String(r.receiptHandle),
+	})
+	return errors.Wrapf(err, "failed to delete message %s", r.ID)
+}
+
+// s3Source reads one failed event per object under a prefix, such as the
+// records of a Lambda on-failure destination.
+type s3Source struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+func newS3Source(client S3Client, bucket, prefix string) *s3Source {
+	return &s3Source{client: client, bucket: bucket, prefix: prefix}
+}
+
+// Records reads objects in key order until limit is reached.
+func (s *s3Source) Records(ctx context.Context, limit int) ([]record, error) {
+	var records []record
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+	for paginator.HasMorePages() && len(records) < limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return records, errors.Wrapf(err, "failed to list s3://%s/%s", s.bucket, s.prefix)
+		}
+		for _, obj := range page.Contents {
+			if len(records) >= limit {
+				break
+			}
+			key := aws.ToString(obj.Key)
+			body, err := s.get(ctx, key)
+			if err != nil {
+				return records, err
+			}
+			records = append(records, record{ID: key, Body: body})
+		}
+	}
+	return records, nil
+}
+
+func (s *s3Source) get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get s3://%s/%s", s.bucket, key)
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	return body, errors.Wrapf(err, "failed to read s3://%s/%s", s.bucket, key)
+}
+
+func (s *s3Source) Delete(ctx context.Context, r record) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(r.ID),
+	})
+	return errors.Wrapf(err, "failed to delete s3://%s/%s", s.bucket, r.ID)
+}
@@ -1,23 +1,58 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 
+	awsevents "github.com/aws/aws-lambda-go/events"
+	"github.com/cockroachdb/errors"
 	"github.com/joho/godotenv"
 
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/app"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/decisionlog"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/notifiers"
 )
 
+// result is printed as a JSON line for each event read from stdin.
+type result struct {
+	EventID   string              `json:"event_id"`
+	Decisions []decisionlog.Entry `json:"decisions"`
+	Error     string              `json:"error,omitempty"`
+}
+
 func main() {
 	ctx := context.Background()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run processes the fixture samples, or with -stdin the EventBridge events
+// piped in. stdin mode logs to stderr so stdout only has the results. with
+// -continue-on-error a failed sample doesn't stop the run, which fails after
+// every sample is processed.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("sample", flag.ContinueOnError)
+	fromStdin := fs.Bool("stdin", false, "process EventBridge events from stdin instead of the fixtures")
+	continueOnError := fs.Bool("continue-on-error", false, "process every sample and fail at the end with a summary")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logOutput := stdout
+	if *fromStdin {
+		logOutput = stderr
+	}
+	logger := slog.New(slog.NewTextHandler(logOutput, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	}))
 
@@ -30,30 +65,57 @@ func main() {
 	cfg, err := app.NewConfig()
 	if err != nil {
 		logger.Error("failed to load config", "error", err)
-		os.Exit(1)
+		return err
 	}
 
 	a, err := app.New(ctx, cfg, logger)
 	if err != nil {
 		logger.Error("failed to create app", "error", err)
-		os.Exit(1)
+		return err
 	}
 
-	path := filepath.Join("fixtures", "samples.json")
+	if *fromStdin {
+		err = processStdin(ctx, a, stdin, stdout, logger)
+	} else {
+		err = processSamples(ctx, a, filepath.Join("fixtures", "samples.json"), *continueOnError, stdout, logger)
+	}
+	if err != nil && !*continueOnError {
+		return err
+	}
+
+	if err := a.Close(ctx); err != nil {
+		logger.Error("failed to flush app", "error", err)
+		return err
+	}
+
+	if memory, ok := a.Notifier.(*notifiers.MemoryNotifier); ok && !*fromStdin {
+		for _, finding := range memory.Findings() {
+			fmt.Fprintf(stdout, "notified: [%s] %s (%s)\n", finding.Severity, finding.FindingInfo.Title, finding.Metadata.UID)
+		}
+	}
+	return err
+}
+
+// processSamples processes each finding in the fixtures file at path as its
+// own event. it stops at the first failed sample unless continueOnError is
+// set, in which case the failures are summarized once all samples ran.
+func processSamples(ctx context.Context, a *app.App, path string, continueOnError bool, stdout io.Writer, logger *slog.Logger) error {
 	raw, err := os.ReadFile(path)
 	if err != nil {
 		logger.Error("failed to read fixtures", "error", err, "path", path)
-		os.Exit(1)
+		return err
 	}
 
 	var findings []json.RawMessage
 	if err := json.Unmarshal(raw, &findings); err != nil {
 		logger.Error("failed to unmarshal fixtures", "error", err)
-		os.Exit(1)
+		return err
 	}
 
 	logger.Info("processing samples", "count", len(findings))
 
+	var failed []int
+	var errs []error
 	for i, finding := range findings {
 		detail := map[string]any{
 			"findings": []json.RawMessage{finding},
@@ -61,7 +123,7 @@ func main() {
 		detailBytes, err := json.Marshal(detail)
 		if err != nil {
 			logger.Error("failed to marshal detail", "error", err, "sample", i)
-			os.Exit(1)
+			return err
 		}
 
 		evt := events.SecurityHubEventInput{
@@ -72,8 +134,99 @@ func main() {
 
 		if err := a.Process(ctx, evt); err != nil {
 			logger.Error("failed to process sample", "error", err, "sample", i)
-			os.Exit(1)
+			if !continueOnError {
+				return err
+			}
+			failed = append(failed, i)
+			errs = append(errs, errors.Wrapf(err, "sample %d", i))
+			continue
 		}
 		logger.Info("processed sample successfully", "sample", i)
 	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(stdout, "failed: %d of %d samples %v\n", len(failed), len(findings), failed)
+		for _, err := range errs {
+			fmt.Fprintf(stdout, "  %v\n", err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// processStdin processes every event on stdin and prints a result line with
+// the decisions for each. a failed event is reported in its result and fails
+// the run once the remaining events are processed.
+func processStdin(ctx context.Context, a *app.App, stdin io.Reader, stdout io.Writer, logger *slog.Logger) error {
+	evts, err := readEvents(stdin)
+	if err != nil {
+		logger.Error("failed to read events from stdin", "error", err)
+		return err
+	}
+
+	decisions := decisionlog.NewMemorySink()
+	if a.Decisions != nil {
+		a.Decisions = decisionlog.MultiSink{a.Decisions, decisions}
+	} else {
+		a.Decisions = decisions
+	}
+
+	logger.Info("processing events from stdin", "count", len(evts))
+
+	enc := json.NewEncoder(stdout)
+	var errs []error
+	for _, evt := range evts {
+		seen := len(decisions.Entries())
+		err := a.Process(ctx, events.SecurityHubEventInput{
+			EventID:    evt.ID,
+			DetailType: evt.DetailType,
+			Detail:     evt.Detail,
+		})
+
+		r := result{EventID: evt.ID, Decisions: decisions.Entries()[seen:]}
+		if err != nil {
+			logger.Error("failed to process event", "event_id", evt.ID, "error", err)
+			r.Error = err.Error()
+			errs = append(errs, err)
+		}
+		if err := enc.Encode(r); err != nil {
+			return errors.Wrap(err, "failed to write result")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readEvents reads a single EventBridge event, a JSON array of events, or
+// several of either separated by whitespace, as from `aws events` captures.
+func readEvents(r io.Reader) ([]awsevents.CloudWatchEvent, error) {
+	dec := json.NewDecoder(r)
+
+	var evts []awsevents.CloudWatchEvent
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "invalid event %d", len(evts))
+		}
+
+		if bytes.HasPrefix(raw, []byte("[")) {
+			var batch []awsevents.CloudWatchEvent
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, errors.Wrapf(err, "invalid event array after event %d", len(evts))
+			}
+			evts = append(evts, batch...)
+			continue
+		}
+
+		var evt awsevents.CloudWatchEvent
+		if err := json.Unmarshal(raw, &evt); err != nil {
+			return nil, errors.Wrapf(err, "invalid event %d", len(evts))
+		}
+		evts = append(evts, evt)
+	}
+
+	if len(evts) == 0 {
+		return nil, errors.New("no events on stdin")
+	}
+	return evts, nil
 }
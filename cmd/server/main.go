@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/app"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/decisionlog"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/metrics"
+)
+
+const defaultAddr = ":8080"
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, logger); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, logger *slog.Logger) error {
+	cfg, err := app.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.DebugEnabled {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+	}
+
+	a, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	logger = a.Logger
+
+	addr := os.Getenv("APP_SERVER_ADDR")
+	if addr == "" {
+		addr = defaultAddr
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newHandler(a, metrics.NewCounters()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+
+		// in-flight events have finished, so buffered output is complete
+		done <- a.Close(shutdownCtx)
+	}()
+
+	logger.Info("listening", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return <-done
+}
+
+// newHandler serves GET /metrics with the counters, which are added to the
+// app's decision log sinks.
+func newHandler(a *app.App, counters *metrics.Counters) http.Handler {
+	if a.Decisions != nil {
+		a.Decisions = decisionlog.MultiSink{a.Decisions, counters}
+	} else {
+		a.Decisions = counters
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", counters)
+	return mux
+}
@@ -0,0 +1,115 @@
+// Package main tests the server's HTTP endpoints.
+//
+// Tests cover:
+// - /metrics exposes processing counters and per-rule hits
+// - No route accepts events over HTTP
+// - Uses fixtures/samples.json for realistic OCSF findings
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/app"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/filters"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/metrics"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/notifiers"
+)
+
+func loadSamples(t *testing.T) []json.RawMessage {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+
+	var findings []json.RawMessage
+	if err := json.Unmarshal(raw, &findings); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+	return findings
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *app.App) {
+	t.Helper()
+
+	a := &app.App{
+		Config:   &app.Config{},
+		Notifier: notifiers.NewMemoryNotifier(),
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	a.SetFilterEngine(filters.NewFilterEngine([]filters.AutoCloseRule{{
+		Name:    "observe-guardduty",
+		Enabled: true,
+		Filters: filters.RuleFilters{ProductName: []string{"GuardDuty"}},
+		Action:  filters.RuleAction{Type: filters.ActionTypeObserve, StatusID: 3},
+	}}))
+
+	srv := httptest.NewServer(newHandler(a, metrics.NewCounters()))
+	t.Cleanup(srv.Close)
+	return srv, a
+}
+
+// TestHandler_Metrics validates that processing an event shows up in the
+// /metrics output under the expected metric names.
+func TestHandler_Metrics(t *testing.T) {
+	srv, a := newTestServer(t)
+
+	detail, err := json.Marshal(map[string]any{"findings": loadSamples(t)})
+	if err != nil {
+		t.Fatalf("failed to marshal detail: %v", err)
+	}
+	input := events.SecurityHubEventInput{
+		EventID:    "test-event",
+		DetailType: "Findings Imported V2",
+		Detail:     detail,
+	}
+	if err := a.Process(context.Background(), input); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("failed to get metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, expected := range []string{
+		metrics.FindingsProcessedMetric + " 3",
+		"# TYPE " + metrics.FindingsClosedMetric + " counter",
+		metrics.NotificationsMetric,
+		metrics.ErrorsMetric + " 0",
+		metrics.RuleHitsTotalMetric + `{rule="observe-guardduty",action="observed",severity="Medium"} 2`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %q in metrics output:\n%s", expected, body)
+		}
+	}
+}
+
+// TestHandler_NoEventsRoute validates that events can't be posted to the
+// server, so findings can't be closed by unauthenticated callers.
+func TestHandler_NoEventsRoute(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Post(srv.URL+"/events", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("failed to post event: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
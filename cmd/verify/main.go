@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -20,9 +21,21 @@ func main() {
 	scenarioFile := flag.String("scenarios", "fixtures/scenarios.json", "path to test scenarios file")
 	verbose := flag.Bool("verbose", false, "enable verbose output")
 	scenarioFilter := flag.String("filter", "", "run only scenarios matching this name")
+	output := flag.String("output", "text", "result format: text or json")
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// json results own stdout, so progress moves to stderr
+	var out io.Writer = os.Stdout
+	switch *output {
+	case "text":
+	case "json":
+		out = os.Stderr
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -output %q (expected 'text' or 'json')\n", *output)
+		os.Exit(2)
+	}
+
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 
@@ -34,7 +47,7 @@ func main() {
 			logger.Warn("failed to load .env file", slog.String("error", err.Error()))
 		}
 	} else if _, err := os.Stat(envExamplePath); err == nil {
-		fmt.Printf("Using .env.test (no .env file found)\n")
+		fmt.Fprintf(out, "Using .env.test (no .env file found)\n")
 		if err := godotenv.Load(envExamplePath); err != nil {
 			logger.Warn("failed to load .env.test file", slog.String("error", err.Error()))
 		}
@@ -58,6 +71,7 @@ func main() {
 	passed := 0
 	failed := 0
 	skipped := 0
+	results := []ScenarioResult{}
 
 	for _, scenario := range scenarios {
 		if *scenarioFilter != "" && !strings.Contains(scenario.Name, *scenarioFilter) {
@@ -65,23 +79,32 @@ func main() {
 			continue
 		}
 
-		if err := runScenario(ctx, scenario, *verbose, logger); err != nil {
-			fmt.Printf("✗ FAILED: %v\n\n", err)
+		result := runScenario(ctx, scenario, *verbose, out, logger)
+		results = append(results, result)
+		if !result.Passed {
+			fmt.Fprintf(out, "✗ FAILED: %s\n\n", result.Error)
 			failed++
 		} else {
 			passed++
 		}
 	}
 
-	fmt.Printf("\n")
+	fmt.Fprintf(out, "\n")
 	separator := strings.Repeat("═", 60)
-	fmt.Printf("%s\n", separator)
+	fmt.Fprintf(out, "%s\n", separator)
 	if failed > 0 {
-		fmt.Printf("  Test Results: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
+		fmt.Fprintf(out, "  Test Results: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
 	} else {
-		fmt.Printf("  Test Results: ✓ All %d tests passed, %d skipped\n", passed, skipped)
+		fmt.Fprintf(out, "  Test Results: ✓ All %d tests passed, %d skipped\n", passed, skipped)
+	}
+	fmt.Fprintf(out, "%s\n", separator)
+
+	if *output == "json" {
+		if err := writeJSONResults(os.Stdout, results); err != nil {
+			logger.Error("failed to write results", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
 	}
-	fmt.Printf("%s\n", separator)
 
 	if failed > 0 {
 		os.Exit(1)
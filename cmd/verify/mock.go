@@ -7,6 +7,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/clock"
 )
 
 // RequestRecord captures details of an HTTP request made during testing.
@@ -41,11 +43,15 @@ type MockServer struct {
 	requests  []RequestRecord
 	responses map[string]MockResponse
 	verbose   bool
+	out       io.Writer
+	// clock stamps recorded requests
+	clock clock.Clock
 }
 
 // NewMockServer creates a new mock HTTP server with canned responses.
-// matches requests by HTTP method and path pattern.
-func NewMockServer(name string, responses []MockResponse, verbose bool) *MockServer {
+// matches requests by HTTP method and path pattern. verbose request logs are
+// written to out.
+func NewMockServer(name string, responses []MockResponse, verbose bool, out io.Writer) *MockServer {
 	respMap := make(map[string]MockResponse)
 	for _, r := range responses {
 		key := fmt.Sprintf("%s:%s", r.Method, r.Path)
@@ -56,6 +62,8 @@ func NewMockServer(name string, responses []MockResponse, verbose bool) *MockSer
 		requests:  make([]RequestRecord, 0),
 		responses: respMap,
 		verbose:   verbose,
+		out:       out,
+		clock:     clock.Real{},
 	}
 }
 
@@ -66,7 +74,7 @@ func (ms *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r.Body.Close()
 
 	rec := RequestRecord{
-		Timestamp: time.Now(),
+		Timestamp: ms.clock.Now(),
 		Method:    r.Method,
 		Host:      r.Host,
 		Path:      r.URL.Path,
@@ -81,7 +89,7 @@ func (ms *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if ms.verbose {
 		serviceName := fmt.Sprintf("%-8s", ms.name)
-		fmt.Printf("  → %s %-6s %s\n", serviceName, r.Method, r.URL.Path)
+		fmt.Fprintf(ms.out, "  → %s %-6s %s\n", serviceName, r.Method, r.URL.Path)
 	}
 
 	key := fmt.Sprintf("%s:%s", r.Method, r.URL.Path)
@@ -117,7 +125,7 @@ func (ms *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if ms.verbose {
 		serviceName := fmt.Sprintf("%-8s", ms.name)
-		fmt.Printf("  ✗ %s No mock response for: %s %s\n", serviceName, r.Method, r.URL.Path)
+		fmt.Fprintf(ms.out, "  ✗ %s No mock response for: %s %s\n", serviceName, r.Method, r.URL.Path)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
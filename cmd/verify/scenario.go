@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -34,14 +35,60 @@ type ExpectedCall struct {
 	Path    string `json:"path"`
 }
 
-// runScenario executes a single test scenario with mock HTTP servers and
-// validates that expected API calls were made.
-func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logger *slog.Logger) error {
+// ScenarioResult is the outcome of a single scenario, as written by
+// -output=json.
+type ScenarioResult struct {
+	Name       string         `json:"name"`
+	Passed     bool           `json:"passed"`
+	DurationMS float64        `json:"duration_ms"`
+	Calls      []CapturedCall `json:"calls"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// CapturedCall is an HTTP call a mock server received during a scenario.
+type CapturedCall struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+}
+
+// runScenario executes a single test scenario and returns its result.
+// progress is written to out.
+func runScenario(ctx context.Context, scenario TestScenario, verbose bool, out io.Writer, logger *slog.Logger) ScenarioResult {
+	start := time.Now()
+	calls, err := executeScenario(ctx, scenario, verbose, out, logger)
+
+	result := ScenarioResult{
+		Name:       scenario.Name,
+		Passed:     err == nil,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Calls:      calls,
+	}
+	if result.Calls == nil {
+		result.Calls = []CapturedCall{}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeJSONResults writes the scenario results as an indented JSON array.
+func writeJSONResults(w io.Writer, results []ScenarioResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// executeScenario runs a scenario with mock HTTP servers and validates that
+// expected API calls were made. the calls the mocks captured are returned
+// even when validation fails.
+func executeScenario(ctx context.Context, scenario TestScenario, verbose bool, out io.Writer, logger *slog.Logger) (calls []CapturedCall, err error) {
 	startTime := time.Now()
 
-	fmt.Printf("\n▶ Running: %s\n", scenario.Name)
+	fmt.Fprintf(out, "\n▶ Running: %s\n", scenario.Name)
 	if scenario.Description != "" {
-		fmt.Printf("  %s\n", scenario.Description)
+		fmt.Fprintf(out, "  %s\n", scenario.Description)
 	}
 
 	securityhubResponses := []MockResponse{}
@@ -59,13 +106,28 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 		}
 	}
 
-	securityhubMock := NewMockServer("SecurityHub", securityhubResponses, verbose)
-	slackMock := NewMockServer("Slack", slackResponses, verbose)
-	s3Mock := NewMockServer("S3", s3Responses, verbose)
+	securityhubMock := NewMockServer("SecurityHub", securityhubResponses, verbose, out)
+	slackMock := NewMockServer("Slack", slackResponses, verbose, out)
+	s3Mock := NewMockServer("S3", s3Responses, verbose, out)
+
+	defer func() {
+		for _, mock := range []struct {
+			service string
+			server  *MockServer
+		}{
+			{"securityhub", securityhubMock},
+			{"slack", slackMock},
+			{"s3", s3Mock},
+		} {
+			for _, req := range mock.server.GetRequests() {
+				calls = append(calls, CapturedCall{Service: mock.service, Method: req.Method, Path: req.Path})
+			}
+		}
+	}()
 
 	tlsCert, certPool, err := generateSelfSignedCert()
 	if err != nil {
-		return fmt.Errorf("generate cert: %w", err)
+		return nil, fmt.Errorf("generate cert: %w", err)
 	}
 
 	securityhubServer := &http.Server{
@@ -162,31 +224,31 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 
 	cfg, err := app.NewConfig()
 	if err != nil {
-		return fmt.Errorf("config creation failed: %w", err)
+		return nil, fmt.Errorf("config creation failed: %w", err)
 	}
 
 	if verbose {
-		fmt.Printf("  Auto-close rules configured: %d\n", len(cfg.AutoCloseRules))
+		fmt.Fprintf(out, "  Auto-close rules configured: %d\n", len(cfg.AutoCloseRules))
 	}
 
-	appLogger := slog.New(&testHandler{prefix: "  ", verbose: verbose, w: os.Stdout})
+	appLogger := slog.New(&testHandler{prefix: "  ", verbose: verbose, w: out})
 
 	a, err := app.New(ctx, cfg, appLogger)
 	if err != nil {
-		return fmt.Errorf("app creation failed: %w", err)
+		return nil, fmt.Errorf("app creation failed: %w", err)
 	}
 
 	if verbose {
-		fmt.Printf("\n  Application Output:\n")
+		fmt.Fprintf(out, "\n  Application Output:\n")
 	}
 
 	var evt awsEvent.CloudWatchEvent
 	if err := json.Unmarshal(scenario.EventPayload, &evt); err != nil {
-		return fmt.Errorf("unmarshal event payload failed: %w", err)
+		return nil, fmt.Errorf("unmarshal event payload failed: %w", err)
 	}
 
 	if verbose {
-		fmt.Printf("  Processing event...\n")
+		fmt.Fprintf(out, "  Processing event...\n")
 	}
 
 	// convert Lambda CloudWatch event to runtime-agnostic event input
@@ -200,17 +262,17 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 
 	if scenario.ExpectError {
 		if processErr == nil {
-			return fmt.Errorf("expected error but processing succeeded")
+			return nil, fmt.Errorf("expected error but processing succeeded")
 		}
 		if verbose {
-			fmt.Printf("  ✓ Expected error occurred: %v\n", processErr)
+			fmt.Fprintf(out, "  ✓ Expected error occurred: %v\n", processErr)
 		}
 	} else {
 		if processErr != nil {
-			return fmt.Errorf("process event failed: %w", processErr)
+			return nil, fmt.Errorf("process event failed: %w", processErr)
 		}
 		if verbose {
-			fmt.Printf("  ✓ Event processed successfully\n")
+			fmt.Fprintf(out, "  ✓ Event processed successfully\n")
 		}
 	}
 
@@ -228,44 +290,44 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 	totalCalls := len(securityhubReqs) + len(slackReqs) + len(s3Reqs)
 
 	if verbose {
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
 	}
 
 	if err := validateExpectedCalls(scenario.ExpectedCalls, allReqs); err != nil {
-		fmt.Printf("\n  Validation:\n")
-		fmt.Printf("  ✗ FAILED: %v\n", err)
-		fmt.Printf("\n  All captured requests:\n")
+		fmt.Fprintf(out, "\n  Validation:\n")
+		fmt.Fprintf(out, "  ✗ FAILED: %v\n", err)
+		fmt.Fprintf(out, "\n  All captured requests:\n")
 		if len(securityhubReqs) > 0 {
-			fmt.Printf("    SecurityHub (%d):\n", len(securityhubReqs))
+			fmt.Fprintf(out, "    SecurityHub (%d):\n", len(securityhubReqs))
 			for i, req := range securityhubReqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
 		if len(slackReqs) > 0 {
-			fmt.Printf("    Slack (%d):\n", len(slackReqs))
+			fmt.Fprintf(out, "    Slack (%d):\n", len(slackReqs))
 			for i, req := range slackReqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
 		if len(s3Reqs) > 0 {
-			fmt.Printf("    S3 (%d):\n", len(s3Reqs))
+			fmt.Fprintf(out, "    S3 (%d):\n", len(s3Reqs))
 			for i, req := range s3Reqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
-		return err
+		return nil, err
 	}
 
 	duration := time.Since(startTime)
 
 	if verbose {
-		fmt.Printf("  Validation:\n")
-		fmt.Printf("  ✓ All expected calls verified (%d total)\n", totalCalls)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "  Validation:\n")
+		fmt.Fprintf(out, "  ✓ All expected calls verified (%d total)\n", totalCalls)
+		fmt.Fprintf(out, "\n")
 	}
 
-	fmt.Printf("✓ PASSED (Duration: %.2fs)\n", duration.Seconds())
-	return nil
+	fmt.Fprintf(out, "✓ PASSED (Duration: %.2fs)\n", duration.Seconds())
+	return nil, nil
 }
 
 // validateExpectedCalls verifies that all expected HTTP calls were captured
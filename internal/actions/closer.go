@@ -5,34 +5,76 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
 	"github.com/cockroachdb/errors"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 )
 
+// ErrFindingNotFound is returned when security hub no longer has the finding,
+// such as one deleted since it was imported. retrying cannot succeed.
+var ErrFindingNotFound = errors.New("finding not found")
+
+type SecurityHubClient interface {
+	BatchUpdateFindingsV2(ctx context.Context, params *securityhub.BatchUpdateFindingsV2Input, optFns ...func(*securityhub.Options)) (*securityhub.BatchUpdateFindingsV2Output, error)
+}
+
+// Closer updates findings in security hub. FindingCloser implements it, and
+// tests can swap in a double.
+type Closer interface {
+	CloseFinding(ctx context.Context, finding *events.SecurityHubV2Finding, statusID int32, comment string) error
+	AddComment(ctx context.Context, finding *events.SecurityHubV2Finding, comment string) error
+}
+
 type FindingCloser struct {
-	client *securityhub.Client
+	client SecurityHubClient
+	region string
 }
 
-func NewFindingCloser(client *securityhub.Client) *FindingCloser {
+// NewFindingCloser creates a closer for the client. a non-empty region forces
+// all updates to that region, such as a delegated-admin aggregation region.
+func NewFindingCloser(client SecurityHubClient, region string) *FindingCloser {
 	return &FindingCloser{
 		client: client,
+		region: region,
 	}
 }
 
 func (c *FindingCloser) CloseFinding(ctx context.Context, finding *events.SecurityHubV2Finding, statusID int32, comment string) error {
-	input := &securityhub.BatchUpdateFindingsV2Input{
+	return c.updateFinding(ctx, finding, &securityhub.BatchUpdateFindingsV2Input{
 		MetadataUids: []string{finding.Metadata.UID},
 		StatusId:     aws.Int32(statusID),
 		Comment:      aws.String(comment),
+	})
+}
+
+// AddComment sets the finding comment without changing its status.
+func (c *FindingCloser) AddComment(ctx context.Context, finding *events.SecurityHubV2Finding, comment string) error {
+	return c.updateFinding(ctx, finding, &securityhub.BatchUpdateFindingsV2Input{
+		MetadataUids: []string{finding.Metadata.UID},
+		Comment:      aws.String(comment),
+	})
+}
+
+func (c *FindingCloser) updateFinding(ctx context.Context, finding *events.SecurityHubV2Finding, input *securityhub.BatchUpdateFindingsV2Input) error {
+	var optFns []func(*securityhub.Options)
+	if c.region != "" {
+		optFns = append(optFns, func(o *securityhub.Options) {
+			o.Region = c.region
+		})
 	}
 
-	output, err := c.client.BatchUpdateFindingsV2(ctx, input)
+	output, err := c.client.BatchUpdateFindingsV2(ctx, input, optFns...)
 	if err != nil {
 		return errors.Wrap(err, "failed to update finding")
 	}
 
 	if len(output.UnprocessedFindings) > 0 {
 		unprocessed := output.UnprocessedFindings[0]
+		if unprocessed.ErrorCode == types.BatchUpdateFindingsV2UnprocessedFindingErrorCodeResourceNotFoundException {
+			return errors.Wrapf(ErrFindingNotFound, "failed to update finding %s: %s",
+				finding.Metadata.UID,
+				aws.ToString(unprocessed.ErrorMessage))
+		}
 		return errors.Newf("failed to update finding %s: %s - %s",
 			finding.Metadata.UID,
 			string(unprocessed.ErrorCode),
@@ -3,22 +3,47 @@
 // Tests cover:
 // - Finding closer construction
 // - Input validation and preparation
+// - Aggregation region override
+// - Comment-only updates
+// - Not-found unprocessed findings map to ErrFindingNotFound
 //
 // Note: Full integration testing with AWS SDK mocks is handled in cmd/verify.
 // These unit tests focus on the logic within this package.
 package actions
 
 import (
+	"context"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 )
 
+type mockSecurityHubClient struct {
+	inputs      []*securityhub.BatchUpdateFindingsV2Input
+	regions     []string
+	unprocessed []types.BatchUpdateFindingsV2UnprocessedFinding
+}
+
+func (m *mockSecurityHubClient) BatchUpdateFindingsV2(ctx context.Context, params *securityhub.BatchUpdateFindingsV2Input, optFns ...func(*securityhub.Options)) (*securityhub.BatchUpdateFindingsV2Output, error) {
+	opts := securityhub.Options{Region: "us-east-1"}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	m.inputs = append(m.inputs, params)
+	m.regions = append(m.regions, opts.Region)
+	return &securityhub.BatchUpdateFindingsV2Output{UnprocessedFindings: m.unprocessed}, nil
+}
+
 // TestNewFindingCloser validates that a FindingCloser can be constructed
 // with a Security Hub client.
 func TestNewFindingCloser(t *testing.T) {
 	client := &securityhub.Client{}
-	closer := NewFindingCloser(client)
+	closer := NewFindingCloser(client, "")
 
 	if closer == nil {
 		t.Fatal("expected non-nil FindingCloser")
@@ -32,7 +57,7 @@ func TestNewFindingCloser(t *testing.T) {
 // TestNewFindingCloser_NilClient validates that a FindingCloser can be
 // constructed even with a nil client (will fail at runtime, but constructor works).
 func TestNewFindingCloser_NilClient(t *testing.T) {
-	closer := NewFindingCloser(nil)
+	closer := NewFindingCloser(nil, "")
 
 	if closer == nil {
 		t.Fatal("expected non-nil FindingCloser even with nil client")
@@ -42,3 +67,111 @@ func TestNewFindingCloser_NilClient(t *testing.T) {
 		t.Error("expected client to be nil")
 	}
 }
+
+// TestFindingCloser_CloseFinding validates the update input sent for a finding.
+func TestFindingCloser_CloseFinding(t *testing.T) {
+	client := &mockSecurityHubClient{}
+	closer := NewFindingCloser(client, "")
+
+	finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	if err := closer.CloseFinding(context.Background(), finding, 5, "Test comment"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("expected 1 update call, got %d", len(client.inputs))
+	}
+
+	input := client.inputs[0]
+	if len(input.MetadataUids) != 1 || input.MetadataUids[0] != "finding-1" {
+		t.Errorf("unexpected metadata uids: %v", input.MetadataUids)
+	}
+
+	if aws.ToInt32(input.StatusId) != 5 {
+		t.Errorf("expected status_id 5, got %d", aws.ToInt32(input.StatusId))
+	}
+
+	if aws.ToString(input.Comment) != "Test comment" {
+		t.Errorf("expected comment 'Test comment', got %s", aws.ToString(input.Comment))
+	}
+
+	if client.regions[0] != "us-east-1" {
+		t.Errorf("expected client default region us-east-1, got %s", client.regions[0])
+	}
+}
+
+// TestFindingCloser_AggregationRegion validates that a configured aggregation
+// region overrides the region for every update, regardless of finding region.
+func TestFindingCloser_AggregationRegion(t *testing.T) {
+	client := &mockSecurityHubClient{}
+	closer := NewFindingCloser(client, "eu-west-1")
+
+	finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	finding.Cloud.Region = "ap-southeast-2"
+
+	if err := closer.CloseFinding(context.Background(), finding, 5, "Test comment"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.regions) != 1 || client.regions[0] != "eu-west-1" {
+		t.Errorf("expected aggregation region eu-west-1, got %v", client.regions)
+	}
+}
+
+// TestFindingCloser_AddComment validates that a comment-only update does not
+// change the finding status.
+func TestFindingCloser_AddComment(t *testing.T) {
+	client := &mockSecurityHubClient{}
+	closer := NewFindingCloser(client, "")
+
+	finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	if err := closer.AddComment(context.Background(), finding, "Notified"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("expected 1 update call, got %d", len(client.inputs))
+	}
+
+	if client.inputs[0].StatusId != nil {
+		t.Errorf("expected no status change, got status_id %d", aws.ToInt32(client.inputs[0].StatusId))
+	}
+
+	if aws.ToString(client.inputs[0].Comment) != "Notified" {
+		t.Errorf("expected comment 'Notified', got %s", aws.ToString(client.inputs[0].Comment))
+	}
+}
+
+// TestFindingCloser_UnprocessedFindings validates that a not-found
+// unprocessed finding is reported as ErrFindingNotFound and other error
+// codes are not.
+func TestFindingCloser_UnprocessedFindings(t *testing.T) {
+	tests := []struct {
+		code     types.BatchUpdateFindingsV2UnprocessedFindingErrorCode
+		notFound bool
+	}{
+		{types.BatchUpdateFindingsV2UnprocessedFindingErrorCodeResourceNotFoundException, true},
+		{types.BatchUpdateFindingsV2UnprocessedFindingErrorCodeInternalServerException, false},
+		{types.BatchUpdateFindingsV2UnprocessedFindingErrorCodeConflictException, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			client := &mockSecurityHubClient{
+				unprocessed: []types.BatchUpdateFindingsV2UnprocessedFinding{
+					{ErrorCode: tt.code, ErrorMessage: aws.String("finding-1 failed")},
+				},
+			}
+			closer := NewFindingCloser(client, "")
+
+			finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+			err := closer.CloseFinding(context.Background(), finding, 3, "closed")
+			if err == nil {
+				t.Fatal("expected error for unprocessed finding")
+			}
+			if errors.Is(err, ErrFindingNotFound) != tt.notFound {
+				t.Errorf("expected not found=%v, got %v", tt.notFound, err)
+			}
+		})
+	}
+}
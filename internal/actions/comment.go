@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	CommentModeReplace = "replace"
+	CommentModePrefix  = "prefix"
+	CommentModeAppend  = "append"
+
+	// maxCommentLength is the BatchUpdateFindingsV2 comment limit
+	maxCommentLength = 512
+)
+
+// FormatComment builds the comment sent on close. prefix stamps the comment
+// with the close time; append also keeps the finding's existing comment so
+// repeated closes build a history, dropping the oldest lines past the limit.
+func FormatComment(mode, existing, comment string, now time.Time) string {
+	switch mode {
+	case CommentModePrefix:
+		return truncateComment(stampComment(comment, now))
+	case CommentModeAppend:
+		stamped := stampComment(comment, now)
+		if existing == "" {
+			return truncateComment(stamped)
+		}
+		history := existing + "\n" + stamped
+		for len(history) > maxCommentLength {
+			i := strings.Index(history, "\n")
+			if i == -1 {
+				return truncateComment(stamped)
+			}
+			history = history[i+1:]
+		}
+		return history
+	default:
+		return truncateComment(comment)
+	}
+}
+
+func stampComment(comment string, now time.Time) string {
+	return "[" + now.UTC().Format(time.RFC3339) + "] " + comment
+}
+
+// truncateComment cuts the comment to the limit on a rune boundary, so a
+// multi-byte character is never split into invalid UTF-8.
+func truncateComment(comment string) string {
+	if len(comment) <= maxCommentLength {
+		return comment
+	}
+	n := maxCommentLength
+	for n > 0 && !utf8.RuneStart(comment[n]) {
+		n--
+	}
+	return comment[:n]
+}
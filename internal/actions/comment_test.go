@@ -0,0 +1,100 @@
+// Package actions tests close comment formatting.
+//
+// Tests cover:
+// - Replace, prefix, and append comment modes
+// - Trimming comment history to the API length limit
+// - Truncating non-ASCII comments on a rune boundary
+// - Truncating long comments in replace mode
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// TestFormatComment validates each comment mode against an existing comment.
+func TestFormatComment(t *testing.T) {
+	now := time.Date(2025, 11, 21, 23, 15, 28, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		mode     string
+		existing string
+		expected string
+	}{
+		{"replace", CommentModeReplace, "old", "Auto-closed"},
+		{"default is replace", "", "old", "Auto-closed"},
+		{"prefix", CommentModePrefix, "old", "[2025-11-21T23:15:28Z] Auto-closed"},
+		{"append to existing", CommentModeAppend, "old", "old\n[2025-11-21T23:15:28Z] Auto-closed"},
+		{"append without existing", CommentModeAppend, "", "[2025-11-21T23:15:28Z] Auto-closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatComment(tt.mode, tt.existing, "Auto-closed", now)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestFormatComment_AppendTrimsHistory validates that the oldest history lines
+// are dropped to stay within the API comment limit.
+func TestFormatComment_AppendTrimsHistory(t *testing.T) {
+	now := time.Date(2025, 11, 21, 23, 15, 28, 0, time.UTC)
+	existing := strings.Repeat("a", 400) + "\n" + strings.Repeat("b", 150)
+
+	got := FormatComment(CommentModeAppend, existing, "Auto-closed", now)
+	if len(got) > maxCommentLength {
+		t.Fatalf("expected at most %d characters, got %d", maxCommentLength, len(got))
+	}
+
+	expected := strings.Repeat("b", 150) + "\n[2025-11-21T23:15:28Z] Auto-closed"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestFormatComment_TruncatesOnRuneBoundary validates that a long non-ASCII
+// comment is cut to the limit without splitting a multi-byte character.
+func TestFormatComment_TruncatesOnRuneBoundary(t *testing.T) {
+	now := time.Date(2025, 11, 21, 23, 15, 28, 0, time.UTC)
+
+	// the 23 byte stamp and the leading "#" leave 488 bytes for 3 byte
+	// characters, so the byte limit ends mid-character
+	comment := "#" + strings.Repeat("自動クローズ", 40)
+	got := FormatComment(CommentModePrefix, "", comment, now)
+
+	if len(got) > maxCommentLength {
+		t.Fatalf("expected at most %d bytes, got %d", maxCommentLength, len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("expected valid UTF-8, got %q", got)
+	}
+	if !strings.HasPrefix(got, "[2025-11-21T23:15:28Z] #自動") {
+		t.Errorf("unexpected comment: %q", got)
+	}
+}
+
+// TestFormatComment_ReplaceTruncates validates that replace mode cuts a long
+// comment to the limit on a rune boundary like the other modes.
+func TestFormatComment_ReplaceTruncates(t *testing.T) {
+	now := time.Date(2025, 11, 21, 23, 15, 28, 0, time.UTC)
+
+	// 1 + 40*18 bytes, with the limit falling mid-character
+	comment := "#" + strings.Repeat("自動クローズ", 40)
+	got := FormatComment(CommentModeReplace, "", comment, now)
+
+	if len(got) > maxCommentLength {
+		t.Fatalf("expected at most %d bytes, got %d", maxCommentLength, len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("expected valid UTF-8, got %q", got)
+	}
+	if !strings.HasPrefix(comment, got) {
+		t.Errorf("expected a prefix of the comment, got %q", got)
+	}
+}
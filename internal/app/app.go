@@ -1,94 +1,320 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/cockroachdb/errors"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/actions"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/audit"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/clock"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/decisionlog"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/filters"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/locale"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/metrics"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/notifiers"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/pending"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/recent"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/telemetry"
 )
 
 type App struct {
 	Config        *Config
-	FilterEngine  *filters.FilterEngine
-	FindingCloser *actions.FindingCloser
+	FindingCloser actions.Closer
 	Notifier      notifiers.Notifier
 	Logger        *slog.Logger
+	RulesLoader   *filters.S3RulesLoader
+	URLLoader     *filters.HTTPRulesLoader
+	Metrics       *metrics.Recorder
+	Audit         audit.Sink
+	Decisions     decisionlog.Sink
+	Pending       pending.Store
+	Notified      *recent.Cache
+	Processed     *recent.Cache
+	Telemetry     *telemetry.Telemetry
+	// Clock is the time source for rule filters, close age, close delays,
+	// dedup windows and timestamps. nil uses the system clock
+	Clock clock.Clock
+
+	filterEngine atomic.Pointer[filters.FilterEngine]
+	// reloadMu serializes reloads, and ruleSources is the last load by source
+	reloadMu    sync.Mutex
+	ruleSources map[string][]filters.AutoCloseRule
+	flushers    []Flusher
 }
 
+// New builds the app from config. logger is extended with APP_LOG_FIELDS,
+// and the result is used for every log line the app and its components write.
 func New(ctx context.Context, cfg *Config, logger *slog.Logger) (*App, error) {
+	logger = logger.With(cfg.LogAttrs()...)
+
 	// allow custom HTTP client from context (for testing)
-	configOpts := []func(*config.LoadOptions) error{}
-	if httpClient, ok := ctx.Value("aws_http_client").(*http.Client); ok && httpClient != nil {
-		configOpts = append(configOpts, config.WithHTTPClient(httpClient))
-	}
+	httpClient, _ := ctx.Value("aws_http_client").(*http.Client)
 
-	awsCfg, err := config.LoadDefaultConfig(ctx, configOpts...)
+	awsCfg, err := config.LoadDefaultConfig(ctx, awsConfigOptions(httpClient, cfg)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load aws config - check credentials and region")
 	}
 
 	app := &App{
 		Config:        cfg,
-		FindingCloser: actions.NewFindingCloser(securityhub.NewFromConfig(awsCfg)),
+		FindingCloser: actions.NewFindingCloser(securityhub.NewFromConfig(awsCfg, securityHubOptions(cfg)...), cfg.AggregationRegion),
 		Logger:        logger,
+		Audit:         audit.NewLogSink(logger),
+		Pending:       pending.NewMemoryStore(),
 	}
 
-	rules := cfg.AutoCloseRules
-
-	if cfg.AutoCloseRulesS3Bucket != "" {
-		s3Client := s3.NewFromConfig(awsCfg)
-		loader := filters.NewS3RulesLoader(s3Client)
+	if cfg.MetricsEnabled {
+		app.Metrics = metrics.NewRecorder(os.Stdout)
+	}
 
-		s3Rules, err := app.LoadRulesFromS3(ctx, loader, cfg.AutoCloseRulesS3Bucket, cfg.AutoCloseRulesS3Prefix)
+	if cfg.OTelEnabled {
+		app.Telemetry, err = telemetry.NewOTLP(ctx)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to load rules from s3://%s/%s", cfg.AutoCloseRulesS3Bucket, cfg.AutoCloseRulesS3Prefix)
+			return nil, errors.Wrap(err, "failed to set up opentelemetry - check OTEL_EXPORTER_OTLP_ENDPOINT")
 		}
+	}
 
-		if len(cfg.AutoCloseRules) > 0 {
-			app.Logger.Info("loaded rules from S3 and env", "s3_rules", len(s3Rules), "env_rules", len(cfg.AutoCloseRules))
-			rules = append(cfg.AutoCloseRules, s3Rules...)
+	if cfg.DecisionLogEnabled {
+		if cfg.DecisionLogS3Bucket != "" {
+			app.Decisions = decisionlog.NewS3Sink(s3.NewFromConfig(awsCfg, s3Options(cfg)...), cfg.DecisionLogS3Bucket, cfg.DecisionLogS3Prefix)
 		} else {
-			app.Logger.Info("loaded rules from S3", "count", len(s3Rules))
-			rules = s3Rules
+			app.Decisions = decisionlog.NewWriterSink(os.Stdout)
+		}
+	}
+
+	if cfg.NotifyDedupTTL > 0 {
+		app.Notified = recent.NewCache(cfg.NotifyDedupSize, cfg.NotifyDedupTTL)
+	}
+
+	if cfg.RecurrenceTTL > 0 {
+		app.Processed = recent.NewCache(cfg.NotifyDedupSize, cfg.RecurrenceTTL)
+	}
+
+	if cfg.PendingCloseS3Bucket != "" {
+		app.Pending = pending.NewS3Store(s3.NewFromConfig(awsCfg, s3Options(cfg)...), cfg.PendingCloseS3Bucket, cfg.PendingCloseS3Prefix)
+	}
+
+	app.useRuleLoaders(awsCfg)
+
+	if err := app.ReloadRules(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Notifiers) == 1 {
+		if app.Notifier, err = newNotifier(ctx, cfg.Notifiers[0], cfg, awsCfg, logger); err != nil {
+			return nil, err
+		}
+	} else if len(cfg.Notifiers) > 1 {
+		multi := notifiers.NewMultiNotifier()
+		for _, name := range cfg.Notifiers {
+			notifier, err := newNotifier(ctx, name, cfg, awsCfg, logger)
+			if err != nil {
+				return nil, err
+			}
+			multi.Add(name, notifier)
 		}
+		app.Notifier = multi
 	}
 
-	app.FilterEngine = filters.NewFilterEngine(rules)
+	for _, component := range []any{app.Notifier, app.Audit, app.Decisions} {
+		if f, ok := component.(Flusher); ok {
+			app.RegisterFlusher(f)
+		}
+	}
 
-	if cfg.SlackEnabled {
-		app.Notifier = notifiers.NewSlackNotifier(
-			cfg.SlackToken,
-			cfg.SlackChannel,
-			cfg.AwsConsoleURL,
-			cfg.AwsAccessPortalURL,
-			cfg.AwsAccessRoleName,
-			cfg.AWSSecurityHubv2Region,
-		)
+	return app, nil
+}
+
+// NewEvaluator builds an app that only loads rules and decides, such as for
+// cmd/eval. rules come from the same sources as New, but nothing is closed or
+// notified, and the aws config is only loaded for rules in S3.
+func NewEvaluator(ctx context.Context, cfg *Config, logger *slog.Logger) (*App, error) {
+	app := &App{Config: cfg, Logger: logger}
+
+	var awsCfg aws.Config
+	if cfg.AutoCloseRulesS3Bucket != "" {
+		httpClient, _ := ctx.Value("aws_http_client").(*http.Client)
+		var err error
+		awsCfg, err = config.LoadDefaultConfig(ctx, awsConfigOptions(httpClient, cfg)...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load aws config - check credentials and region")
+		}
 	}
+	app.useRuleLoaders(awsCfg)
 
+	if err := app.ReloadRules(ctx); err != nil {
+		return nil, err
+	}
 	return app, nil
 }
 
+// useRuleLoaders sets the S3 and URL rule loaders the config enables.
+func (a *App) useRuleLoaders(awsCfg aws.Config) {
+	cfg := a.Config
+	if cfg.AutoCloseRulesS3Bucket != "" {
+		a.RulesLoader = filters.NewS3RulesLoader(s3.NewFromConfig(awsCfg, s3Options(cfg)...))
+	}
+	if cfg.AutoCloseRulesURL != "" {
+		a.URLLoader = filters.NewHTTPRulesLoader(NewHTTPClient(cfg), cfg.AutoCloseRulesURL, cfg.AutoCloseRulesURLAuth, cfg.AutoCloseRulesURLTimeout)
+	}
+}
+
+// newNotifier builds the named APP_NOTIFIER backend.
+func newNotifier(ctx context.Context, name string, cfg *Config, awsCfg aws.Config, logger *slog.Logger) (notifiers.Notifier, error) {
+	switch name {
+	case "slack":
+		slackNotifier := notifiers.NewSlackNotifier(notifiers.SlackOptions{
+			Token:               cfg.SlackToken,
+			Channel:             cfg.SlackChannel,
+			ConsoleURL:          cfg.AwsConsoleURL,
+			AccessPortalURL:     cfg.AwsAccessPortalURL,
+			AccessRoleName:      cfg.AwsAccessRoleName,
+			AccessRoleNames:     cfg.AwsAccessRoleNames,
+			ConsoleRegion:       cfg.AwsConsoleRegion,
+			SecurityHubv2Region: cfg.AWSSecurityHubv2Region,
+			Footer:              cfg.NotifyFooter,
+			CategoryMappings:    cfg.CategoryMappings,
+			Fields:              cfg.SlackFields,
+			ChannelRoutes:       cfg.SlackChannelRoutes,
+			HTTPClient:          NewHTTPClient(cfg),
+		})
+		if cfg.SlackVerify {
+			if err := slackNotifier.Verify(ctx); err != nil {
+				return nil, errors.Wrap(err, "invalid slack config - check APP_SLACK_TOKEN")
+			}
+		}
+		slackNotifier.UseCategoryFields(cfg.SlackCategoryFields)
+		slackNotifier.LimitBlocks(cfg.SlackMaxBlocks)
+		slackNotifier.UseLocale(cfg.Locale)
+		if cfg.SlackAttachRaw {
+			slackNotifier.AttachRaw(logger)
+		}
+		// names still work with chat.postMessage, so a failed lookup isn't fatal
+		if err := slackNotifier.ResolveChannels(ctx); err != nil {
+			logger.Warn("failed to resolve slack channel names, posting by name", "error", err)
+		}
+		return slackNotifier, nil
+	case "webhook":
+		return notifiers.NewWebhookNotifier(cfg.WebhookURL, consoleLinkOptions(cfg), NewHTTPClient(cfg)), nil
+	case "chatbot":
+		return notifiers.NewChatbotNotifier(
+			sns.NewFromConfig(awsCfg, snsOptions(cfg)...),
+			cfg.ChatbotSNSTopicARN,
+			consoleLinkOptions(cfg),
+		), nil
+	case "render":
+		// render only builds payloads, so it needs no token
+		slackNotifier := notifiers.NewSlackNotifier(notifiers.SlackOptions{
+			Channel:             cfg.SlackChannel,
+			ConsoleURL:          cfg.AwsConsoleURL,
+			AccessPortalURL:     cfg.AwsAccessPortalURL,
+			AccessRoleName:      cfg.AwsAccessRoleName,
+			AccessRoleNames:     cfg.AwsAccessRoleNames,
+			ConsoleRegion:       cfg.AwsConsoleRegion,
+			SecurityHubv2Region: cfg.AWSSecurityHubv2Region,
+			Footer:              cfg.NotifyFooter,
+			CategoryMappings:    cfg.CategoryMappings,
+			Fields:              cfg.SlackFields,
+			ChannelRoutes:       cfg.SlackChannelRoutes,
+			HTTPClient:          NewHTTPClient(cfg),
+		})
+		slackNotifier.UseCategoryFields(cfg.SlackCategoryFields)
+		slackNotifier.LimitBlocks(cfg.SlackMaxBlocks)
+		return notifiers.NewRenderNotifier(slackNotifier, os.Stdout), nil
+	case "memory":
+		return notifiers.NewMemoryNotifier(), nil
+	}
+	return nil, errors.Newf("unsupported notifier: %s", name)
+}
+
+// consoleLinkOptions returns the console link config shared by notifiers.
+func consoleLinkOptions(cfg *Config) events.ConsoleLinkOptions {
+	return events.ConsoleLinkOptions{
+		ConsoleURL:        cfg.AwsConsoleURL,
+		AccessPortalURL:   cfg.AwsAccessPortalURL,
+		AccessRoleName:    cfg.AwsAccessRoleName,
+		AccessRoleNames:   cfg.AwsAccessRoleNames,
+		ConsoleRegion:     cfg.AwsConsoleRegion,
+		SecurityHubRegion: cfg.AWSSecurityHubv2Region,
+		CategoryMappings:  cfg.CategoryMappings,
+	}
+}
+
+// Flusher is implemented by components that buffer output, such as queued
+// notifications or batched audit records, and must write it before the
+// process exits.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// RegisterFlusher adds a component for Close to flush. components are flushed
+// in registration order.
+func (a *App) RegisterFlusher(f Flusher) {
+	a.flushers = append(a.flushers, f)
+}
+
+// Close flushes metrics, every registered component and then telemetry, so
+// spans from flushing are exported too. a failing flusher doesn't stop the
+// rest, and the failures are returned together.
+func (a *App) Close(ctx context.Context) error {
+	a.FlushMetrics()
+
+	var errs []error
+	for _, f := range a.flushers {
+		if err := f.Flush(ctx); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to flush %T", f))
+		}
+	}
+
+	if err := a.Telemetry.Flush(ctx); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to flush telemetry"))
+	}
+	return errors.Join(errs...)
+}
+
 type EventDetail struct {
 	Findings []json.RawMessage `json:"findings"`
 }
 
-func (a *App) ParseEvent(e events.SecurityHubEventInput) (*events.SecurityHubV2Finding, error) {
+// APP_MAX_FINDINGS_MODE values for events over APP_MAX_FINDINGS_PER_EVENT.
+const (
+	MaxFindingsTruncate = "truncate"
+	MaxFindingsFail     = "fail"
+)
+
+// ParseEvent parses the event's findings. events over the configured max
+// findings are truncated to it, or rejected in fail mode, and the overflow is
+// counted as a metric.
+func (a *App) ParseEvent(e events.SecurityHubEventInput) ([]*events.SecurityHubV2Finding, error) {
 	if e.DetailType != "Findings Imported V2" {
 		return nil, errors.Newf("unsupported event type: %s (expected 'Findings Imported V2')", e.DetailType)
 	}
 
+	// some relays deliver the findings array as the detail itself
 	var detail EventDetail
-	if err := json.Unmarshal(e.Detail, &detail); err != nil {
+	if trimmed := bytes.TrimSpace(e.Detail); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &detail.Findings); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal event detail findings array")
+		}
+	} else if err := json.Unmarshal(e.Detail, &detail); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal event detail")
 	}
 
@@ -96,13 +322,231 @@ func (a *App) ParseEvent(e events.SecurityHubEventInput) (*events.SecurityHubV2F
 		return nil, errors.Newf("event contains no findings (event_id: %s)", e.EventID)
 	}
 
-	return events.NewSecurityHubFinding(detail.Findings[0])
+	if limit := a.Config.MaxFindingsPerEvent; limit > 0 && len(detail.Findings) > limit {
+		overflow := len(detail.Findings) - limit
+		if a.Metrics != nil {
+			a.Metrics.RecordFindingsOverflow(overflow)
+		}
+		if a.Config.MaxFindingsMode == MaxFindingsFail {
+			return nil, errors.Newf("event contains %d findings, over APP_MAX_FINDINGS_PER_EVENT %d (event_id: %s)", len(detail.Findings), limit, e.EventID)
+		}
+		a.Logger.Warn("event exceeds max findings, processing the first findings only",
+			"findings", len(detail.Findings),
+			"max_findings", limit,
+			"dropped", overflow,
+			"event_id", e.EventID)
+		detail.Findings = detail.Findings[:limit]
+	}
+
+	findings := make([]*events.SecurityHubV2Finding, 0, len(detail.Findings))
+	for i, raw := range detail.Findings {
+		finding, err := events.NewSecurityHubFinding(raw, a.Config.FieldMappings...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse finding %d (event_id: %s)", i, e.EventID)
+		}
+		finding.NormalizeStatus(a.Config.StatusMappings)
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
 }
 
-func (a *App) LoadRulesFromS3(ctx context.Context, loader *filters.S3RulesLoader, bucket, prefix string) ([]filters.AutoCloseRule, error) {
-	a.Logger.Debug("loading rules from S3", "bucket", bucket, "prefix", prefix)
+// FilterEngine returns the current filter engine. safe for concurrent use
+// with ReloadRules.
+func (a *App) FilterEngine() *filters.FilterEngine {
+	return a.filterEngine.Load()
+}
 
-	rules, err := loader.LoadRules(ctx, bucket, prefix)
+func (a *App) SetFilterEngine(engine *filters.FilterEngine) {
+	a.filterEngine.Store(engine)
+}
+
+// LoadRules returns the env rules followed by the S3 and URL rules, if
+// configured.
+func (a *App) LoadRules(ctx context.Context) ([]filters.AutoCloseRule, error) {
+	rules, _, err := a.loadRules(ctx)
+	return rules, err
+}
+
+// loadRules also returns the rules as loaded from each source, keyed "env",
+// "s3" and "url", so a reload can report which sources changed.
+func (a *App) loadRules(ctx context.Context) ([]filters.AutoCloseRule, map[string][]filters.AutoCloseRule, error) {
+	cfg := a.Config
+	rules := cfg.AutoCloseRules
+	sources := map[string][]filters.AutoCloseRule{"env": cfg.AutoCloseRules}
+
+	if a.RulesLoader != nil {
+		s3Rules, err := a.LoadRulesFromS3(ctx, a.RulesLoader, cfg.AutoCloseRulesS3Bucket, cfg.AutoCloseRulesS3Prefixes)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to load rules from s3://%s (prefixes: %s)", cfg.AutoCloseRulesS3Bucket, strings.Join(cfg.AutoCloseRulesS3Prefixes, ","))
+		}
+		sources["s3"] = s3Rules
+
+		if len(cfg.AutoCloseRules) > 0 {
+			a.Logger.Info("loaded rules from S3 and env", "s3_rules", len(s3Rules), "env_rules", len(cfg.AutoCloseRules))
+			rules = slices.Concat(cfg.AutoCloseRules, s3Rules)
+		} else {
+			a.Logger.Info("loaded rules from S3", "count", len(s3Rules))
+			rules = s3Rules
+		}
+	}
+
+	if a.URLLoader != nil {
+		urlRules, err := a.URLLoader.LoadRules(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to load rules from %s", cfg.AutoCloseRulesURL)
+		}
+		sources["url"] = urlRules
+
+		a.Logger.Info("loaded rules from URL", "count", len(urlRules))
+		rules = slices.Concat(rules, urlRules)
+	}
+
+	rules = filters.ExpandEnv(rules, os.LookupEnv)
+
+	// the informational shortcut runs after every configured rule
+	if cfg.AutoCloseInformational {
+		rules = append(slices.Clip(rules), informationalRule(cfg.InformationalComment))
+	}
+
+	problems := filters.RuleProblems(rules)
+	for _, rule := range rules {
+		for _, notifier := range rule.Notifiers {
+			if !slices.Contains(cfg.Notifiers, notifier) {
+				problems.Add(rule.Name, "selects notifier %q, which isn't in APP_NOTIFIER", notifier)
+			}
+		}
+	}
+	if err := problems.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid auto-close rules")
+	}
+
+	for _, rule := range rules {
+		if rule.Enabled && rule.MatchAll {
+			a.Logger.Warn("rule matches every finding", "rule", rule.Name)
+		}
+		if reason := a.deadRuleReason(&rule); reason != "" {
+			a.Logger.Warn("rule can never close a finding", "rule", rule.Name, "reason", reason)
+		}
+		for _, ref := range rule.UnexpandedEnvRefs() {
+			a.Logger.Warn("rule references a variable that isn't expanded",
+				"rule", rule.Name,
+				"reference", ref,
+				"prefix", filters.RuleVarPrefix)
+		}
+	}
+
+	return rules, sources, nil
+}
+
+// InformationalRuleName names the rule APP_AUTOCLOSE_INFORMATIONAL adds.
+const InformationalRuleName = "auto-close-informational"
+
+// informationalRule silently archives informational findings.
+func informationalRule(comment string) filters.AutoCloseRule {
+	return filters.AutoCloseRule{
+		Name:             InformationalRuleName,
+		Enabled:          true,
+		Filters:          filters.RuleFilters{Severity: []string{"Informational"}},
+		Action:           filters.RuleAction{StatusID: 5, Comment: comment},
+		SkipNotification: true,
+	}
+}
+
+// ReloadRules loads the rules and atomically swaps in a new filter engine.
+// in-flight processing keeps the engine it started with. each reload is
+// logged and recorded as a metric with the rule delta and duration.
+func (a *App) ReloadRules(ctx context.Context) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	start := time.Now()
+	rules, sources, err := a.loadRules(ctx)
+	duration := time.Since(start)
+	if err != nil {
+		a.Logger.Error("failed to reload rules", "duration_ms", duration.Milliseconds(), "error", err)
+		if a.Metrics != nil {
+			a.Metrics.RecordRulesReload(metrics.RulesReload{Duration: duration, Failed: true})
+		}
+		return err
+	}
+
+	var previous []filters.AutoCloseRule
+	if engine := a.FilterEngine(); engine != nil {
+		previous = engine.Rules
+	}
+	reload := ruleDelta(previous, rules)
+	reload.Duration = duration
+
+	engine := filters.NewFilterEngine(rules)
+	if a.Processed != nil {
+		engine.History = a.Processed
+	}
+	if a.Clock != nil {
+		engine.Clock = a.Clock
+	}
+	a.SetFilterEngine(engine)
+
+	a.Logger.Info("reloaded rules",
+		"rules", len(rules),
+		"added", reload.Added,
+		"removed", reload.Removed,
+		"changed", reload.Changed,
+		"sources_changed", changedRuleSources(a.ruleSources, sources),
+		"duration_ms", duration.Milliseconds())
+	if a.Metrics != nil {
+		a.Metrics.RecordRulesReload(reload)
+	}
+	a.ruleSources = sources
+	return nil
+}
+
+// ruleDelta counts the rules added, removed and changed by name between two
+// rule sets.
+func ruleDelta(previous, current []filters.AutoCloseRule) metrics.RulesReload {
+	before := make(map[string]filters.AutoCloseRule, len(previous))
+	for _, rule := range previous {
+		before[rule.Name] = rule
+	}
+
+	var delta metrics.RulesReload
+	for _, rule := range current {
+		old, ok := before[rule.Name]
+		switch {
+		case !ok:
+			delta.Added++
+		case !reflect.DeepEqual(old, rule):
+			delta.Changed++
+		}
+		delete(before, rule.Name)
+	}
+	delta.Removed = len(before)
+	return delta
+}
+
+// changedRuleSources lists the sources whose rules differ from the previous
+// load, in name order. every source counts as changed on the first load.
+func changedRuleSources(previous, current map[string][]filters.AutoCloseRule) []string {
+	var changed []string
+	for source, rules := range current {
+		old, ok := previous[source]
+		if !ok || !reflect.DeepEqual(old, rules) {
+			changed = append(changed, source)
+		}
+	}
+	for source := range previous {
+		if _, ok := current[source]; !ok {
+			changed = append(changed, source)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+func (a *App) LoadRulesFromS3(ctx context.Context, loader *filters.S3RulesLoader, bucket string, prefixes []string) ([]filters.AutoCloseRule, error) {
+	a.Logger.Debug("loading rules from S3", "bucket", bucket, "prefixes", prefixes)
+
+	rules, err := loader.LoadRulesFromPrefixes(ctx, bucket, prefixes)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +558,10 @@ func (a *App) LoadRulesFromS3(ctx context.Context, loader *filters.S3RulesLoader
 func (a *App) CloseFinding(ctx context.Context, finding *events.SecurityHubV2Finding, statusID int32, comment string) error {
 	a.Logger.Debug("closing finding",
 		"uid", finding.Metadata.UID,
-		"status_id", statusID)
+		"status_id", statusID,
+		"status", a.StatusName(int(statusID)))
+
+	comment = actions.FormatComment(a.Config.CommentMode, finding.Comment, comment, a.now())
 
 	err := a.FindingCloser.CloseFinding(ctx, finding, statusID, comment)
 	if err != nil {
@@ -124,11 +571,91 @@ func (a *App) CloseFinding(ctx context.Context, finding *events.SecurityHubV2Fin
 	return nil
 }
 
+// NotifyFinding sends a notification and applies the notification failure
+// policy. failures are ignored when configured so a completed close isn't
+// retried by EventBridge. when annotate is set, a notified finding is stamped
+// with the notify comment.
+func (a *App) NotifyFinding(ctx context.Context, finding *events.SecurityHubV2Finding, annotate bool) error {
+	var comment string
+	if annotate {
+		comment = a.Config.NotifyComment
+	}
+	_, err := a.notifyFinding(ctx, finding, comment)
+	return err
+}
+
+// notifyComment returns the comment stamped on a notified finding, if any.
+// findings no rule matched get the alert comment when one is set.
+func (a *App) notifyComment(d Decision) string {
+	if !d.Annotate {
+		return ""
+	}
+	if d.Rule == nil && a.Config.AlertComment != "" {
+		return a.Config.AlertComment
+	}
+	return a.Config.NotifyComment
+}
+
+// notifyFinding also returns the decision log notification result, which
+// records a failed notification even when failures are ignored. a notified
+// finding is stamped with comment unless it's empty.
+func (a *App) notifyFinding(ctx context.Context, finding *events.SecurityHubV2Finding, comment string) (string, error) {
+	// rapid re-imports of the same finding shouldn't ping again
+	if a.Notified != nil {
+		if a.Notified.Seen(finding.Metadata.UID, a.now()) {
+			a.Logger.Info("suppressing repeat notification",
+				"uid", finding.Metadata.UID,
+				"ttl", a.Config.NotifyDedupTTL)
+			return decisionlog.NotificationSuppressed, nil
+		}
+	}
+
+	if err := a.SendNotification(ctx, finding); err != nil {
+		if a.Config.NotifyIgnoreFailures {
+			return decisionlog.NotificationFailed, nil
+		}
+		return decisionlog.NotificationFailed, err
+	}
+
+	if a.Notified != nil {
+		a.Notified.Add(finding.Metadata.UID, a.now())
+	}
+
+	// the stamp updates the finding, which re-imports it. a finding that
+	// already carries the comment was stamped before and isn't stamped again
+	if comment != "" && strings.Contains(finding.Comment, comment) {
+		a.Logger.Debug("finding already carries notify comment, skipping stamp",
+			"uid", finding.Metadata.UID)
+		return decisionlog.NotificationSent, nil
+	}
+
+	if comment != "" {
+		comment = fmt.Sprintf(a.Config.Locale.Text(locale.StampedAt), comment, a.now().UTC().Format(time.RFC3339))
+		err := a.FindingCloser.AddComment(ctx, finding, comment)
+		if errors.Is(err, actions.ErrFindingNotFound) {
+			a.Logger.Info("finding no longer exists, skipping notify comment",
+				"uid", finding.Metadata.UID,
+				"error", err)
+			return decisionlog.NotificationSent, nil
+		}
+		if err != nil {
+			return decisionlog.NotificationSent, errors.Wrap(err, "failed to add notify comment")
+		}
+	}
+
+	return decisionlog.NotificationSent, nil
+}
+
+// SendNotification notifies with retries and exponential backoff.
 func (a *App) SendNotification(ctx context.Context, finding *events.SecurityHubV2Finding) error {
 	a.Logger.Debug("sending notification",
 		"uid", finding.Metadata.UID)
 
-	err := a.Notifier.Notify(ctx, finding)
+	// retries only resend to the backends that failed
+	sendCtx := notifiers.WithDeliveries(ctx)
+	err := a.retryNotification(ctx, func() error {
+		return a.Notifier.Notify(sendCtx, finding)
+	}, "uid", finding.Metadata.UID)
 	if err != nil {
 		a.Logger.Error("failed to send notification",
 			"error", err,
@@ -142,12 +669,442 @@ func (a *App) SendNotification(ctx context.Context, finding *events.SecurityHubV
 	return nil
 }
 
+// retryNotification runs send with APP_NOTIFY_RETRIES retries and exponential
+// backoff, logging each retry with attrs.
+func (a *App) retryNotification(ctx context.Context, send func() error, attrs ...any) error {
+	backoff := a.Config.NotifyRetryBackoff
+	var err error
+	for attempt := 0; attempt <= a.Config.NotifyRetries; attempt++ {
+		if attempt > 0 {
+			a.Logger.Warn("retrying notification",
+				append([]any{"error", err, "attempt", attempt}, attrs...)...)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if err = send(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 func (a *App) Process(ctx context.Context, evt events.SecurityHubEventInput) error {
-	finding, err := a.ParseEvent(evt)
+	findings, err := a.ParseEvent(evt)
 	if err != nil {
+		// a rejected oversized event is still counted
+		a.FlushMetrics()
 		return err
 	}
 
+	ctx, span := a.Telemetry.StartProcess(ctx, evt.EventID, len(findings))
+
+	// every finding in the event sees the same rules, even across a reload
+	inv := &invocation{engine: a.FilterEngine(), eventID: evt.EventID}
+
+	var errs []error
+	seen := make(map[string]bool, len(findings))
+	for _, finding := range findings {
+		// the same finding can be re-imported more than once within a batch,
+		// even with a new modified time or status. only the first copy is
+		// closed or notified
+		uid := finding.Metadata.UID
+		if seen[uid] {
+			a.Logger.Info("skipping duplicate finding in batch",
+				"uid", uid,
+				"fingerprint", finding.Fingerprint(),
+				"event_id", evt.EventID)
+			entry := decisionlog.NewEntry(evt.EventID, finding)
+			entry.Action = decisionlog.ActionDuplicate
+			a.recordDecision(ctx, &entry, a.now(), nil)
+			continue
+		}
+		seen[uid] = true
+
+		if err := a.processFinding(ctx, inv, finding); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := a.sendDigests(ctx, inv); err != nil {
+		errs = append(errs, err)
+	}
+
+	a.FlushMetrics()
+
+	// end the span first so it is included in the flush
+	span.End()
+	a.FlushTelemetry(ctx)
+
+	return errors.Join(errs...)
+}
+
+// FlushMetrics writes the rule hit counters as EMF, if metrics are enabled.
+// failures are logged rather than failing the event.
+func (a *App) FlushMetrics() {
+	if a.Metrics == nil {
+		return
+	}
+	if err := a.Metrics.Flush(a.now()); err != nil {
+		a.Logger.Warn("failed to flush metrics", "error", err)
+	}
+}
+
+// FlushTelemetry exports buffered spans and metrics, if opentelemetry is
+// enabled. failures are logged rather than failing the event.
+func (a *App) FlushTelemetry(ctx context.Context) {
+	if err := a.Telemetry.Flush(ctx); err != nil {
+		a.Logger.Warn("failed to flush telemetry", "error", err)
+	}
+}
+
+// recordAudit writes the audit record for a rule acting on a finding. sink
+// failures are logged since the action already happened.
+func (a *App) recordAudit(ctx context.Context, rule *filters.AutoCloseRule, action string, finding *events.SecurityHubV2Finding) {
+	if a.Audit == nil {
+		return
+	}
+
+	record := audit.Record{
+		Time:       a.now().UTC(),
+		FindingUID: finding.Metadata.UID,
+		AccountUID: finding.Cloud.Account.UID,
+		Rule:       rule.Name,
+		Action:     action,
+		StatusID:   rule.Action.StatusID,
+		Status:     a.StatusName(int(rule.Action.StatusID)),
+		Owner:      rule.Owner,
+		Ticket:     rule.Ticket,
+		Reason:     rule.Reason,
+	}
+	if err := a.Audit.Record(ctx, record); err != nil {
+		a.Logger.Warn("failed to write audit record",
+			"error", err,
+			"uid", finding.Metadata.UID)
+	}
+}
+
+// recordDecision writes the decision log entry for a processed finding, if
+// the decision log is enabled. sink failures are logged since the finding was
+// already handled.
+func (a *App) recordDecision(ctx context.Context, entry *decisionlog.Entry, start time.Time, err error) {
+	if a.Decisions == nil {
+		return
+	}
+
+	entry.Time = start.UTC()
+	entry.Status = a.StatusName(entry.StatusID)
+	entry.DurationMS = float64(a.now().Sub(start).Microseconds()) / 1000
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if werr := a.Decisions.Write(ctx, *entry); werr != nil {
+		a.Logger.Warn("failed to write decision log entry",
+			"error", werr,
+			"uid", entry.FindingUID)
+	}
+}
+
+// StatusName returns the name shown for a status id, using APP_STATUS_NAMES
+// over the OCSF names.
+func (a *App) StatusName(statusID int) string {
+	return a.Config.StatusNames.Name(statusID)
+}
+
+func (a *App) recordRuleHit(ctx context.Context, rule *filters.AutoCloseRule, action string, finding *events.SecurityHubV2Finding) {
+	if a.Metrics != nil {
+		a.Metrics.RecordRuleHit(rule.Name, action, finding.Severity)
+	}
+	a.Telemetry.RecordRuleHit(ctx, rule.Name, action, finding.Severity)
+}
+
+// BlockedReasonTooOld is the blocked reason for findings first seen longer ago
+// than APP_MAX_CLOSE_AGE_DAYS.
+const BlockedReasonTooOld = "first seen before max close age"
+
+// CloseBlockedReason returns why a finding matching a rule must not be
+// closed, or an empty string if closing is allowed.
+func (a *App) CloseBlockedReason(finding *events.SecurityHubV2Finding) string {
+	if slices.Contains(a.Config.ProtectedAccounts, finding.Cloud.Account.UID) {
+		return "protected account"
+	}
+	for _, findingType := range finding.FindingInfo.Types {
+		if filters.MatchesAnyGlob(a.Config.NeverAutoCloseTypes, findingType) {
+			return "protected finding type"
+		}
+	}
+	if len(a.Config.AutoCloseSeverities) > 0 {
+		enabled := slices.ContainsFunc(a.Config.AutoCloseSeverities, func(s string) bool {
+			return strings.EqualFold(s, finding.Severity)
+		})
+		if !enabled {
+			return "severity not enabled for auto-close"
+		}
+	}
+	if a.Config.MaxCloseAgeDays > 0 {
+		// findings without a first seen time have no age to check
+		firstSeen := finding.Timestamp(events.TimestampFirstSeen)
+		maxAge := time.Duration(a.Config.MaxCloseAgeDays) * 24 * time.Hour
+		if !firstSeen.IsZero() && a.now().Sub(firstSeen) > maxAge {
+			return BlockedReasonTooOld
+		}
+	}
+	return ""
+}
+
+// now returns the time from the app's clock.
+func (a *App) now() time.Time {
+	if a.Clock != nil {
+		return a.Clock.Now()
+	}
+	return time.Now()
+}
+
+// deadRuleReason returns which global guardrail blocks every finding the
+// rule's filters can match, or an empty string if the rule can close some.
+func (a *App) deadRuleReason(rule *filters.AutoCloseRule) string {
+	if !rule.Enabled || rule.Action.Observe() {
+		return ""
+	}
+
+	cfg := a.Config
+	f := rule.Filters
+	if len(f.Accounts) > 0 && allFunc(f.Accounts, func(account string) bool {
+		return slices.Contains(cfg.ProtectedAccounts, account)
+	}) {
+		return "protected account"
+	}
+	if len(f.FindingTypes) > 0 && allFunc(f.FindingTypes, func(findingType string) bool {
+		return filters.MatchesAnyGlob(cfg.NeverAutoCloseTypes, findingType)
+	}) {
+		return "protected finding type"
+	}
+	if len(f.Severity) > 0 && len(cfg.AutoCloseSeverities) > 0 && allFunc(f.Severity, func(severity string) bool {
+		return !slices.ContainsFunc(cfg.AutoCloseSeverities, func(s string) bool {
+			return strings.EqualFold(s, severity)
+		})
+	}) {
+		return "severity not enabled for auto-close"
+	}
+	return ""
+}
+
+func allFunc(items []string, fn func(string) bool) bool {
+	for _, item := range items {
+		if !fn(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// invocation is the state shared by every finding in a single Process call.
+type invocation struct {
+	engine  *filters.FilterEngine
+	eventID string
+	closes  int
+	digests []*pendingDigest
+}
+
+// pendingDigest collects the findings a rule closed for its digest.
+type pendingDigest struct {
+	rule     *filters.AutoCloseRule
+	findings []*events.SecurityHubV2Finding
+}
+
+// addToDigest adds a finding to its rule's digest, keyed by rule name.
+func (inv *invocation) addToDigest(rule *filters.AutoCloseRule, finding *events.SecurityHubV2Finding) {
+	for _, pending := range inv.digests {
+		if pending.rule.Name == rule.Name {
+			pending.findings = append(pending.findings, finding)
+			return
+		}
+	}
+	inv.digests = append(inv.digests, &pendingDigest{rule: rule, findings: []*events.SecurityHubV2Finding{finding}})
+}
+
+// Decision is what processing a finding will do, before any update or
+// notification is sent.
+type Decision struct {
+	// Rule is the matched rule, if any
+	Rule *filters.AutoCloseRule
+	// Action is the metrics action for the matched rule
+	Action        string
+	BlockedReason string
+	Close         bool
+	// Notify is whether a notification is sent when a notifier is configured
+	Notify bool
+	// Annotate stamps the notify or alert comment on the notified finding
+	Annotate bool
+}
+
+// decide evaluates the rules and close guards for a finding without side
+// effects.
+func (a *App) decide(inv *invocation, finding *events.SecurityHubV2Finding) Decision {
+	d := a.decideRule(inv, finding)
+	if a.Config.SilenceInformational && finding.IsInformational() {
+		d.Notify = false
+	}
+	// muted accounts are still closed by rules, unlike protected accounts
+	if filters.MatchesAnyGlob(a.Config.MuteNotifyAccounts, finding.Cloud.Account.UID) {
+		d.Notify = false
+	}
+	if d.Action == metrics.ActionClosed && !a.notifiesClose(finding) {
+		d.Notify = false
+	}
+	return d
+}
+
+// notifiesClose reports whether an auto-closed finding is severe enough to
+// notify under APP_AUTOCLOSE_NOTIFY_MIN_SEVERITY_ID. findings without a
+// severity_id are ranked by their severity string.
+func (a *App) notifiesClose(finding *events.SecurityHubV2Finding) bool {
+	if a.Config.CloseNotifyMinSeverityID == 0 {
+		return true
+	}
+	id := finding.SeverityID
+	if id == 0 {
+		id = events.SeverityID(finding.Severity)
+	}
+	return id >= a.Config.CloseNotifyMinSeverityID
+}
+
+func (a *App) decideRule(inv *invocation, finding *events.SecurityHubV2Finding) Decision {
+	rule, matched := inv.engine.FindMatchingRule(finding)
+	if !matched {
+		return Decision{Notify: a.isAlertable(finding), Annotate: true}
+	}
+
+	// a blocked finding is handled as if nothing matched, except that an old
+	// finding is always handed to a human since it may be long-standing risk
+	if reason := a.CloseBlockedReason(finding); reason != "" {
+		return Decision{
+			Rule:          rule,
+			Action:        metrics.ActionBlocked,
+			BlockedReason: reason,
+			Notify:        a.isAlertable(finding) || reason == BlockedReasonTooOld,
+			Annotate:      true,
+		}
+	}
+
+	switch {
+	case rule.Action.Observe():
+		// observe rules are staged: record the match but leave the finding alone
+		return Decision{Rule: rule, Action: metrics.ActionObserved, Notify: !rule.SkipNotification}
+	case int32(finding.StatusID) == rule.Action.StatusID:
+		// skip if finding is already in the desired state to avoid feedback loops
+		return Decision{Rule: rule, Action: metrics.ActionSkipped}
+	case a.Config.MaxClosesPerInvocation > 0 && inv.closes >= a.Config.MaxClosesPerInvocation:
+		// a runaway rule must not mass-close findings, so hand the rest to a
+		// human. no stamp, since its re-import would close the finding next time
+		return Decision{Rule: rule, Action: metrics.ActionCapped, Notify: true}
+	}
+
+	return Decision{Rule: rule, Action: metrics.ActionClosed, Close: true, Notify: !rule.SkipNotification}
+}
+
+// applyCloseDelay holds back a close until the finding matches again at
+// least close_after_seconds after the bot first matched it. the first match is
+// notified so a human can act during the grace period, later matches within
+// it are not.
+func (a *App) applyCloseDelay(ctx context.Context, d *Decision, finding *events.SecurityHubV2Finding) error {
+	if a.Pending == nil {
+		return errors.Newf("rule %q sets close_after_seconds but no pending close store is configured", d.Rule.Name)
+	}
+
+	uid := finding.Metadata.UID
+	now := a.now()
+	firstMatched, ok, err := a.Pending.Get(ctx, uid)
+	if err != nil {
+		return errors.Wrap(err, "failed to check pending close")
+	}
+
+	switch {
+	case !ok:
+		if err := a.Pending.Put(ctx, uid, now); err != nil {
+			return errors.Wrap(err, "failed to record pending close")
+		}
+		*d = Decision{Rule: d.Rule, Action: metrics.ActionDelayed, Notify: true, Annotate: true}
+	case now.Sub(firstMatched) < d.Rule.Action.CloseDelay():
+		*d = Decision{Rule: d.Rule, Action: metrics.ActionDelayed}
+	}
+	return nil
+}
+
+// forgetPending clears the grace period record once a delayed close is done.
+// failures are logged since the finding is already closed.
+func (a *App) forgetPending(ctx context.Context, rule *filters.AutoCloseRule, finding *events.SecurityHubV2Finding) {
+	if rule.Action.CloseAfterSeconds == 0 || a.Pending == nil {
+		return
+	}
+	if err := a.Pending.Delete(ctx, finding.Metadata.UID); err != nil {
+		a.Logger.Warn("failed to clear pending close",
+			"error", err,
+			"uid", finding.Metadata.UID)
+	}
+}
+
+// isAlertable reports whether a finding no rule closed is notified. with
+// APP_ALERT_MIN_SEVERITY set, new findings at or above it in the severity
+// ranking are alerted instead of the default Critical, High and Medium. failed
+// compliance checks are always alerted, unless APP_FAIL_MIN_SEVERITY sets the
+// severity they are ranked at instead.
+func (a *App) isAlertable(finding *events.SecurityHubV2Finding) bool {
+	if a.Config.AlertMinSeverity == "" && a.Config.FailMinSeverity == "" {
+		return finding.IsAlertable()
+	}
+	if finding.Status != "New" {
+		return false
+	}
+	if finding.IsFailedCompliance() && a.Config.FailMinSeverity == "" {
+		return true
+	}
+
+	// rank a copy so the stored finding keeps its reported severity
+	effective := *finding
+	effective.Severity = a.effectiveSeverity(finding)
+	if a.Config.AlertMinSeverity == "" {
+		return slices.Contains(events.AlertSeverities, effective.Severity)
+	}
+	return a.Config.SeverityRanking().AtLeast(&effective, a.Config.AlertMinSeverity)
+}
+
+// effectiveSeverity is the severity a finding is alerted and routed by, with
+// failed compliance checks raised to APP_FAIL_MIN_SEVERITY.
+func (a *App) effectiveSeverity(finding *events.SecurityHubV2Finding) string {
+	return a.Config.SeverityRanking().EffectiveSeverity(finding, a.Config.FailMinSeverity)
+}
+
+// Evaluate returns the decision for a single finding and an explanation of
+// every rule, without updating the finding or notifying.
+func (a *App) Evaluate(finding *events.SecurityHubV2Finding) (Decision, []filters.RuleResult) {
+	engine := a.FilterEngine()
+	return a.decide(&invocation{engine: engine}, finding), engine.Explain(finding)
+}
+
+func (a *App) ProcessFinding(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	return a.processFinding(ctx, &invocation{engine: a.FilterEngine()}, finding)
+}
+
+func (a *App) processFinding(ctx context.Context, inv *invocation, finding *events.SecurityHubV2Finding) (err error) {
+	ctx, span := a.Telemetry.StartFinding(ctx, finding)
+	defer span.End()
+
+	entry := decisionlog.NewEntry(inv.eventID, finding)
+	start := a.now()
+	defer func() {
+		a.recordDecision(ctx, &entry, start, err)
+		// a failed finding is retried as new, so only successes recur
+		if err == nil && a.Processed != nil {
+			a.Processed.Add(finding.Metadata.UID, a.now())
+		}
+	}()
+
 	if a.Config.DebugEnabled {
 		a.Logger.Debug("processing finding",
 			"uid", finding.Metadata.UID,
@@ -155,41 +1112,182 @@ func (a *App) Process(ctx context.Context, evt events.SecurityHubEventInput) err
 			"severity", finding.Severity)
 	}
 
-	if matchedRule, matched := a.FilterEngine.FindMatchingRule(finding); matched {
+	if !finding.HasKnownSeverity() {
+		a.Logger.Warn("unknown severity",
+			"uid", finding.Metadata.UID,
+			"severity", finding.Severity,
+			"severity_id", finding.SeverityID)
+		if a.Metrics != nil {
+			a.Metrics.RecordUnknownSeverity(finding.Severity)
+		}
+	}
+
+	d := a.decide(inv, finding)
+	if d.Action == metrics.ActionClosed && d.Rule.Action.CloseAfterSeconds > 0 {
+		if err := a.applyCloseDelay(ctx, &d, finding); err != nil {
+			entry.Rule = d.Rule.Name
+			entry.Action = decisionlog.ActionError
+			return err
+		}
+	}
+	if d.Rule != nil {
+		entry.Rule = d.Rule.Name
+		entry.Action = d.Action
+		entry.BlockedReason = d.BlockedReason
 		if a.Config.DebugEnabled {
-			a.Logger.Debug("finding matched rule", "rule", matchedRule.Name)
+			a.Logger.Debug("finding matched rule", "rule", d.Rule.Name)
 		}
+	}
 
-		// skip if finding is already in the desired state to avoid feedback loops
-		if int32(finding.StatusID) == matchedRule.Action.StatusID {
-			if a.Config.DebugEnabled {
-				a.Logger.Debug("finding already in desired state, skipping update",
-					"uid", finding.Metadata.UID,
-					"status_id", finding.StatusID)
-			}
+	switch d.Action {
+	case metrics.ActionBlocked:
+		a.Logger.Info("skipping auto-close for matched finding",
+			"uid", finding.Metadata.UID,
+			"rule", d.Rule.Name,
+			"reason", d.BlockedReason)
+	case metrics.ActionObserved:
+		a.Logger.Info("observed finding",
+			"uid", finding.Metadata.UID,
+			"rule", d.Rule.Name,
+			"owner", d.Rule.Owner,
+			"ticket", d.Rule.Ticket)
+		a.recordAudit(ctx, d.Rule, metrics.ActionObserved, finding)
+	case metrics.ActionSkipped:
+		if a.Config.DebugEnabled {
+			a.Logger.Debug("no-op, already in target status",
+				"uid", finding.Metadata.UID,
+				"rule", d.Rule.Name,
+				"status_id", finding.StatusID,
+				"status", a.StatusName(finding.StatusID))
+		}
+	case metrics.ActionCapped:
+		a.Logger.Warn("auto-close cap reached, leaving finding open",
+			"uid", finding.Metadata.UID,
+			"rule", d.Rule.Name,
+			"max_closes", a.Config.MaxClosesPerInvocation)
+	case metrics.ActionDelayed:
+		a.Logger.Info("delaying auto-close for matched finding",
+			"uid", finding.Metadata.UID,
+			"rule", d.Rule.Name,
+			"close_after", d.Rule.Action.CloseDelay())
+	case metrics.ActionClosed:
+		err := a.CloseFinding(ctx, finding, d.Rule.Action.StatusID, d.Rule.ActionComment(a.Config.TicketURLTemplate, a.Config.Locale))
+		if err == nil || errors.Is(err, actions.ErrFindingNotFound) {
+			a.forgetPending(ctx, d.Rule, finding)
+		}
+		if errors.Is(err, actions.ErrFindingNotFound) {
+			// a retry can't close a finding that no longer exists
+			a.Logger.Info("finding no longer exists, skipping auto-close",
+				"uid", finding.Metadata.UID,
+				"rule", d.Rule.Name,
+				"error", err)
+			a.recordRuleHit(ctx, d.Rule, metrics.ActionSkipped, finding)
+			entry.Action = metrics.ActionSkipped
 			return nil
 		}
-
-		err := a.CloseFinding(ctx, finding, matchedRule.Action.StatusID, matchedRule.Action.Comment)
 		if err != nil {
+			entry.Action = decisionlog.ActionError
 			return errors.Wrap(err, "failed to auto-close finding")
 		}
+		inv.closes++
 
 		a.Logger.Info("auto-closed finding",
 			"uid", finding.Metadata.UID,
-			"rule", matchedRule.Name,
-			"status_id", matchedRule.Action.StatusID)
+			"rule", d.Rule.Name,
+			"status_id", d.Rule.Action.StatusID,
+			"status", a.StatusName(int(d.Rule.Action.StatusID)),
+			"owner", d.Rule.Owner,
+			"ticket", d.Rule.Ticket,
+			"reason", d.Rule.Reason)
 
-		if !matchedRule.SkipNotification && a.Notifier != nil {
-			return a.SendNotification(ctx, finding)
-		}
+		a.recordAudit(ctx, d.Rule, metrics.ActionClosed, finding)
+	}
+
+	if d.Rule != nil {
+		a.recordRuleHit(ctx, d.Rule, d.Action, finding)
+	}
 
+	if !d.Notify || a.Notifier == nil {
 		return nil
 	}
 
-	if a.Notifier != nil && finding.IsAlertable() {
-		return a.SendNotification(ctx, finding)
+	// closes are summarized per rule once the whole event is processed
+	if d.Action == metrics.ActionClosed && a.Config.AutoCloseDigest {
+		if a.Notified != nil {
+			if a.Notified.Seen(finding.Metadata.UID, a.now()) {
+				entry.Notification = decisionlog.NotificationSuppressed
+				return nil
+			}
+		}
+		inv.addToDigest(d.Rule, finding)
+		entry.Notification = decisionlog.NotificationDigest
+		return nil
 	}
 
-	return nil
+	if severity := a.effectiveSeverity(finding); severity != finding.Severity {
+		ctx = notifiers.WithSeverity(ctx, severity)
+	}
+
+	// notifications for findings a rule acted on carry the rule
+	if d.Action == metrics.ActionClosed || d.Action == metrics.ActionObserved || d.Action == metrics.ActionDelayed {
+		ctx = a.ruleNotifyContext(ctx, d.Rule)
+	}
+	entry.Notification, err = a.notifyFinding(ctx, finding, a.notifyComment(d))
+	return err
+}
+
+// ruleNotifyContext returns a context carrying the rule for notifications
+// about findings it acted on.
+func (a *App) ruleNotifyContext(ctx context.Context, rule *filters.AutoCloseRule) context.Context {
+	ctx = notifiers.WithMatchedRule(ctx, rule.Name)
+	if rule.Ticket != "" {
+		ctx = notifiers.WithTicket(ctx, rule.Ticket, rule.TicketURL(a.Config.TicketURLTemplate))
+	}
+	if len(rule.Notifiers) > 0 {
+		ctx = notifiers.WithSelectedNotifiers(ctx, rule.Notifiers)
+	}
+	if rule.ConsoleRegion != "" {
+		ctx = notifiers.WithConsoleRegion(ctx, rule.ConsoleRegion)
+	}
+	return ctx
+}
+
+// sendDigests sends one notification per rule for the findings it closed
+// during the invocation, in the order the rules first closed a finding.
+// failures follow APP_NOTIFY_IGNORE_FAILURES like single notifications, and
+// only findings in a sent digest are marked as notified.
+func (a *App) sendDigests(ctx context.Context, inv *invocation) error {
+	var errs []error
+	for _, pending := range inv.digests {
+		digest := notifiers.Digest{
+			Rule:     pending.rule.Name,
+			Findings: pending.findings,
+			Status:   a.StatusName(int(pending.rule.Action.StatusID)),
+		}
+		ruleCtx := notifiers.WithDeliveries(a.ruleNotifyContext(ctx, pending.rule))
+		err := a.retryNotification(ctx, func() error {
+			return notifiers.NotifyDigest(ruleCtx, a.Notifier, digest)
+		}, "rule", digest.Rule)
+		if err != nil {
+			a.Logger.Error("failed to send digest",
+				"error", err,
+				"rule", digest.Rule,
+				"findings", len(digest.Findings))
+			if !a.Config.NotifyIgnoreFailures {
+				errs = append(errs, errors.Wrapf(err, "failed to send digest for rule %s", digest.Rule))
+			}
+			continue
+		}
+
+		if a.Notified != nil {
+			for _, finding := range digest.Findings {
+				a.Notified.Add(finding.Metadata.UID, a.now())
+			}
+		}
+
+		a.Logger.Info("sent digest",
+			"rule", digest.Rule,
+			"findings", len(digest.Findings))
+	}
+	return errors.Join(errs...)
 }
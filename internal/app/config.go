@@ -2,48 +2,426 @@ package app
 
 import (
 	"encoding/json"
+	"maps"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/actions"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/filters"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/locale"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/notifiers"
 )
 
 type Config struct {
-	DebugEnabled           bool
-	AwsConsoleURL          string
-	AwsAccessPortalURL     string
-	AwsAccessRoleName      string
-	AWSSecurityHubv2Region string
-	AutoCloseRules         []filters.AutoCloseRule
-	AutoCloseRulesS3Bucket string
-	AutoCloseRulesS3Prefix string
-	SlackEnabled           bool
-	SlackToken             string
-	SlackChannel           string
+	DebugEnabled             bool
+	LogFields                map[string]string
+	HTTPTimeout              time.Duration
+	HTTPProxy                *url.URL
+	AwsConsoleURL            string
+	AwsAccessPortalURL       string
+	AwsAccessRoleName        string
+	AwsAccessRoleNames       map[string]string
+	AwsConsoleRegion         string
+	AWSSecurityHubv2Region   string
+	AWSEndpointSecurityHub   string
+	AWSEndpointS3            string
+	AWSEndpointSNS           string
+	AggregationRegion        string
+	AutoCloseRules           []filters.AutoCloseRule
+	AutoCloseRulesS3Bucket   string
+	AutoCloseRulesS3Prefixes []string
+	AutoCloseRulesURL        string
+	AutoCloseRulesURLAuth    string
+	AutoCloseRulesURLTimeout time.Duration
+	AutoCloseSeverities      []string
+	NeverAutoCloseTypes      []string
+	AutoCloseInformational   bool
+	AutoCloseDigest          bool
+	InformationalComment     string
+	SilenceInformational     bool
+	CommentMode              string
+	TicketURLTemplate        string
+	CategoryMappings         []events.CategoryMapping
+	StatusMappings           map[string]int
+	StatusNames              events.StatusNames
+	FieldMappings            []events.FieldMapping
+	ProtectedAccounts        []string
+	MuteNotifyAccounts       []string
+	MaxClosesPerInvocation   int
+	MaxFindingsPerEvent      int
+	MaxFindingsMode          string
+	CloseNotifyMinSeverityID int
+	MaxCloseAgeDays          int
+	MetricsEnabled           bool
+	OTelEnabled              bool
+	DecisionLogEnabled       bool
+	DecisionLogS3Bucket      string
+	DecisionLogS3Prefix      string
+	PendingCloseS3Bucket     string
+	PendingCloseS3Prefix     string
+	Notifier                 string
+	Notifiers                []string
+	NotifyFooter             string
+	NotifyRetries            int
+	NotifyRetryBackoff       time.Duration
+	NotifyIgnoreFailures     bool
+	NotifyComment            string
+	AlertComment             string
+	Locale                   locale.Locale
+	NotifyDedupTTL           time.Duration
+	NotifyDedupSize          int
+	RecurrenceTTL            time.Duration
+	WebhookURL               string
+	ChatbotSNSTopicARN       string
+	SlackEnabled             bool
+	SlackToken               string
+	SlackChannel             string
+	SlackFields              []string
+	SlackCategoryFields      map[string][]string
+	SlackChannelRoutes       []notifiers.ChannelRoute
+	SeverityOrder            []string
+	AlertMinSeverity         string
+	FailMinSeverity          string
+	SlackVerify              bool
+	SlackAttachRaw           bool
+	SlackMaxBlocks           int
 }
 
 func NewConfig() (*Config, error) {
 	debugEnabled, _ := strconv.ParseBool(os.Getenv("APP_DEBUG_ENABLED"))
+	notifyIgnoreFailures, _ := strconv.ParseBool(os.Getenv("APP_NOTIFY_IGNORE_FAILURES"))
+	metricsEnabled, _ := strconv.ParseBool(os.Getenv("APP_METRICS_ENABLED"))
+	otelEnabled, _ := strconv.ParseBool(os.Getenv("APP_OTEL_ENABLED"))
+	decisionLogEnabled, _ := strconv.ParseBool(os.Getenv("APP_DECISION_LOG_ENABLED"))
+	autoCloseInformational, _ := strconv.ParseBool(os.Getenv("APP_AUTOCLOSE_INFORMATIONAL"))
+	autoCloseDigest, _ := strconv.ParseBool(os.Getenv("APP_AUTOCLOSE_DIGEST"))
+	silenceInformational, _ := strconv.ParseBool(os.Getenv("APP_SILENCE_INFORMATIONAL"))
+	slackVerify, _ := strconv.ParseBool(os.Getenv("APP_SLACK_VERIFY"))
+	slackAttachRaw, _ := strconv.ParseBool(os.Getenv("APP_SLACK_ATTACH_RAW"))
 
 	cfg := Config{
-		DebugEnabled:           debugEnabled,
-		AwsConsoleURL:          os.Getenv("APP_AWS_CONSOLE_URL"),
-		AwsAccessPortalURL:     os.Getenv("APP_AWS_ACCESS_PORTAL_URL"),
-		AwsAccessRoleName:      os.Getenv("APP_AWS_ACCESS_ROLE_NAME"),
-		AWSSecurityHubv2Region: os.Getenv("APP_AWS_SECURITYHUBV2_REGION"),
-		AutoCloseRulesS3Bucket: os.Getenv("APP_AUTO_CLOSE_RULES_S3_BUCKET"),
-		AutoCloseRulesS3Prefix: os.Getenv("APP_AUTO_CLOSE_RULES_S3_PREFIX"),
-		SlackToken:             os.Getenv("APP_SLACK_TOKEN"),
-		SlackChannel:           os.Getenv("APP_SLACK_CHANNEL"),
+		DebugEnabled:             debugEnabled,
+		AwsConsoleURL:            os.Getenv("APP_AWS_CONSOLE_URL"),
+		AwsAccessPortalURL:       os.Getenv("APP_AWS_ACCESS_PORTAL_URL"),
+		AwsAccessRoleName:        os.Getenv("APP_AWS_ACCESS_ROLE_NAME"),
+		AwsConsoleRegion:         os.Getenv("APP_AWS_CONSOLE_REGION"),
+		AWSSecurityHubv2Region:   os.Getenv("APP_AWS_SECURITYHUBV2_REGION"),
+		AWSEndpointSecurityHub:   os.Getenv("APP_AWS_ENDPOINT_SECURITYHUB"),
+		AWSEndpointS3:            os.Getenv("APP_AWS_ENDPOINT_S3"),
+		AWSEndpointSNS:           os.Getenv("APP_AWS_ENDPOINT_SNS"),
+		AggregationRegion:        os.Getenv("APP_AGGREGATION_REGION"),
+		AutoCloseRulesS3Bucket:   os.Getenv("APP_AUTO_CLOSE_RULES_S3_BUCKET"),
+		AutoCloseRulesS3Prefixes: parseList(os.Getenv("APP_AUTO_CLOSE_RULES_S3_PREFIX")),
+		AutoCloseRulesURL:        os.Getenv("APP_AUTO_CLOSE_RULES_URL"),
+		AutoCloseRulesURLAuth:    os.Getenv("APP_AUTO_CLOSE_RULES_URL_AUTH"),
+		AutoCloseRulesURLTimeout: 10 * time.Second,
+		AutoCloseSeverities:      parseList(os.Getenv("APP_AUTOCLOSE_SEVERITIES")),
+		NeverAutoCloseTypes:      parseList(os.Getenv("APP_NEVER_AUTOCLOSE_TYPES")),
+		AutoCloseInformational:   autoCloseInformational,
+		AutoCloseDigest:          autoCloseDigest,
+		InformationalComment:     os.Getenv("APP_INFORMATIONAL_COMMENT"),
+		SilenceInformational:     silenceInformational,
+		CommentMode:              os.Getenv("APP_COMMENT_MODE"),
+		MaxFindingsMode:          os.Getenv("APP_MAX_FINDINGS_MODE"),
+		TicketURLTemplate:        os.Getenv("APP_TICKET_URL_TEMPLATE"),
+		ProtectedAccounts:        parseList(os.Getenv("APP_PROTECTED_ACCOUNTS")),
+		MuteNotifyAccounts:       parseList(os.Getenv("APP_MUTE_NOTIFY_ACCOUNTS")),
+		MetricsEnabled:           metricsEnabled,
+		OTelEnabled:              otelEnabled,
+		DecisionLogEnabled:       decisionLogEnabled,
+		DecisionLogS3Bucket:      os.Getenv("APP_DECISION_LOG_S3_BUCKET"),
+		DecisionLogS3Prefix:      os.Getenv("APP_DECISION_LOG_S3_PREFIX"),
+		PendingCloseS3Bucket:     os.Getenv("APP_PENDING_CLOSE_S3_BUCKET"),
+		PendingCloseS3Prefix:     os.Getenv("APP_PENDING_CLOSE_S3_PREFIX"),
+		Notifier:                 os.Getenv("APP_NOTIFIER"),
+		NotifyFooter:             os.Getenv("APP_NOTIFY_FOOTER"),
+		NotifyRetries:            2,
+		NotifyRetryBackoff:       500 * time.Millisecond,
+		NotifyIgnoreFailures:     notifyIgnoreFailures,
+		NotifyComment:            os.Getenv("APP_NOTIFY_COMMENT"),
+		AlertComment:             os.Getenv("APP_ALERT_COMMENT"),
+		NotifyDedupSize:          1000,
+		RecurrenceTTL:            24 * time.Hour,
+		WebhookURL:               os.Getenv("APP_WEBHOOK_URL"),
+		ChatbotSNSTopicARN:       os.Getenv("APP_CHATBOT_SNS_TOPIC_ARN"),
+		SlackToken:               os.Getenv("APP_SLACK_TOKEN"),
+		SlackChannel:             os.Getenv("APP_SLACK_CHANNEL"),
+		SlackVerify:              slackVerify,
+		SlackAttachRaw:           slackAttachRaw,
+		SeverityOrder:            parseList(os.Getenv("APP_SEVERITY_ORDER")),
+		AlertMinSeverity:         os.Getenv("APP_ALERT_MIN_SEVERITY"),
+		FailMinSeverity:          os.Getenv("APP_FAIL_MIN_SEVERITY"),
+	}
+
+	if v := os.Getenv("APP_HTTP_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_HTTP_TIMEOUT")
+		}
+		cfg.HTTPTimeout = timeout
+	}
+
+	if v := os.Getenv("APP_HTTP_PROXY"); v != "" {
+		proxyURL, err := url.Parse(v)
+		if err != nil || proxyURL.Host == "" {
+			return nil, errors.Newf("invalid APP_HTTP_PROXY: %s", v)
+		}
+		cfg.HTTPProxy = proxyURL
+	}
+
+	if cfg.AutoCloseRulesURL != "" {
+		rulesURL, err := url.Parse(cfg.AutoCloseRulesURL)
+		if err != nil || (rulesURL.Scheme != "http" && rulesURL.Scheme != "https") || rulesURL.Host == "" {
+			return nil, errors.Newf("invalid APP_AUTO_CLOSE_RULES_URL: %s (expected an http or https url)", cfg.AutoCloseRulesURL)
+		}
+	}
+
+	if v := os.Getenv("APP_AUTO_CLOSE_RULES_URL_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout < 0 {
+			return nil, errors.Newf("invalid APP_AUTO_CLOSE_RULES_URL_TIMEOUT: %s", v)
+		}
+		cfg.AutoCloseRulesURLTimeout = timeout
+	}
+
+	for _, endpoint := range []struct{ name, value string }{
+		{"APP_AWS_ENDPOINT_SECURITYHUB", cfg.AWSEndpointSecurityHub},
+		{"APP_AWS_ENDPOINT_S3", cfg.AWSEndpointS3},
+		{"APP_AWS_ENDPOINT_SNS", cfg.AWSEndpointSNS},
+	} {
+		if endpoint.value == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint.value); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errors.Newf("invalid %s: %s", endpoint.name, endpoint.value)
+		}
+	}
+
+	if v := os.Getenv("APP_MAX_CLOSES_PER_INVOCATION"); v != "" {
+		maxCloses, err := strconv.Atoi(v)
+		if err != nil || maxCloses < 0 {
+			return nil, errors.Newf("invalid APP_MAX_CLOSES_PER_INVOCATION: %s", v)
+		}
+		cfg.MaxClosesPerInvocation = maxCloses
+	}
+
+	if v := os.Getenv("APP_MAX_FINDINGS_PER_EVENT"); v != "" {
+		maxFindings, err := strconv.Atoi(v)
+		if err != nil || maxFindings < 0 {
+			return nil, errors.Newf("invalid APP_MAX_FINDINGS_PER_EVENT: %s", v)
+		}
+		cfg.MaxFindingsPerEvent = maxFindings
+	}
+
+	if v := os.Getenv("APP_AUTOCLOSE_NOTIFY_MIN_SEVERITY_ID"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || events.SeverityName(id) == "Unknown" {
+			return nil, errors.Newf("invalid APP_AUTOCLOSE_NOTIFY_MIN_SEVERITY_ID: %s", v)
+		}
+		cfg.CloseNotifyMinSeverityID = id
+	}
+
+	if v := os.Getenv("APP_MAX_CLOSE_AGE_DAYS"); v != "" {
+		maxAge, err := strconv.Atoi(v)
+		if err != nil || maxAge < 0 {
+			return nil, errors.Newf("invalid APP_MAX_CLOSE_AGE_DAYS: %s", v)
+		}
+		cfg.MaxCloseAgeDays = maxAge
+	}
+
+	if v := os.Getenv("APP_NOTIFY_RETRIES"); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 0 {
+			return nil, errors.Newf("invalid APP_NOTIFY_RETRIES: %s", v)
+		}
+		cfg.NotifyRetries = retries
+	}
+
+	if v := os.Getenv("APP_NOTIFY_DEDUP_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl < 0 {
+			return nil, errors.Newf("invalid APP_NOTIFY_DEDUP_TTL: %s", v)
+		}
+		cfg.NotifyDedupTTL = ttl
+	}
+
+	if v := os.Getenv("APP_NOTIFY_DEDUP_SIZE"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return nil, errors.Newf("invalid APP_NOTIFY_DEDUP_SIZE: %s", v)
+		}
+		cfg.NotifyDedupSize = size
+	}
+
+	if v := os.Getenv("APP_SLACK_MAX_BLOCKS"); v != "" {
+		maxBlocks, err := strconv.Atoi(v)
+		if err != nil || maxBlocks < 1 || maxBlocks > events.MaxSlackBlocks {
+			return nil, errors.Newf("invalid APP_SLACK_MAX_BLOCKS: %s (expected 1-%d)", v, events.MaxSlackBlocks)
+		}
+		cfg.SlackMaxBlocks = maxBlocks
+	}
+
+	if v := os.Getenv("APP_RECURRENCE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl < 0 {
+			return nil, errors.Newf("invalid APP_RECURRENCE_TTL: %s", v)
+		}
+		cfg.RecurrenceTTL = ttl
+	}
+
+	if v := os.Getenv("APP_NOTIFY_RETRY_BACKOFF"); v != "" {
+		backoff, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_NOTIFY_RETRY_BACKOFF")
+		}
+		cfg.NotifyRetryBackoff = backoff
+	}
+
+	if v := strings.TrimSpace(cfg.AwsAccessRoleName); strings.HasPrefix(v, "{") {
+		role, roles, err := parseAccessRoleNames(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_AWS_ACCESS_ROLE_NAME")
+		}
+		cfg.AwsAccessRoleName = role
+		cfg.AwsAccessRoleNames = roles
 	}
 
 	if cfg.AwsConsoleURL == "" {
 		cfg.AwsConsoleURL = "https://console.aws.amazon.com"
 	}
 
-	if cfg.AutoCloseRulesS3Prefix == "" {
-		cfg.AutoCloseRulesS3Prefix = "rules/"
+	loc, err := locale.Parse(os.Getenv("APP_LOCALE"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid APP_LOCALE")
+	}
+	cfg.Locale = loc
+
+	if cfg.InformationalComment == "" {
+		cfg.InformationalComment = loc.Text(locale.InformationalComment)
+	}
+
+	if cfg.DecisionLogS3Prefix == "" {
+		cfg.DecisionLogS3Prefix = "decisions/"
+	}
+
+	if cfg.PendingCloseS3Prefix == "" {
+		cfg.PendingCloseS3Prefix = "pending/"
+	}
+
+	if len(cfg.AutoCloseRulesS3Prefixes) == 0 {
+		cfg.AutoCloseRulesS3Prefixes = []string{"rules/"}
+	}
+
+	switch cfg.CommentMode {
+	case "":
+		cfg.CommentMode = actions.CommentModeReplace
+	case actions.CommentModeReplace, actions.CommentModePrefix, actions.CommentModeAppend:
+	default:
+		return nil, errors.Newf("unsupported APP_COMMENT_MODE: %s (expected 'replace', 'prefix' or 'append')", cfg.CommentMode)
+	}
+
+	switch cfg.MaxFindingsMode {
+	case "":
+		cfg.MaxFindingsMode = MaxFindingsTruncate
+	case MaxFindingsTruncate, MaxFindingsFail:
+	default:
+		return nil, errors.Newf("unsupported APP_MAX_FINDINGS_MODE: %s (expected 'truncate' or 'fail')", cfg.MaxFindingsMode)
+	}
+
+	if cfg.TicketURLTemplate != "" && !strings.Contains(cfg.TicketURLTemplate, filters.TicketPlaceholder) {
+		return nil, errors.Newf("invalid APP_TICKET_URL_TEMPLATE: %s has no %s placeholder", cfg.TicketURLTemplate, filters.TicketPlaceholder)
+	}
+
+	if v := os.Getenv("APP_CATEGORY_MAPPINGS"); v != "" {
+		mappings, err := parseCategoryMappings(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_CATEGORY_MAPPINGS")
+		}
+		cfg.CategoryMappings = mappings
+	}
+
+	if v := os.Getenv("APP_SLACK_FIELDS"); v != "" {
+		fields, err := parseSlackFields(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_SLACK_FIELDS")
+		}
+		cfg.SlackFields = fields
+	}
+
+	if v := os.Getenv("APP_SLACK_CATEGORY_FIELDS"); v != "" {
+		layouts, err := parseSlackCategoryFields(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_SLACK_CATEGORY_FIELDS")
+		}
+		cfg.SlackCategoryFields = layouts
+	}
+
+	ranking := cfg.SeverityRanking()
+	seen := make(map[string]bool)
+	for _, severity := range ranking {
+		if seen[strings.ToLower(severity)] {
+			return nil, errors.Newf("invalid APP_SEVERITY_ORDER: %s is listed twice", severity)
+		}
+		seen[strings.ToLower(severity)] = true
+	}
+
+	if cfg.AlertMinSeverity != "" && ranking.Rank(cfg.AlertMinSeverity) < 0 {
+		return nil, errors.Newf("invalid APP_ALERT_MIN_SEVERITY: %s is not in the severity order", cfg.AlertMinSeverity)
+	}
+
+	if v := cfg.FailMinSeverity; v != "" {
+		rank := ranking.Rank(v)
+		if rank < 0 {
+			return nil, errors.Newf("invalid APP_FAIL_MIN_SEVERITY: %s is not in the severity order", v)
+		}
+		// use the ranking's spelling so routes and the default alert set match
+		cfg.FailMinSeverity = ranking[rank]
+	}
+
+	if v := os.Getenv("APP_SLACK_CHANNEL_ROUTES"); v != "" {
+		routes, err := parseChannelRoutes(v, ranking)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_SLACK_CHANNEL_ROUTES")
+		}
+		cfg.SlackChannelRoutes = routes
+	}
+
+	if v := os.Getenv("APP_STATUS_MAPPINGS"); v != "" {
+		mappings, err := parseStatusMappings(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_STATUS_MAPPINGS")
+		}
+		cfg.StatusMappings = mappings
+	}
+
+	if v := os.Getenv("APP_STATUS_NAMES"); v != "" {
+		names, err := parseStatusNames(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_STATUS_NAMES")
+		}
+		cfg.StatusNames = names
+	}
+
+	if v := os.Getenv("APP_LOG_FIELDS"); v != "" {
+		fields, err := parseLogFields(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_LOG_FIELDS")
+		}
+		cfg.LogFields = fields
+	}
+
+	if v := os.Getenv("APP_FIELD_MAPPINGS"); v != "" {
+		mappings, err := parseFieldMappings(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse APP_FIELD_MAPPINGS")
+		}
+		cfg.FieldMappings = mappings
 	}
 
 	rulesJSON := os.Getenv("APP_AUTO_CLOSE_RULES")
@@ -64,9 +442,255 @@ func NewConfig() (*Config, error) {
 
 	cfg.SlackEnabled = cfg.SlackToken != "" && cfg.SlackChannel != ""
 
+	cfg.Notifiers = parseList(cfg.Notifier)
+	if len(cfg.Notifiers) == 0 && cfg.SlackEnabled {
+		cfg.Notifiers = []string{"slack"}
+	}
+	for i, notifier := range cfg.Notifiers {
+		switch notifier {
+		case "slack":
+			if !cfg.SlackEnabled {
+				return nil, errors.New("APP_NOTIFIER=slack requires APP_SLACK_TOKEN and APP_SLACK_CHANNEL")
+			}
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, errors.New("APP_NOTIFIER=webhook requires APP_WEBHOOK_URL")
+			}
+		case "chatbot":
+			if cfg.ChatbotSNSTopicARN == "" {
+				return nil, errors.New("APP_NOTIFIER=chatbot requires APP_CHATBOT_SNS_TOPIC_ARN")
+			}
+		case "render", "memory":
+		default:
+			return nil, errors.Newf("unsupported APP_NOTIFIER: %s (expected 'slack', 'webhook', 'chatbot', 'render' or 'memory')", notifier)
+		}
+		if slices.Contains(cfg.Notifiers[:i], notifier) {
+			return nil, errors.Newf("invalid APP_NOTIFIER: %s is listed twice", notifier)
+		}
+	}
+	cfg.Notifier = strings.Join(cfg.Notifiers, ",")
+
 	return &cfg, nil
 }
 
+// parseList splits a comma-separated value, trimming whitespace and dropping
+// empty entries.
+func parseList(input string) []string {
+	var items []string
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLogFields parses comma-separated key=value pairs, such as
+// "environment=prod,region=us-east-1".
+func parseLogFields(input string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for _, item := range parseList(input) {
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.Newf("invalid field %q - expected key=value", item)
+		}
+		if _, dup := fields[key]; dup {
+			return nil, errors.Newf("field %q is set twice", key)
+		}
+		fields[key] = strings.TrimSpace(value)
+	}
+	return fields, nil
+}
+
+// LogAttrs returns APP_LOG_FIELDS as slog key-value pairs in key order.
+func (c *Config) LogAttrs() []any {
+	keys := slices.Sorted(maps.Keys(c.LogFields))
+	attrs := make([]any, 0, 2*len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, key, c.LogFields[key])
+	}
+	return attrs
+}
+
+// parseCategoryMappings parses an ordered JSON array of {substring, category}
+// pairs. earlier entries take precedence.
+func parseCategoryMappings(input string) ([]events.CategoryMapping, error) {
+	var mappings []events.CategoryMapping
+	if err := json.Unmarshal([]byte(input), &mappings); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected array")
+	}
+
+	for i, m := range mappings {
+		if m.Substring == "" || m.Category == "" {
+			return nil, errors.Newf("mapping %d requires both substring and category", i)
+		}
+	}
+
+	return mappings, nil
+}
+
+// parseAccessRoleNames parses a JSON object mapping account ids to access
+// portal roles. the optional "default" key is returned as the default role.
+func parseAccessRoleNames(input string) (string, map[string]string, error) {
+	var roles map[string]string
+	if err := json.Unmarshal([]byte(input), &roles); err != nil {
+		return "", nil, errors.Wrap(err, "invalid JSON format - expected object of account ids to roles")
+	}
+
+	for account, role := range roles {
+		if role == "" {
+			return "", nil, errors.Newf("account %q has an empty role", account)
+		}
+	}
+
+	role := roles["default"]
+	delete(roles, "default")
+	return role, roles, nil
+}
+
+// parseSlackFields parses an ordered, comma-separated list of Slack message
+// field keys.
+func parseSlackFields(input string) ([]string, error) {
+	fields := parseList(input)
+	if err := checkSlackFields(fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// parseSlackCategoryFields parses a JSON object mapping finding categories to
+// ordered lists of Slack message field keys.
+func parseSlackCategoryFields(input string) (map[string][]string, error) {
+	var layouts map[string][]string
+	if err := json.Unmarshal([]byte(input), &layouts); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected object of categories to field arrays")
+	}
+
+	for category, fields := range layouts {
+		if len(fields) == 0 {
+			return nil, errors.Newf("category %q has no fields", category)
+		}
+		if err := checkSlackFields(fields); err != nil {
+			return nil, errors.Wrapf(err, "category %q", category)
+		}
+	}
+
+	return layouts, nil
+}
+
+func checkSlackFields(fields []string) error {
+	for _, field := range fields {
+		if !slices.Contains(events.DefaultSlackFields, field) {
+			return errors.Newf("unknown field %q (expected one of %s)", field, strings.Join(events.DefaultSlackFields, ", "))
+		}
+	}
+	return nil
+}
+
+// SeverityRanking returns the configured severity order, or the OCSF scale
+// when none is set.
+func (c *Config) SeverityRanking() events.SeverityRanking {
+	if len(c.SeverityOrder) == 0 {
+		return events.DefaultSeverityRanking
+	}
+	return events.SeverityRanking(c.SeverityOrder)
+}
+
+// parseChannelRoutes parses an ordered JSON array of {severities,
+// min_severity, regions, channel} routes. each route needs a channel and at
+// least one condition, and min_severity is expanded to the severities ranked
+// at or above it.
+func parseChannelRoutes(input string, ranking events.SeverityRanking) ([]notifiers.ChannelRoute, error) {
+	var routes []notifiers.ChannelRoute
+	if err := json.Unmarshal([]byte(input), &routes); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected array")
+	}
+
+	for i, r := range routes {
+		if r.Channel == "" {
+			return nil, errors.Newf("route %d requires a channel", i)
+		}
+		if r.MinSeverity != "" {
+			if len(r.Severities) > 0 {
+				return nil, errors.Newf("route %d sets both severities and min_severity", i)
+			}
+			routes[i].Severities = ranking.From(r.MinSeverity)
+			if routes[i].Severities == nil {
+				return nil, errors.Newf("route %d has unranked min_severity %q", i, r.MinSeverity)
+			}
+		}
+		if len(routes[i].Severities) == 0 && len(r.Regions) == 0 {
+			return nil, errors.Newf("route %d requires severities or regions", i)
+		}
+	}
+
+	return routes, nil
+}
+
+// parseStatusMappings parses a JSON object mapping product status strings to
+// OCSF status ids.
+func parseStatusMappings(input string) (map[string]int, error) {
+	var mappings map[string]int
+	if err := json.Unmarshal([]byte(input), &mappings); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected object of status ids")
+	}
+
+	for status, statusID := range mappings {
+		if events.StatusName(statusID) == "Unknown" {
+			return nil, errors.Newf("status %q maps to unsupported status id %d", status, statusID)
+		}
+	}
+
+	return mappings, nil
+}
+
+// parseStatusNames parses a JSON object mapping OCSF status ids to the names
+// shown for them.
+func parseStatusNames(input string) (events.StatusNames, error) {
+	var names events.StatusNames
+	if err := json.Unmarshal([]byte(input), &names); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected object of status ids to names")
+	}
+
+	for statusID, name := range names {
+		if events.StatusName(statusID) == "Unknown" {
+			return nil, errors.Newf("unsupported status id %d", statusID)
+		}
+		if name == "" {
+			return nil, errors.Newf("status id %d has an empty name", statusID)
+		}
+	}
+
+	return names, nil
+}
+
+// parseFieldMappings parses a JSON array of per-product field mappings. each
+// mapping needs a product and something to map, and severities must map to
+// canonical OCSF severity names.
+func parseFieldMappings(input string) ([]events.FieldMapping, error) {
+	var mappings []events.FieldMapping
+	if err := json.Unmarshal([]byte(input), &mappings); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON format - expected array")
+	}
+
+	for i, m := range mappings {
+		if m.Product == "" {
+			return nil, errors.Newf("mapping %d requires a product", i)
+		}
+		if m.TitlePath == "" && m.SeverityPath == "" && len(m.Severities) == 0 {
+			return nil, errors.Newf("mapping %d for %q maps no fields", i, m.Product)
+		}
+		for value, severity := range m.Severities {
+			if events.SeverityID(severity) == 0 {
+				return nil, errors.Newf("mapping %d for %q maps %q to unknown severity %q", i, m.Product, value, severity)
+			}
+		}
+	}
+
+	return mappings, nil
+}
+
 // parseAutoCloseRules parses auto-close rules from either JSON or JSON-encoded string format.
 // supports both direct JSON arrays and JSON strings that need unescaping.
 func parseAutoCloseRules(input string) ([]filters.AutoCloseRule, error) {
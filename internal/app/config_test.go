@@ -5,11 +5,35 @@
 // - Empty and invalid rule arrays
 // - Multiple rules with different filter combinations
 // - Both single-encoded and double-encoded JSON (env var format)
+// - Notifier selection and validation
+// - Comma-separated list parsing
+// - Log field key=value parsing and key ordering
+// - HTTP timeout and proxy parsing
+// - Category mapping parsing and validation
+// - Status mapping parsing and validation
+// - Status name override parsing and validation
+// - Locale parsing and the localized informational comment default
+// - Slack field selection parsing and validation
+// - Per-category Slack field layout parsing and validation
+// - Slack block cap bounds validation
+// - S3 rule prefix list parsing
+// - Rules URL scheme validation and timeout default
+// - Slack channel route parsing and validation
+// - Access portal role as a name or per-account JSON map
+// - Max close age parsing and validation
+// - Max findings per event and overflow mode validation
+// - Auto-close notification minimum severity id validation
+// - Severity order, alert and failed compliance minimums and min_severity route expansion
+// - Notification dedup window parsing and validation
+// - Field mapping parsing and validation
+// - Ticket url template placeholder validation
 package app
 
 import (
 	"encoding/json"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/filters"
 )
@@ -225,3 +249,594 @@ func TestParseAutoCloseRules_MultipleRules(t *testing.T) {
 		t.Error("expected rule-2 to be disabled")
 	}
 }
+
+// TestNewConfig_Notifier validates notifier selection from APP_NOTIFIER and
+// the Slack settings.
+func TestNewConfig_Notifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		notifier string
+		token    string
+		channel  string
+		expected string
+		wantErr  bool
+	}{
+		{"default without slack", "", "", "", "", false},
+		{"default with slack", "", "xoxb-test", "C01234TEST", "slack", false},
+		{"memory", "memory", "", "", "memory", false},
+		{"memory overrides slack", "memory", "xoxb-test", "C01234TEST", "memory", false},
+		{"slack without token", "slack", "", "", "", true},
+		{"webhook without url", "webhook", "", "", "", true},
+		{"chatbot without topic", "chatbot", "", "", "", true},
+		{"render without slack", "render", "", "", "render", false},
+		{"unknown", "email", "", "", "", true},
+		{"several", "memory, render", "", "", "memory,render", false},
+		{"duplicate", "memory,memory", "", "", "", true},
+		{"several with invalid", "memory,slack", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_NOTIFIER", tt.notifier)
+			t.Setenv("APP_SLACK_TOKEN", tt.token)
+			t.Setenv("APP_SLACK_CHANNEL", tt.channel)
+
+			cfg, err := NewConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Notifier != tt.expected {
+				t.Errorf("expected notifier %q, got %q", tt.expected, cfg.Notifier)
+			}
+		})
+	}
+
+	t.Setenv("APP_NOTIFIER", "webhook")
+	t.Setenv("APP_WEBHOOK_URL", "https://hooks.example.com/securityhub")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Notifier != "webhook" || cfg.WebhookURL != "https://hooks.example.com/securityhub" {
+		t.Errorf("unexpected webhook config: %q, %q", cfg.Notifier, cfg.WebhookURL)
+	}
+
+	t.Setenv("APP_NOTIFIER", "chatbot")
+	t.Setenv("APP_CHATBOT_SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:chatbot")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Notifier != "chatbot" || cfg.ChatbotSNSTopicARN != "arn:aws:sns:us-east-1:123456789012:chatbot" {
+		t.Errorf("unexpected chatbot config: %q, %q", cfg.Notifier, cfg.ChatbotSNSTopicARN)
+	}
+}
+
+// TestParseList validates comma-separated parsing with whitespace and empty
+// entries.
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"single", "123456789012", []string{"123456789012"}},
+		{"multiple with spaces", " 123456789012 , 210987654321", []string{"123456789012", "210987654321"}},
+		{"trailing comma", "123456789012,", []string{"123456789012"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseList(tt.input)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestNewConfig_LogFields validates that base log fields are parsed from
+// key=value pairs and returned in key order.
+func TestNewConfig_LogFields(t *testing.T) {
+	t.Setenv("APP_LOG_FIELDS", "region=us-east-1, environment=prod,team=")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []any{"environment", "prod", "region", "us-east-1", "team", ""}
+	if attrs := cfg.LogAttrs(); !slices.Equal(attrs, expected) {
+		t.Errorf("expected %v, got %v", expected, attrs)
+	}
+
+	for _, v := range []string{"environment", "=prod", "env=a,env=b"} {
+		t.Setenv("APP_LOG_FIELDS", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+// TestNewConfig_HTTPSettings validates parsing of the outbound HTTP timeout
+// and proxy settings.
+func TestNewConfig_HTTPSettings(t *testing.T) {
+	t.Setenv("APP_HTTP_TIMEOUT", "15s")
+	t.Setenv("APP_HTTP_PROXY", "http://proxy.internal:3128")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.HTTPTimeout != 15*time.Second {
+		t.Errorf("expected 15s timeout, got %s", cfg.HTTPTimeout)
+	}
+
+	if cfg.HTTPProxy == nil || cfg.HTTPProxy.Host != "proxy.internal:3128" {
+		t.Errorf("unexpected proxy: %v", cfg.HTTPProxy)
+	}
+
+	t.Setenv("APP_HTTP_TIMEOUT", "fast")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+
+	t.Setenv("APP_HTTP_TIMEOUT", "")
+	t.Setenv("APP_HTTP_PROXY", "not a url")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid proxy")
+	}
+}
+
+// TestNewConfig_CategoryMappings validates that category mappings keep their
+// configured order and that incomplete entries are rejected.
+func TestNewConfig_CategoryMappings(t *testing.T) {
+	t.Setenv("APP_CATEGORY_MAPPINGS", `[{"substring": "Exposure", "category": "Exposure"}, {"substring": "Threats", "category": "Threats"}]`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.CategoryMappings) != 2 || cfg.CategoryMappings[0].Substring != "Exposure" {
+		t.Errorf("unexpected mappings: %+v", cfg.CategoryMappings)
+	}
+
+	t.Setenv("APP_CATEGORY_MAPPINGS", `[{"substring": "Exposure"}]`)
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for mapping without category")
+	}
+
+	t.Setenv("APP_CATEGORY_MAPPINGS", `{"substring": "Exposure"}`)
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for non-array mappings")
+	}
+}
+
+// TestNewConfig_FieldMappings validates parsing of per-product field mappings
+// and rejection of incomplete ones.
+func TestNewConfig_FieldMappings(t *testing.T) {
+	t.Setenv("APP_FIELD_MAPPINGS", `[{"product": "Acme Scanner", "title_path": "unmapped.headline", "severities": {"sev1": "critical"}}]`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.FieldMappings) != 1 || cfg.FieldMappings[0].Product != "Acme Scanner" || cfg.FieldMappings[0].TitlePath != "unmapped.headline" {
+		t.Errorf("unexpected mappings: %+v", cfg.FieldMappings)
+	}
+
+	for name, input := range map[string]string{
+		"missing product":  `[{"title_path": "unmapped.headline"}]`,
+		"nothing mapped":   `[{"product": "Acme Scanner"}]`,
+		"unknown severity": `[{"product": "Acme Scanner", "severities": {"sev1": "Urgent"}}]`,
+		"not an array":     `{"product": "Acme Scanner"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("APP_FIELD_MAPPINGS", input)
+			if _, err := NewConfig(); err == nil {
+				t.Errorf("expected error for %s", input)
+			}
+		})
+	}
+}
+
+// TestNewConfig_StatusMappings validates parsing of product status mappings
+// and rejection of unsupported status ids.
+func TestNewConfig_StatusMappings(t *testing.T) {
+	t.Setenv("APP_STATUS_MAPPINGS", `{"Open": 1, "Dismissed": 3}`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.StatusMappings["Open"] != 1 || cfg.StatusMappings["Dismissed"] != 3 {
+		t.Errorf("unexpected mappings: %v", cfg.StatusMappings)
+	}
+
+	t.Setenv("APP_STATUS_MAPPINGS", `{"Open": 42}`)
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unsupported status id")
+	}
+}
+
+// TestNewConfig_StatusNames validates that status name overrides apply to
+// their ids only and reject unsupported ids and empty names.
+func TestNewConfig_StatusNames(t *testing.T) {
+	t.Setenv("APP_STATUS_NAMES", `{"3": "Accepted Risk"}`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.StatusNames.Name(3) != "Accepted Risk" || cfg.StatusNames.Name(4) != "Resolved" {
+		t.Errorf("unexpected names: %v", cfg.StatusNames)
+	}
+
+	for _, v := range []string{`{"42": "Gone"}`, `{"3": ""}`, `{"suppressed": "Accepted"}`} {
+		t.Setenv("APP_STATUS_NAMES", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", v)
+		}
+	}
+}
+
+// TestNewConfig_Locale validates that APP_LOCALE localizes the default
+// informational comment, leaves a configured one verbatim and rejects
+// unsupported locales.
+func TestNewConfig_Locale(t *testing.T) {
+	t.Setenv("APP_LOCALE", "es-MX")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Locale.Lang() != "es" || cfg.InformationalComment != "Cerrado automáticamente: hallazgo informativo" {
+		t.Errorf("unexpected locale %s with comment %q", cfg.Locale.Lang(), cfg.InformationalComment)
+	}
+
+	t.Setenv("APP_INFORMATIONAL_COMMENT", "Archived")
+	if cfg, err := NewConfig(); err != nil || cfg.InformationalComment != "Archived" {
+		t.Errorf("expected the configured comment verbatim, got %v", err)
+	}
+
+	t.Setenv("APP_LOCALE", "klingon")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for an unsupported locale")
+	}
+}
+
+// TestNewConfig_SlackFields validates that the Slack field list keeps its
+// order and rejects unknown keys.
+func TestNewConfig_SlackFields(t *testing.T) {
+	t.Setenv("APP_SLACK_FIELDS", "severity, account")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(cfg.SlackFields, []string{"severity", "account"}) {
+		t.Errorf("unexpected fields: %v", cfg.SlackFields)
+	}
+
+	t.Setenv("APP_SLACK_FIELDS", "severity,tags")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+// TestNewConfig_SlackCategoryFields validates that category layouts keep
+// their field order and reject unknown or empty field lists.
+func TestNewConfig_SlackCategoryFields(t *testing.T) {
+	t.Setenv("APP_SLACK_CATEGORY_FIELDS", `{"Posture Management": ["compliance", "severity"]}`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.SlackCategoryFields["Posture Management"], []string{"compliance", "severity"}) {
+		t.Errorf("unexpected layouts: %v", cfg.SlackCategoryFields)
+	}
+
+	for _, v := range []string{`{"Threats": ["tags"]}`, `{"Threats": []}`, `["severity"]`} {
+		t.Setenv("APP_SLACK_CATEGORY_FIELDS", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", v)
+		}
+	}
+}
+
+// TestNewConfig_SlackMaxBlocks validates that the block cap is parsed and
+// kept within Slack's limit.
+func TestNewConfig_SlackMaxBlocks(t *testing.T) {
+	t.Setenv("APP_SLACK_MAX_BLOCKS", "20")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SlackMaxBlocks != 20 {
+		t.Errorf("expected 20, got %d", cfg.SlackMaxBlocks)
+	}
+
+	for _, v := range []string{"0", "51", "many"} {
+		t.Setenv("APP_SLACK_MAX_BLOCKS", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+// TestNewConfig_RulesURL validates the rules url scheme and the default
+// request timeout.
+func TestNewConfig_RulesURL(t *testing.T) {
+	t.Setenv("APP_AUTO_CLOSE_RULES_URL", "https://config.internal/rules.yaml")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AutoCloseRulesURLTimeout != 10*time.Second {
+		t.Errorf("expected a 10s default timeout, got %s", cfg.AutoCloseRulesURLTimeout)
+	}
+
+	t.Setenv("APP_AUTO_CLOSE_RULES_URL_TIMEOUT", "soon")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+
+	t.Setenv("APP_AUTO_CLOSE_RULES_URL_TIMEOUT", "")
+	t.Setenv("APP_AUTO_CLOSE_RULES_URL", "s3://bucket/rules.json")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for non-http url")
+	}
+}
+
+// TestNewConfig_S3Prefixes validates the default prefix and parsing of a
+// comma-separated prefix list.
+func TestNewConfig_S3Prefixes(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(cfg.AutoCloseRulesS3Prefixes, []string{"rules/"}) {
+		t.Errorf("expected default prefix, got %v", cfg.AutoCloseRulesS3Prefixes)
+	}
+
+	t.Setenv("APP_AUTO_CLOSE_RULES_S3_PREFIX", "rules/global/, rules/prod/")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(cfg.AutoCloseRulesS3Prefixes, []string{"rules/global/", "rules/prod/"}) {
+		t.Errorf("unexpected prefixes: %v", cfg.AutoCloseRulesS3Prefixes)
+	}
+}
+
+// TestNewConfig_SlackChannelRoutes validates route parsing and rejection of
+// routes without a channel or condition.
+func TestNewConfig_SlackChannelRoutes(t *testing.T) {
+	t.Setenv("APP_SLACK_CHANNEL_ROUTES", `[{"regions":["eu-west-1"],"channel":"C-EU"},{"severities":["Critical"],"regions":["eu-west-1"],"channel":"C-EU-CRITICAL"}]`)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.SlackChannelRoutes) != 2 || cfg.SlackChannelRoutes[1].Channel != "C-EU-CRITICAL" {
+		t.Errorf("unexpected routes: %+v", cfg.SlackChannelRoutes)
+	}
+
+	for _, input := range []string{
+		`[{"regions":["eu-west-1"]}]`,
+		`[{"channel":"C-ALL"}]`,
+		`{"channel":"C-EU"}`,
+	} {
+		t.Setenv("APP_SLACK_CHANNEL_ROUTES", input)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+// TestNewConfig_AccessRoleNames validates that APP_AWS_ACCESS_ROLE_NAME takes
+// either a single role or a JSON map of account ids with a default.
+func TestNewConfig_AccessRoleNames(t *testing.T) {
+	t.Setenv("APP_AWS_ACCESS_ROLE_NAME", "SecurityAuditor")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AwsAccessRoleName != "SecurityAuditor" || cfg.AwsAccessRoleNames != nil {
+		t.Errorf("unexpected roles: %s, %v", cfg.AwsAccessRoleName, cfg.AwsAccessRoleNames)
+	}
+
+	t.Setenv("APP_AWS_ACCESS_ROLE_NAME", `{"default": "SecurityAuditor", "123456789012": "ProdSecurityAuditor"}`)
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AwsAccessRoleName != "SecurityAuditor" || len(cfg.AwsAccessRoleNames) != 1 || cfg.AwsAccessRoleNames["123456789012"] != "ProdSecurityAuditor" {
+		t.Errorf("unexpected roles: %s, %v", cfg.AwsAccessRoleName, cfg.AwsAccessRoleNames)
+	}
+
+	for _, input := range []string{`{"123456789012": ""}`, `{"123456789012": 1}`} {
+		t.Setenv("APP_AWS_ACCESS_ROLE_NAME", input)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+// TestNewConfig_MaxCloseAgeDays validates that the max close age is parsed
+// as whole days and rejects invalid values.
+func TestNewConfig_MaxCloseAgeDays(t *testing.T) {
+	t.Setenv("APP_MAX_CLOSE_AGE_DAYS", "90")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxCloseAgeDays != 90 {
+		t.Errorf("expected 90 days, got %d", cfg.MaxCloseAgeDays)
+	}
+
+	for _, v := range []string{"-1", "90d", "1.5"} {
+		t.Setenv("APP_MAX_CLOSE_AGE_DAYS", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+// TestNewConfig_MaxFindingsPerEvent validates the findings cap and that the
+// overflow mode defaults to truncate.
+func TestNewConfig_MaxFindingsPerEvent(t *testing.T) {
+	t.Setenv("APP_MAX_FINDINGS_PER_EVENT", "500")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxFindingsPerEvent != 500 || cfg.MaxFindingsMode != MaxFindingsTruncate {
+		t.Errorf("expected 500 findings in truncate mode, got %d in %q", cfg.MaxFindingsPerEvent, cfg.MaxFindingsMode)
+	}
+
+	t.Setenv("APP_MAX_FINDINGS_MODE", "drop")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+
+	t.Setenv("APP_MAX_FINDINGS_MODE", "")
+	t.Setenv("APP_MAX_FINDINGS_PER_EVENT", "-1")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for negative cap")
+	}
+}
+
+// TestNewConfig_CloseNotifyMinSeverityID validates that the auto-close
+// notification floor must be an OCSF severity id.
+func TestNewConfig_CloseNotifyMinSeverityID(t *testing.T) {
+	t.Setenv("APP_AUTOCLOSE_NOTIFY_MIN_SEVERITY_ID", "4")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CloseNotifyMinSeverityID != 4 {
+		t.Errorf("expected 4, got %d", cfg.CloseNotifyMinSeverityID)
+	}
+
+	for _, v := range []string{"0", "7", "High", "-1"} {
+		t.Setenv("APP_AUTOCLOSE_NOTIFY_MIN_SEVERITY_ID", v)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+// TestNewConfig_SeverityOrder validates the custom severity order, the alert
+// minimum and route min_severity expansion against it.
+func TestNewConfig_SeverityOrder(t *testing.T) {
+	t.Setenv("APP_SEVERITY_ORDER", "Unknown,Low,Informational,Medium,High,Critical,Fatal")
+	t.Setenv("APP_ALERT_MIN_SEVERITY", "Informational")
+	t.Setenv("APP_SLACK_CHANNEL_ROUTES", `[{"min_severity":"informational","channel":"C-NOISY"}]`)
+	t.Setenv("APP_FAIL_MIN_SEVERITY", "medium")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.SeverityRanking().Rank("Informational") != 2 {
+		t.Errorf("expected custom order, got %v", cfg.SeverityRanking())
+	}
+
+	if cfg.FailMinSeverity != "Medium" {
+		t.Errorf("expected the failed compliance floor in the order's spelling, got %s", cfg.FailMinSeverity)
+	}
+
+	expected := []string{"Informational", "Medium", "High", "Critical", "Fatal"}
+	if !slices.Equal(cfg.SlackChannelRoutes[0].Severities, expected) {
+		t.Errorf("expected min_severity expanded to %v, got %v", expected, cfg.SlackChannelRoutes[0].Severities)
+	}
+
+	for _, env := range []struct{ key, value string }{
+		{"APP_ALERT_MIN_SEVERITY", "Severe"},
+		{"APP_FAIL_MIN_SEVERITY", "Severe"},
+		{"APP_SEVERITY_ORDER", "Low,Medium,low"},
+		{"APP_SLACK_CHANNEL_ROUTES", `[{"min_severity":"Severe","channel":"C-X"}]`},
+		{"APP_SLACK_CHANNEL_ROUTES", `[{"min_severity":"High","severities":["Low"],"channel":"C-X"}]`},
+	} {
+		t.Run(env.key+"="+env.value, func(t *testing.T) {
+			t.Setenv(env.key, env.value)
+			if _, err := NewConfig(); err == nil {
+				t.Errorf("expected error for %s=%s", env.key, env.value)
+			}
+		})
+	}
+}
+
+// TestNewConfig_NotifyDedup validates the dedup ttl and size, which default
+// to disabled and 1000 entries.
+func TestNewConfig_NotifyDedup(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.NotifyDedupTTL != 0 || cfg.NotifyDedupSize != 1000 {
+		t.Errorf("unexpected defaults: ttl=%s size=%d", cfg.NotifyDedupTTL, cfg.NotifyDedupSize)
+	}
+
+	t.Setenv("APP_NOTIFY_DEDUP_TTL", "15m")
+	t.Setenv("APP_NOTIFY_DEDUP_SIZE", "50")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.NotifyDedupTTL != 15*time.Minute || cfg.NotifyDedupSize != 50 {
+		t.Errorf("unexpected dedup config: ttl=%s size=%d", cfg.NotifyDedupTTL, cfg.NotifyDedupSize)
+	}
+
+	for _, env := range []struct{ key, value string }{
+		{"APP_NOTIFY_DEDUP_TTL", "15"},
+		{"APP_NOTIFY_DEDUP_TTL", "-1m"},
+		{"APP_NOTIFY_DEDUP_SIZE", "0"},
+	} {
+		t.Run(env.key+"="+env.value, func(t *testing.T) {
+			t.Setenv(env.key, env.value)
+			if _, err := NewConfig(); err == nil {
+				t.Errorf("expected error for %s=%s", env.key, env.value)
+			}
+		})
+	}
+}
+
+// TestNewConfig_TicketURLTemplate validates that the ticket url template must
+// contain the ticket placeholder.
+func TestNewConfig_TicketURLTemplate(t *testing.T) {
+	t.Setenv("APP_TICKET_URL_TEMPLATE", "https://jira.example.com/browse/{ticket}")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TicketURLTemplate != "https://jira.example.com/browse/{ticket}" {
+		t.Errorf("unexpected template: %q", cfg.TicketURLTemplate)
+	}
+
+	t.Setenv("APP_TICKET_URL_TEMPLATE", "https://jira.example.com/browse/")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for a template without the placeholder")
+	}
+}
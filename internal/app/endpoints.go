@@ -0,0 +1,41 @@
+package app
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// securityHubOptions overrides the Security Hub endpoint when configured,
+// otherwise the SDK default resolution applies.
+func securityHubOptions(cfg *Config) []func(*securityhub.Options) {
+	if cfg.AWSEndpointSecurityHub == "" {
+		return nil
+	}
+	return []func(*securityhub.Options){func(o *securityhub.Options) {
+		o.BaseEndpoint = aws.String(cfg.AWSEndpointSecurityHub)
+	}}
+}
+
+// s3Options overrides the S3 endpoint when configured, otherwise the SDK
+// default resolution applies.
+func s3Options(cfg *Config) []func(*s3.Options) {
+	if cfg.AWSEndpointS3 == "" {
+		return nil
+	}
+	return []func(*s3.Options){func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(cfg.AWSEndpointS3)
+	}}
+}
+
+// snsOptions overrides the SNS endpoint when configured, otherwise the SDK
+// default resolution applies.
+func snsOptions(cfg *Config) []func(*sns.Options) {
+	if cfg.AWSEndpointSNS == "" {
+		return nil
+	}
+	return []func(*sns.Options){func(o *sns.Options) {
+		o.BaseEndpoint = aws.String(cfg.AWSEndpointSNS)
+	}}
+}
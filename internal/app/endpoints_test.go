@@ -0,0 +1,81 @@
+// Package app tests AWS service endpoint overrides.
+//
+// Tests cover:
+// - Security Hub, S3 and SNS clients use configured endpoints
+// - SDK default endpoint resolution when unset
+// - Endpoint validation in config
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// TestServiceEndpoints validates that configured endpoints are applied to the
+// constructed clients and that unset endpoints keep the SDK defaults.
+func TestServiceEndpoints(t *testing.T) {
+	awsCfg := aws.Config{Region: "us-east-1"}
+
+	cfg := &Config{
+		AWSEndpointSecurityHub: "https://securityhub-fips.us-east-1.amazonaws.com",
+		AWSEndpointS3:          "https://s3-fips.us-east-1.amazonaws.com",
+		AWSEndpointSNS:         "https://sns-fips.us-east-1.amazonaws.com",
+	}
+
+	shClient := securityhub.NewFromConfig(awsCfg, securityHubOptions(cfg)...)
+	if got := aws.ToString(shClient.Options().BaseEndpoint); got != cfg.AWSEndpointSecurityHub {
+		t.Errorf("expected securityhub endpoint %s, got %s", cfg.AWSEndpointSecurityHub, got)
+	}
+
+	s3Client := s3.NewFromConfig(awsCfg, s3Options(cfg)...)
+	if got := aws.ToString(s3Client.Options().BaseEndpoint); got != cfg.AWSEndpointS3 {
+		t.Errorf("expected s3 endpoint %s, got %s", cfg.AWSEndpointS3, got)
+	}
+
+	snsClient := sns.NewFromConfig(awsCfg, snsOptions(cfg)...)
+	if got := aws.ToString(snsClient.Options().BaseEndpoint); got != cfg.AWSEndpointSNS {
+		t.Errorf("expected sns endpoint %s, got %s", cfg.AWSEndpointSNS, got)
+	}
+
+	empty := &Config{}
+	if opts := securityHubOptions(empty); opts != nil {
+		t.Errorf("expected no securityhub options, got %d", len(opts))
+	}
+
+	if opts := s3Options(empty); opts != nil {
+		t.Errorf("expected no s3 options, got %d", len(opts))
+	}
+
+	if opts := snsOptions(empty); opts != nil {
+		t.Errorf("expected no sns options, got %d", len(opts))
+	}
+
+	if endpoint := securityhub.NewFromConfig(awsCfg, securityHubOptions(empty)...).Options().BaseEndpoint; endpoint != nil {
+		t.Errorf("expected default securityhub endpoint, got %s", *endpoint)
+	}
+}
+
+// TestNewConfig_Endpoints validates parsing and validation of the endpoint
+// overrides.
+func TestNewConfig_Endpoints(t *testing.T) {
+	t.Setenv("APP_AWS_ENDPOINT_SECURITYHUB", "https://securityhub-fips.us-east-1.amazonaws.com")
+	t.Setenv("APP_AWS_ENDPOINT_S3", "http://localhost:4566")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.AWSEndpointSecurityHub != "https://securityhub-fips.us-east-1.amazonaws.com" || cfg.AWSEndpointS3 != "http://localhost:4566" {
+		t.Errorf("unexpected endpoints: %s, %s", cfg.AWSEndpointSecurityHub, cfg.AWSEndpointS3)
+	}
+
+	t.Setenv("APP_AWS_ENDPOINT_S3", "localhost")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for endpoint without scheme")
+	}
+}
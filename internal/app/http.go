@@ -0,0 +1,57 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// proxyFunc returns the configured proxy, falling back to the standard
+// HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables.
+func proxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxyURL != nil {
+		return http.ProxyURL(proxyURL)
+	}
+	return http.ProxyFromEnvironment
+}
+
+// NewHTTPClient returns the client for non-AWS outbound calls, or nil to use
+// the library defaults when no timeout or proxy is configured.
+func NewHTTPClient(cfg *Config) *http.Client {
+	if cfg.HTTPTimeout == 0 && cfg.HTTPProxy == nil {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc(cfg.HTTPProxy)
+
+	return &http.Client{
+		Timeout:   cfg.HTTPTimeout,
+		Transport: transport,
+	}
+}
+
+// NewAWSHTTPClient returns the AWS SDK client with the configured timeout and
+// proxy. the buildable client keeps support for AWS_CA_BUNDLE.
+func NewAWSHTTPClient(cfg *Config) *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().
+		WithTimeout(cfg.HTTPTimeout).
+		WithTransportOptions(func(tr *http.Transport) {
+			tr.Proxy = proxyFunc(cfg.HTTPProxy)
+		})
+}
+
+func awsConfigOptions(httpClient *http.Client, cfg *Config) []func(*config.LoadOptions) error {
+	// a client from context (for testing) takes precedence
+	if httpClient != nil {
+		return []func(*config.LoadOptions) error{config.WithHTTPClient(httpClient)}
+	}
+
+	if cfg.HTTPTimeout == 0 && cfg.HTTPProxy == nil {
+		return nil
+	}
+
+	return []func(*config.LoadOptions) error{config.WithHTTPClient(NewAWSHTTPClient(cfg))}
+}
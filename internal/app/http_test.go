@@ -0,0 +1,113 @@
+// Package app tests outbound HTTP client construction.
+//
+// Tests cover:
+// - Default clients when no timeout or proxy is configured
+// - Timeout and proxy propagation to the generic and AWS clients
+// - Context-injected test client precedence
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+func applyLoadOptions(t *testing.T, opts []func(*config.LoadOptions) error) config.LoadOptions {
+	t.Helper()
+
+	var lo config.LoadOptions
+	for _, opt := range opts {
+		if err := opt(&lo); err != nil {
+			t.Fatalf("unexpected error applying load option: %v", err)
+		}
+	}
+	return lo
+}
+
+// TestNewHTTPClient_Default validates that no client is built without config,
+// leaving library defaults in place.
+func TestNewHTTPClient_Default(t *testing.T) {
+	if client := NewHTTPClient(&Config{}); client != nil {
+		t.Error("expected nil client without timeout or proxy")
+	}
+
+	if opts := awsConfigOptions(nil, &Config{}); len(opts) != 0 {
+		t.Errorf("expected no aws load options, got %d", len(opts))
+	}
+}
+
+// TestNewHTTPClient_TimeoutAndProxy validates that the configured timeout and
+// proxy are applied to the generic client.
+func TestNewHTTPClient_TimeoutAndProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.internal:3128")
+	cfg := &Config{HTTPTimeout: 7 * time.Second, HTTPProxy: proxyURL}
+
+	client := NewHTTPClient(cfg)
+	if client == nil {
+		t.Fatal("expected non-nil client")
+	}
+
+	if client.Timeout != 7*time.Second {
+		t.Errorf("expected 7s timeout, got %s", client.Timeout)
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.Transport)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://slack.com/api/chat.postMessage", nil)
+	got, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("unexpected proxy error: %v", err)
+	}
+
+	if got == nil || got.String() != proxyURL.String() {
+		t.Errorf("expected proxy %s, got %v", proxyURL, got)
+	}
+}
+
+// TestAWSConfigOptions_Configured validates that the AWS config receives a
+// buildable client carrying the configured timeout and proxy.
+func TestAWSConfigOptions_Configured(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.internal:3128")
+	cfg := &Config{HTTPTimeout: 9 * time.Second, HTTPProxy: proxyURL}
+
+	lo := applyLoadOptions(t, awsConfigOptions(nil, cfg))
+
+	client, ok := lo.HTTPClient.(*awshttp.BuildableClient)
+	if !ok {
+		t.Fatalf("expected *awshttp.BuildableClient, got %T", lo.HTTPClient)
+	}
+
+	if client.GetTimeout() != 9*time.Second {
+		t.Errorf("expected 9s timeout, got %s", client.GetTimeout())
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://securityhub.us-east-1.amazonaws.com", nil)
+	got, err := client.GetTransport().Proxy(req)
+	if err != nil {
+		t.Fatalf("unexpected proxy error: %v", err)
+	}
+
+	if got == nil || got.String() != proxyURL.String() {
+		t.Errorf("expected proxy %s, got %v", proxyURL, got)
+	}
+}
+
+// TestAWSConfigOptions_ContextClient validates that a context-injected client
+// takes precedence over the configured client.
+func TestAWSConfigOptions_ContextClient(t *testing.T) {
+	injected := &http.Client{}
+	cfg := &Config{HTTPTimeout: 9 * time.Second}
+
+	lo := applyLoadOptions(t, awsConfigOptions(injected, cfg))
+
+	if lo.HTTPClient != injected {
+		t.Errorf("expected context client, got %T", lo.HTTPClient)
+	}
+}
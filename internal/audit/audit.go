@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Record describes an action an auto-close rule took on a finding.
+type Record struct {
+	Time       time.Time `json:"time"`
+	FindingUID string    `json:"finding_uid"`
+	AccountUID string    `json:"account_uid"`
+	Rule       string    `json:"rule"`
+	Action     string    `json:"action"`
+	StatusID   int32     `json:"status_id"`
+	Status     string    `json:"status,omitempty"`
+	Owner      string    `json:"owner,omitempty"`
+	Ticket     string    `json:"ticket,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type Sink interface {
+	Record(ctx context.Context, record Record) error
+}
+
+// LogSink writes audit records to the structured log.
+type LogSink struct {
+	logger *slog.Logger
+}
+
+func NewLogSink(logger *slog.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Record(ctx context.Context, record Record) error {
+	s.logger.InfoContext(ctx, "audit",
+		"time", record.Time,
+		"finding_uid", record.FindingUID,
+		"account_uid", record.AccountUID,
+		"rule", record.Rule,
+		"action", record.Action,
+		"status_id", record.StatusID,
+		"status", record.Status,
+		"owner", record.Owner,
+		"ticket", record.Ticket,
+		"reason", record.Reason)
+	return nil
+}
+
+// MemorySink keeps audit records in memory, for tests and local runs.
+type MemorySink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Record(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns a copy of all records in the order they were written.
+func (s *MemorySink) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]Record, len(s.records))
+	copy(records, s.records)
+	return records
+}
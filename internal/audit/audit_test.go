@@ -0,0 +1,66 @@
+// Package audit tests audit record sinks.
+//
+// Tests cover:
+// - Log sink writes every record field
+// - Memory sink keeps records in order
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// TestLogSink_Record validates that the log sink emits the rule's audit
+// metadata as structured fields.
+func TestLogSink_Record(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewLogSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	record := Record{
+		Time:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		FindingUID: "finding-1",
+		Rule:       "close-runs-on",
+		Action:     "closed",
+		StatusID:   3,
+		Owner:      "platform-team",
+		Ticket:     "SEC-123",
+		Reason:     "expected",
+	}
+	if err := sink.Record(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid log JSON: %v", err)
+	}
+
+	for key, expected := range map[string]any{
+		"msg":         "audit",
+		"finding_uid": "finding-1",
+		"rule":        "close-runs-on",
+		"owner":       "platform-team",
+		"ticket":      "SEC-123",
+		"reason":      "expected",
+	} {
+		if entry[key] != expected {
+			t.Errorf("expected %s=%v, got %v", key, expected, entry[key])
+		}
+	}
+}
+
+// TestMemorySink_Records validates that records are returned in order.
+func TestMemorySink_Records(t *testing.T) {
+	sink := NewMemorySink()
+	sink.Record(context.Background(), Record{Rule: "a"})
+	sink.Record(context.Background(), Record{Rule: "b"})
+
+	records := sink.Records()
+	if len(records) != 2 || records[0].Rule != "a" || records[1].Rule != "b" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
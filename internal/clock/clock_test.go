@@ -0,0 +1,40 @@
+// Package clock tests the real and fake clocks.
+//
+// Tests cover:
+// - The fake clock stays put until set or advanced
+// - The real clock follows the system time
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFake validates that the fake clock only moves when set or advanced.
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	if !c.Now().Equal(start) {
+		t.Fatalf("expected %s, got %s", start, c.Now())
+	}
+
+	c.Advance(90 * time.Minute)
+	if want := start.Add(90 * time.Minute); !c.Now().Equal(want) {
+		t.Errorf("expected %s after advancing, got %s", want, c.Now())
+	}
+
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("expected %s after setting, got %s", start, c.Now())
+	}
+}
+
+// TestReal validates that the real clock reports the system time.
+func TestReal(t *testing.T) {
+	before := time.Now()
+	now := Real{}.Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("expected the system time, got %s", now)
+	}
+}
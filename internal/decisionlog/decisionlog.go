@@ -0,0 +1,136 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+const (
+	// ActionNone is logged when no rule matched the finding
+	ActionNone = "none"
+	// ActionDuplicate is logged for a finding repeated within one event
+	ActionDuplicate = "duplicate"
+	// ActionError is logged when closing a matched finding failed
+	ActionError = "error"
+
+	NotificationNone   = "none"
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+	// NotificationSuppressed is logged when the finding was notified within
+	// the dedup window
+	NotificationSuppressed = "suppressed"
+	// NotificationDigest is logged when the finding is notified in its rule's
+	// digest at the end of the event
+	NotificationDigest = "digest"
+)
+
+// Entry describes every decision made for one finding, including findings no
+// rule matched, so processing can be replayed after an incident.
+type Entry struct {
+	Time          time.Time `json:"time"`
+	EventID       string    `json:"event_id,omitempty"`
+	Fingerprint   string    `json:"fingerprint"`
+	FindingUID    string    `json:"finding_uid"`
+	AccountUID    string    `json:"account_uid"`
+	Region        string    `json:"region"`
+	Product       string    `json:"product"`
+	Types         []string  `json:"types,omitempty"`
+	Severity      string    `json:"severity"`
+	StatusID      int       `json:"status_id"`
+	Status        string    `json:"status"`
+	Rule          string    `json:"rule,omitempty"`
+	Action        string    `json:"action"`
+	BlockedReason string    `json:"blocked_reason,omitempty"`
+	Notification  string    `json:"notification"`
+	Error         string    `json:"error,omitempty"`
+	DurationMS    float64   `json:"duration_ms"`
+}
+
+// NewEntry returns an entry describing the finding as it was received.
+func NewEntry(eventID string, finding *events.SecurityHubV2Finding) Entry {
+	return Entry{
+		EventID:      eventID,
+		Fingerprint:  finding.Fingerprint(),
+		FindingUID:   finding.Metadata.UID,
+		AccountUID:   finding.Cloud.Account.UID,
+		Region:       finding.Cloud.Region,
+		Product:      finding.Metadata.Product.Name,
+		Types:        finding.FindingInfo.Types,
+		Severity:     finding.Severity,
+		StatusID:     finding.StatusID,
+		Status:       events.StatusName(finding.StatusID),
+		Action:       ActionNone,
+		Notification: NotificationNone,
+	}
+}
+
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// MultiSink writes each entry to every sink.
+type MultiSink []Sink
+
+func (m MultiSink) Write(ctx context.Context, entry Entry) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriterSink writes entries as JSON lines, e.g. to stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal decision log entry")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// MemorySink keeps entries in memory, for tests and local runs.
+type MemorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Write(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of all entries in the order they were written.
+func (s *MemorySink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, len(s.entries))
+	copy(entries, s.entries)
+	return entries
+}
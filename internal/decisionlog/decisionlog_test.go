@@ -0,0 +1,133 @@
+// Package decisionlog tests decision log entries and sinks.
+//
+// Tests cover:
+// - Entries describe the finding input with a stable fingerprint
+// - Writer sink writes one JSON line per entry
+// - S3 sink writes one date-keyed object per entry
+// - Multi sink writes to every sink
+package decisionlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+func newTestFinding(t *testing.T, raw string) *events.SecurityHubV2Finding {
+	t.Helper()
+	finding, err := events.NewSecurityHubFinding(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("failed to parse finding: %v", err)
+	}
+	return finding
+}
+
+// TestNewEntry validates that an entry carries the finding's key fields and a
+// fingerprint that only changes with the input.
+func TestNewEntry(t *testing.T) {
+	raw := `{"metadata":{"uid":"finding-1","product":{"name":"GuardDuty"}},"cloud":{"account":{"uid":"123456789012"},"region":"eu-west-1"},"finding_info":{"types":["Recon:EC2/PortProbeUnprotectedPort"]},"severity":"Medium","status_id":1}`
+	entry := NewEntry("event-1", newTestFinding(t, raw))
+
+	if entry.EventID != "event-1" || entry.FindingUID != "finding-1" || entry.AccountUID != "123456789012" ||
+		entry.Region != "eu-west-1" || entry.Product != "GuardDuty" || entry.Severity != "Medium" || entry.StatusID != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Action != ActionNone || entry.Notification != NotificationNone {
+		t.Errorf("expected no action or notification by default, got %s, %s", entry.Action, entry.Notification)
+	}
+
+	if again := NewEntry("event-2", newTestFinding(t, raw)); again.Fingerprint != entry.Fingerprint {
+		t.Error("expected the same input to have the same fingerprint")
+	}
+	changed := strings.Replace(raw, `"status_id":1`, `"status_id":2`, 1)
+	if other := NewEntry("event-1", newTestFinding(t, changed)); other.Fingerprint == entry.Fingerprint {
+		t.Error("expected a changed input to have a different fingerprint")
+	}
+}
+
+// TestWriterSink_Write validates that entries are written as JSON lines.
+func TestWriterSink_Write(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	sink.Write(context.Background(), Entry{FindingUID: "a", Action: "closed", Rule: "close-a"})
+	sink.Write(context.Background(), Entry{FindingUID: "b", Action: ActionNone, Error: "boom"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var entry Entry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if entry.FindingUID != "b" || entry.Error != "boom" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+type mockS3Client struct {
+	inputs []*s3.PutObjectInput
+	bodies []string
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(params.Body)
+	m.inputs = append(m.inputs, params)
+	m.bodies = append(m.bodies, string(body))
+	return &s3.PutObjectOutput{}, nil
+}
+
+// TestS3Sink_Write validates the object key and body written for an entry.
+func TestS3Sink_Write(t *testing.T) {
+	client := &mockS3Client{}
+	sink := NewS3Sink(client, "decisions-bucket", "decisions/")
+
+	entry := Entry{
+		Time:        time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+		FindingUID:  "finding-1",
+		Fingerprint: "0123456789abcdef0123456789abcdef",
+		Action:      ActionNone,
+	}
+	if err := sink.Write(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(client.inputs))
+	}
+
+	input := client.inputs[0]
+	if aws.ToString(input.Bucket) != "decisions-bucket" {
+		t.Errorf("unexpected bucket: %s", aws.ToString(input.Bucket))
+	}
+	if key := aws.ToString(input.Key); key != "decisions/2025/01/02/030405.000000006-0123456789abcdef.json" {
+		t.Errorf("unexpected key: %s", key)
+	}
+	if !strings.Contains(client.bodies[0], `"finding_uid":"finding-1"`) {
+		t.Errorf("unexpected body: %s", client.bodies[0])
+	}
+}
+
+// TestMultiSink_Write validates that every sink receives the entry.
+func TestMultiSink_Write(t *testing.T) {
+	first, second := NewMemorySink(), NewMemorySink()
+	sink := MultiSink{first, second}
+
+	if err := sink.Write(context.Background(), Entry{FindingUID: "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(first.Entries()) != 1 || len(second.Entries()) != 1 {
+		t.Errorf("expected both sinks to receive the entry, got %d and %d", len(first.Entries()), len(second.Entries()))
+	}
+}
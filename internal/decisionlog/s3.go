@@ -0,0 +1,51 @@
+package decisionlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cockroachdb/errors"
+)
+
+type S3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Sink writes each entry to its own object, keyed by date so a day's
+// decisions can be listed together.
+type S3Sink struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+func NewS3Sink(client S3Client, bucket, prefix string) *S3Sink {
+	return &S3Sink{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *S3Sink) Write(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal decision log entry")
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.Key(entry)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write decision log to s3://%s", s.bucket)
+	}
+	return nil
+}
+
+// Key returns the object key for an entry.
+func (s *S3Sink) Key(entry Entry) string {
+	t := entry.Time.UTC()
+	return s.prefix + t.Format("2006/01/02/150405.000000000") + "-" + entry.Fingerprint[:min(16, len(entry.Fingerprint))] + ".json"
+}
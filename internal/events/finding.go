@@ -1,11 +1,14 @@
 package events
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -18,6 +21,7 @@ type SecurityHubV2Finding struct {
 	ClassName    string          `json:"class_name"`
 	ClassUID     int             `json:"class_uid"`
 	Cloud        Cloud           `json:"cloud"`
+	Comment      string          `json:"comment,omitempty"`
 	Compliance   *OCSFCompliance `json:"compliance,omitempty"`
 	FindingInfo  FindingInfo     `json:"finding_info"`
 	Metadata     Metadata        `json:"metadata"`
@@ -31,6 +35,9 @@ type SecurityHubV2Finding struct {
 	TimeDt       string          `json:"time_dt"`
 	TypeName     string          `json:"type_name"`
 	TypeUID      int             `json:"type_uid"`
+
+	// raw holds the original finding json for filters on unmapped fields
+	Raw json.RawMessage `json:"-"`
 }
 
 type Cloud struct {
@@ -134,124 +141,623 @@ type ResourceTag struct {
 	Value string `json:"value"`
 }
 
-func (shf *SecurityHubV2Finding) SlackMessage(consoleURL, accessPortalURL, accessRoleName, shRegion string) (slack.MsgOption, slack.MsgOption) {
+const (
+	// MaxRemediationReferences caps the remediation links rendered in Slack.
+	MaxRemediationReferences = 5
+
+	// MaxAdditionalResources caps the non-primary resources rendered in Slack.
+	MaxAdditionalResources = 5
+
+	// CompactResourcesThreshold is the resource count above which resources
+	// are rendered as one compact list instead of per-resource fields.
+	CompactResourcesThreshold = 1 + MaxAdditionalResources
+
+	// MaxCompactResources caps the resource UIDs in the compact list.
+	MaxCompactResources = 25
+
+	// MaxSectionText is Slack's limit on the text of a section block.
+	MaxSectionText = 3000
+
+	// MaxSectionFields is Slack's limit on fields in a single section block.
+	MaxSectionFields = 10
+
+	// MaxSlackBlocks is Slack's limit on blocks in a single message.
+	MaxSlackBlocks = 50
+)
+
+// ConsoleLinkOptions configures finding console links.
+type ConsoleLinkOptions struct {
+	ConsoleURL      string
+	AccessPortalURL string
+	AccessRoleName  string
+	// AccessRoleNames overrides AccessRoleName for specific account ids
+	AccessRoleNames map[string]string
+	// ConsoleRegion is the matched rule's or the configured console region,
+	// used over SecurityHubRegion and the finding's region when set
+	ConsoleRegion     string
+	SecurityHubRegion string
+	CategoryMappings  []CategoryMapping
+}
+
+// SlackMessageOptions configures console links and extra content for Slack
+// messages.
+type SlackMessageOptions struct {
+	ConsoleLinkOptions
+	Footer string
+	// Fields selects and orders the rendered fields, nil for all of them
+	Fields []string
+	// CategoryFields overrides Fields for findings by FindingCategory
+	CategoryFields map[string][]string
+	// Ticket is the matched rule's ticket, linked to TicketURL when set
+	Ticket    string
+	TicketURL string
+	// MaxBlocks caps the message's blocks, 0 for Slack's MaxSlackBlocks
+	MaxBlocks int
+}
+
+func (shf *SecurityHubV2Finding) SlackMessage(opts SlackMessageOptions) (slack.MsgOption, slack.MsgOption) {
+	return slack.MsgOptionText(shf.FindingInfo.Title, false), slack.MsgOptionBlocks(shf.SlackBlocks(opts)...)
+}
+
+// slack message field keys, in default render order.
+const (
+	SlackFieldDescription = "description"
+	SlackFieldSeverity    = "severity"
+	SlackFieldSource      = "source"
+	SlackFieldCategory    = "category"
+	SlackFieldAccount     = "account"
+	SlackFieldFindingID   = "finding_id"
+	SlackFieldCompliance  = "compliance"
+	SlackFieldResource    = "resource"
+	SlackFieldRemediation = "remediation"
+)
+
+// DefaultSlackFields renders every field. the title header and console
+// button are always rendered.
+var DefaultSlackFields = []string{
+	SlackFieldDescription,
+	SlackFieldSeverity,
+	SlackFieldSource,
+	SlackFieldCategory,
+	SlackFieldAccount,
+	SlackFieldFindingID,
+	SlackFieldCompliance,
+	SlackFieldResource,
+	SlackFieldRemediation,
+}
+
+// slack block priorities, dropped highest first when a message is over its
+// block cap. essential blocks are always kept.
+const (
+	blockEssential = iota
+	blockDetail
+	blockEnrichment
+)
+
+// SlackBlocks renders the finding as Slack blocks. messages over the block cap
+// drop resources and remediation, then the other detail blocks, and end with
+// a truncation note; the header, severity and console button are always kept.
+func (shf *SecurityHubV2Finding) SlackBlocks(opts SlackMessageOptions) []slack.Block {
 	var blocks []slack.Block
+	var priorities []int
+	add := func(priority int, bs ...slack.Block) {
+		blocks = append(blocks, bs...)
+		for range bs {
+			priorities = append(priorities, priority)
+		}
+	}
 
 	severityEmoji := shf.GetSeverityEmoji()
 	headerText := fmt.Sprintf("%s %s", severityEmoji, shf.FindingInfo.Title)
 	header := slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", headerText, false, false))
-	blocks = append(blocks, header)
+	add(blockEssential, header)
 
-	descriptionSection := slack.NewSectionBlock(
-		slack.NewTextBlockObject("mrkdwn", shf.FindingInfo.Desc, false, false),
-		nil, nil,
-	)
-	blocks = append(blocks, descriptionSection)
+	fields := shf.slackFields(opts)
 
+	// consecutive detail fields share a section
 	var detailFields []*slack.TextBlockObject
-	detailFields = append(detailFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Severity*\n%s", shf.Severity), false, false))
-	detailFields = append(detailFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Source*\n%s", shf.Metadata.Product.Name), false, false))
-
-	findingCategory := shf.GetFindingCategory()
-	detailFields = append(detailFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Category*\n%s", findingCategory), false, false))
+	detailPriority := blockDetail
+	flushDetails := func() {
+		add(detailPriority, fieldSections(detailFields)...)
+		detailFields = nil
+		detailPriority = blockDetail
+	}
+	addDetail := func(name, value string) {
+		detailFields = append(detailFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s*\n%s", name, value), false, false))
+	}
 
-	detailFields = append(detailFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Account*\n%s", shf.Cloud.Account.UID), false, false))
+	for _, field := range fields {
+		switch field {
+		case SlackFieldSeverity:
+			addDetail("Severity", shf.Severity)
+			detailPriority = blockEssential
+			continue
+		case SlackFieldSource:
+			addDetail("Source", shf.Metadata.Product.Name)
+			continue
+		case SlackFieldCategory:
+			addDetail("Category", shf.FindingCategory(opts.CategoryMappings))
+			continue
+		case SlackFieldAccount:
+			addDetail("Account", shf.Cloud.Account.UID)
+			continue
+		}
 
-	details := slack.NewSectionBlock(nil, detailFields, nil)
-	blocks = append(blocks, details)
+		flushDetails()
+
+		switch field {
+		case SlackFieldDescription:
+			descriptionSection := slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", shf.FindingInfo.Desc, false, false),
+				nil, nil,
+			)
+			add(blockDetail, descriptionSection)
+		case SlackFieldCompliance:
+			add(blockDetail, shf.complianceBlocks()...)
+		case SlackFieldFindingID:
+			findingIDSection := slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Finding ID*\n`%s`", shf.Metadata.UID), false, false),
+				nil, nil,
+			)
+			add(blockDetail, findingIDSection)
+		case SlackFieldResource:
+			add(blockEnrichment, shf.resourceBlocks()...)
+		case SlackFieldRemediation:
+			add(blockEnrichment, shf.remediationBlocks()...)
+		}
+	}
+	flushDetails()
 
-	findingIDSection := slack.NewSectionBlock(
-		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Finding ID*\n`%s`", shf.Metadata.UID), false, false),
-		nil, nil,
+	consoleUrl := shf.BuildConsoleUrl(opts.ConsoleLinkOptions)
+	buttonSection := slack.NewActionBlock(
+		"actions",
+		slack.NewButtonBlockElement(
+			"view_finding",
+			"view",
+			slack.NewTextBlockObject("plain_text", "View in Security Hub", false, false),
+		).WithStyle(slack.StylePrimary).WithURL(consoleUrl),
 	)
-	blocks = append(blocks, findingIDSection)
+	add(blockEssential, buttonSection)
 
-	if len(shf.Resources) > 0 {
-		resource := shf.Resources[0]
-		var resourceFields []*slack.TextBlockObject
-		resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Resource Type*\n`%s`", resource.Type), false, false))
-		resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Region*\n`%s`", resource.Region), false, false))
+	if opts.Ticket != "" {
+		text := "*Ticket:* " + opts.Ticket
+		if opts.TicketURL != "" {
+			text = fmt.Sprintf("*Ticket:* <%s|%s>", opts.TicketURL, opts.Ticket)
+		}
+		add(blockDetail, slack.NewContextBlock(
+			"ticket",
+			slack.NewTextBlockObject("mrkdwn", text, false, false),
+		))
+	}
 
-		resourceName := resource.UID
-		if resource.Name != "" {
-			resourceName = resource.Name
+	if opts.Footer != "" {
+		footer := slack.NewContextBlock(
+			"footer",
+			slack.NewTextBlockObject("mrkdwn", opts.Footer, false, false),
+		)
+		add(blockDetail, footer)
+	}
+
+	maxBlocks := opts.MaxBlocks
+	if maxBlocks <= 0 || maxBlocks > MaxSlackBlocks {
+		maxBlocks = MaxSlackBlocks
+	}
+	return capBlocks(blocks, priorities, maxBlocks, consoleUrl)
+}
+
+// capBlocks drops the lowest priority blocks, latest first, until the blocks
+// and a truncation note linking to consoleURL fit in maxBlocks. essential
+// blocks are kept even if they alone exceed it.
+func capBlocks(blocks []slack.Block, priorities []int, maxBlocks int, consoleURL string) []slack.Block {
+	if len(blocks) <= maxBlocks {
+		return blocks
+	}
+
+	drop := make([]bool, len(blocks))
+	kept := len(blocks)
+	for priority := blockEnrichment; priority > blockEssential && kept+1 > maxBlocks; priority-- {
+		for i := len(blocks) - 1; i >= 0 && kept+1 > maxBlocks; i-- {
+			if priorities[i] == priority {
+				drop[i] = true
+				kept--
+			}
 		}
-		if len(resourceName) > 60 {
-			parts := strings.Split(resourceName, "/")
-			resourceName = parts[len(parts)-1]
+	}
+
+	capped := make([]slack.Block, 0, kept+1)
+	for i, block := range blocks {
+		if !drop[i] {
+			capped = append(capped, block)
 		}
-		resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Resource ID*\n`%s`", resourceName), false, false))
+	}
+	note := fmt.Sprintf("_Message truncated — <%s|view the full finding in the console>_", consoleURL)
+	return append(capped, slack.NewContextBlock(
+		"truncated",
+		slack.NewTextBlockObject("mrkdwn", note, false, false),
+	))
+}
+
+// slackFields returns the fields to render: the finding category's layout,
+// then opts.Fields, then every field.
+func (shf *SecurityHubV2Finding) slackFields(opts SlackMessageOptions) []string {
+	if fields, ok := opts.CategoryFields[shf.FindingCategory(opts.CategoryMappings)]; ok {
+		return fields
+	}
+	if opts.Fields != nil {
+		return opts.Fields
+	}
+	return DefaultSlackFields
+}
+
+// complianceBlocks renders the standards and control of a compliance finding.
+func (shf *SecurityHubV2Finding) complianceBlocks() []slack.Block {
+	if shf.Compliance == nil {
+		return nil
+	}
+
+	var fields []*slack.TextBlockObject
+	if len(shf.Compliance.Standards) > 0 {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", "*Standards*\n"+strings.Join(shf.Compliance.Standards, "\n"), false, false))
+	}
+	if shf.Compliance.Control != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Control*\n`%s`", shf.Compliance.Control), false, false))
+	}
+	return fieldSections(fields)
+}
 
-		resourceSection := slack.NewSectionBlock(nil, resourceFields, nil)
-		blocks = append(blocks, resourceSection)
+func (shf *SecurityHubV2Finding) resourceBlocks() []slack.Block {
+	resource, ok := shf.PrimaryResource()
+	if !ok {
+		return nil
 	}
 
-	if shf.Remediation != nil && len(shf.Remediation.References) > 0 {
-		remediationText := fmt.Sprintf("*Remediation*\n%s\n<%s>",
-			shf.Remediation.Desc,
-			shf.Remediation.References[0])
-		remediationSection := slack.NewSectionBlock(
-			slack.NewTextBlockObject("mrkdwn", remediationText, false, false),
+	resources := shf.UniqueResources()
+	if len(resources) > CompactResourcesThreshold {
+		return compactResourceBlocks(resources)
+	}
+
+	var resourceFields []*slack.TextBlockObject
+	resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Resource Type*\n`%s`", resource.Type), false, false))
+	resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Region*\n`%s`", resource.Region), false, false))
+
+	resourceName := resource.UID
+	if resource.Name != "" {
+		resourceName = resource.Name
+	}
+	if len(resourceName) > 60 {
+		parts := strings.Split(resourceName, "/")
+		resourceName = parts[len(parts)-1]
+	}
+	resourceFields = append(resourceFields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Resource ID*\n`%s`", resourceName), false, false))
+
+	blocks := fieldSections(resourceFields)
+
+	if others := resources[1:]; len(others) > 0 {
+		var lines []string
+		for _, r := range others {
+			lines = append(lines, fmt.Sprintf("• `%s`", r.UID))
+		}
+		additional := slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", "*Additional Resources*\n"+strings.Join(lines, "\n"), false, false),
 			nil, nil,
 		)
-		blocks = append(blocks, remediationSection)
+		blocks = append(blocks, additional)
 	}
 
-	consoleUrl := shf.BuildConsoleUrl(consoleURL, accessPortalURL, accessRoleName, shRegion)
-	buttonSection := slack.NewActionBlock(
-		"actions",
-		slack.NewButtonBlockElement(
-			"view_finding",
-			"view",
-			slack.NewTextBlockObject("plain_text", "View in Security Hub", false, false),
-		).WithStyle(slack.StylePrimary).WithURL(consoleUrl),
+	return blocks
+}
+
+// compactResourceBlocks renders many resources as a single code block of
+// UIDs, truncated to MaxCompactResources lines and Slack's section text limit.
+func compactResourceBlocks(resources []OCSFResource) []slack.Block {
+	header := fmt.Sprintf("*Resources (%d)*\n", len(resources))
+	more := func(n int) string {
+		return fmt.Sprintf("\n_and %d more_", n)
+	}
+
+	var lines []string
+	size := len(header) + len("``````") + len(more(len(resources)))
+	for _, r := range resources {
+		if len(lines) == MaxCompactResources || size+len(r.UID)+1 > MaxSectionText {
+			break
+		}
+		lines = append(lines, r.UID)
+		size += len(r.UID) + 1
+	}
+
+	text := header + "```" + strings.Join(lines, "\n") + "```"
+	if hidden := len(resources) - len(lines); hidden > 0 {
+		text += more(hidden)
+	}
+
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil),
+	}
+}
+
+func (shf *SecurityHubV2Finding) remediationBlocks() []slack.Block {
+	if shf.Remediation == nil || len(shf.Remediation.References) == 0 {
+		return nil
+	}
+
+	references := shf.Remediation.References
+	if len(references) > MaxRemediationReferences {
+		references = references[:MaxRemediationReferences]
+	}
+
+	var links []string
+	for _, ref := range references {
+		links = append(links, fmt.Sprintf("• <%s>", ref))
+	}
+	if more := len(shf.Remediation.References) - len(references); more > 0 {
+		links = append(links, fmt.Sprintf("_and %d more_", more))
+	}
+
+	remediationText := fmt.Sprintf("*Remediation*\n%s\n%s",
+		shf.Remediation.Desc,
+		strings.Join(links, "\n"))
+	remediationSection := slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn", remediationText, false, false),
+		nil, nil,
 	)
-	blocks = append(blocks, buttonSection)
+	return []slack.Block{remediationSection}
+}
+
+// fieldSections splits fields across as many section blocks as needed to
+// stay within Slack's per-section field limit.
+func fieldSections(fields []*slack.TextBlockObject) []slack.Block {
+	var sections []slack.Block
+	for chunk := range slices.Chunk(fields, MaxSectionFields) {
+		sections = append(sections, slack.NewSectionBlock(nil, chunk, nil))
+	}
+	return sections
+}
+
+// UniqueResources returns the finding's resources with repeated UIDs removed,
+// keeping the first occurrence.
+func (shf *SecurityHubV2Finding) UniqueResources() []OCSFResource {
+	seen := make(map[string]bool, len(shf.Resources))
+	resources := make([]OCSFResource, 0, len(shf.Resources))
+	for _, r := range shf.Resources {
+		if seen[r.UID] {
+			continue
+		}
+		seen[r.UID] = true
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// ResourceUIDs returns the unique resource UIDs in finding order.
+func (shf *SecurityHubV2Finding) ResourceUIDs() []string {
+	resources := shf.UniqueResources()
+	uids := make([]string, 0, len(resources))
+	for _, r := range resources {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
 
-	return slack.MsgOptionText(shf.FindingInfo.Title, false), slack.MsgOptionBlocks(blocks...)
+// PrimaryResource returns the first resource on the finding, if any.
+func (shf *SecurityHubV2Finding) PrimaryResource() (OCSFResource, bool) {
+	if len(shf.Resources) == 0 {
+		return OCSFResource{}, false
+	}
+	return shf.Resources[0], true
 }
 
+// timestamp fields accepted by Timestamp.
+const (
+	TimestampEvent     = "time"
+	TimestampFirstSeen = "first_seen"
+	TimestampLastSeen  = "last_seen"
+	TimestampCreated   = "created"
+	TimestampModified  = "modified"
+)
+
+// Timestamp returns the named timestamp from its epoch milliseconds, falling
+// back to the RFC3339 *_dt string when the epoch is zero. the zero time means
+// the finding has neither.
+func (shf *SecurityHubV2Finding) Timestamp(field string) time.Time {
+	var ms int64
+	var dt string
+	switch field {
+	case TimestampEvent:
+		ms, dt = shf.Time, shf.TimeDt
+	case TimestampFirstSeen:
+		ms, dt = shf.FindingInfo.FirstSeenTime, shf.FindingInfo.FirstSeenTimeDt
+	case TimestampLastSeen:
+		ms, dt = shf.FindingInfo.LastSeenTime, shf.FindingInfo.LastSeenTimeDt
+	case TimestampCreated:
+		ms, dt = shf.FindingInfo.CreatedTime, shf.FindingInfo.CreatedTimeDt
+	case TimestampModified:
+		ms, dt = shf.FindingInfo.ModifiedTime, shf.FindingInfo.ModifiedTimeDt
+	default:
+		return time.Time{}
+	}
+
+	if ms != 0 {
+		return time.UnixMilli(ms)
+	}
+	if t, err := time.Parse(time.RFC3339, dt); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// Fingerprint returns a stable sha256 over the finding's uid, modified time
+// and status id, so re-imports of the same content share a fingerprint while
+// updates to a finding with the same uid don't.
+func (shf *SecurityHubV2Finding) Fingerprint() string {
+	var modified int64
+	if t := shf.Timestamp(TimestampModified); !t.IsZero() {
+		modified = t.UnixMilli()
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\n%d\n%d", shf.Metadata.UID, modified, shf.StatusID))
+	return hex.EncodeToString(sum[:])
+}
+
+// StatusName returns the OCSF status name for a status id.
+func StatusName(statusID int) string {
+	switch statusID {
+	case 1:
+		return "New"
+	case 2:
+		return "In Progress"
+	case 3:
+		return "Suppressed"
+	case 4:
+		return "Resolved"
+	case 5:
+		return "Archived"
+	case 6:
+		return "Deleted"
+	case 99:
+		return "Other"
+	default:
+		return "Unknown"
+	}
+}
+
+// StatusNames overrides StatusName for specific status ids, so logs and
+// records can use an organization's own wording.
+type StatusNames map[int]string
+
+// Name returns the status name for a status id, from the overrides when set
+// and the OCSF name otherwise.
+func (n StatusNames) Name(statusID int) string {
+	if name, ok := n[statusID]; ok {
+		return name
+	}
+	return StatusName(statusID)
+}
+
+// NormalizeStatus maps a product-specific status string to its canonical
+// OCSF status id and name. unmapped statuses are left as-is.
+func (shf *SecurityHubV2Finding) NormalizeStatus(mappings map[string]int) {
+	if statusID, ok := mappings[shf.Status]; ok {
+		shf.StatusID = statusID
+		shf.Status = StatusName(statusID)
+	}
+}
+
+// AlertSeverities are the severities of new findings alerted by default.
+var AlertSeverities = []string{"Critical", "High", "Medium"}
+
 func (shf *SecurityHubV2Finding) IsAlertable() bool {
 	if shf.Status != "New" {
 		return false
 	}
 
-	if shf.Compliance != nil && shf.Compliance.Status == "Fail" {
+	if shf.IsFailedCompliance() {
 		return true
 	}
 
-	alertSeverities := []string{"Critical", "High", "Medium"}
-	return slices.Contains(alertSeverities, shf.Severity)
+	return slices.Contains(AlertSeverities, shf.Severity)
+}
+
+// IsFailedCompliance reports whether the finding is a failed compliance check.
+func (shf *SecurityHubV2Finding) IsFailedCompliance() bool {
+	return shf.Compliance != nil && shf.Compliance.Status == "Fail"
 }
 
-func NewSecurityHubFinding(raw json.RawMessage) (*SecurityHubV2Finding, error) {
+// IsInformational reports whether the finding is informational by severity
+// or severity_id.
+func (shf *SecurityHubV2Finding) IsInformational() bool {
+	return strings.EqualFold(shf.Severity, "Informational") || shf.SeverityID == 1
+}
+
+// NewSecurityHubFinding parses a finding, normalizing it with the first field
+// mapping for its product, if any.
+func NewSecurityHubFinding(raw json.RawMessage, mappings ...FieldMapping) (*SecurityHubV2Finding, error) {
 	var shf SecurityHubV2Finding
 	if err := json.Unmarshal(raw, &shf); err != nil {
 		return &SecurityHubV2Finding{}, err
 	}
+	shf.Raw = raw
+	if err := applyFieldMappings(&shf, mappings); err != nil {
+		return &SecurityHubV2Finding{}, err
+	}
+	if shf.Severity == "" {
+		shf.Severity = SeverityName(shf.SeverityID)
+	}
 	return &shf, nil
 }
 
+// SeverityName maps an OCSF severity_id to its canonical severity string.
+func SeverityName(severityID int) string {
+	switch severityID {
+	case 1:
+		return "Informational"
+	case 2:
+		return "Low"
+	case 3:
+		return "Medium"
+	case 4:
+		return "High"
+	case 5:
+		return "Critical"
+	case 6:
+		return "Fatal"
+	case 99:
+		return "Other"
+	default:
+		return "Unknown"
+	}
+}
+
+// SeverityID maps a canonical severity string, ignoring case, to its OCSF
+// severity_id. unknown severities map to 0.
+func SeverityID(severity string) int {
+	for _, id := range []int{1, 2, 3, 4, 5, 6, 99} {
+		if strings.EqualFold(SeverityName(id), severity) {
+			return id
+		}
+	}
+	return 0
+}
+
+// KnownSeverities lists the severity strings SeverityName can produce.
+var KnownSeverities = []string{"Informational", "Low", "Medium", "High", "Critical", "Fatal", "Other", "Unknown"}
+
+// HasKnownSeverity reports whether the finding's severity is one of
+// KnownSeverities. other severities miss severity filters and get the default
+// emoji, so callers should surface them.
+func (shf *SecurityHubV2Finding) HasKnownSeverity() bool {
+	return slices.Contains(KnownSeverities, shf.Severity)
+}
+
+// CategoryMapping maps a substring of a finding type to a display category.
+type CategoryMapping struct {
+	Substring string `json:"substring"`
+	Category  string `json:"category"`
+}
+
+// DefaultCategoryMappings are the built-in mappings in precedence order.
+var DefaultCategoryMappings = []CategoryMapping{
+	{Substring: "Threats", Category: "Threats"},
+	{Substring: "Posture Management", Category: "Posture Management"},
+	{Substring: "Exposure", Category: "Exposure"},
+	{Substring: "Vulnerabilities", Category: "Vulnerabilities"},
+	{Substring: "Sensitive data", Category: "Sensitive Data"},
+}
+
 func (shf *SecurityHubV2Finding) GetFindingCategory() string {
-	if len(shf.FindingInfo.Types) == 0 {
-		return shf.CategoryName
+	return shf.FindingCategory(nil)
+}
+
+// FindingCategory classifies the finding using the first mapping whose
+// substring appears in a finding type. nil mappings use the defaults.
+func (shf *SecurityHubV2Finding) FindingCategory(mappings []CategoryMapping) string {
+	if mappings == nil {
+		mappings = DefaultCategoryMappings
 	}
 
 	for _, findingType := range shf.FindingInfo.Types {
-		if strings.Contains(findingType, "Threats") {
-			return "Threats"
-		}
-		if strings.Contains(findingType, "Posture Management") {
-			return "Posture Management"
-		}
-		if strings.Contains(findingType, "Exposure") {
-			return "Exposure"
-		}
-		if strings.Contains(findingType, "Vulnerabilities") {
-			return "Vulnerabilities"
-		}
-		if strings.Contains(findingType, "Sensitive data") {
-			return "Sensitive Data"
+		for _, m := range mappings {
+			if strings.Contains(findingType, m.Substring) {
+				return m.Category
+			}
 		}
 	}
 
@@ -275,14 +781,21 @@ func (shf *SecurityHubV2Finding) GetSeverityEmoji() string {
 	}
 }
 
-func (shf *SecurityHubV2Finding) BuildConsoleUrl(consoleURL, accessPortalURL, accessRoleName, shRegion string) string {
-	region := shRegion
+// BuildConsoleUrl returns the console link for the finding, wrapped in an
+// access portal deep link when a portal and role are set.
+func (shf *SecurityHubV2Finding) BuildConsoleUrl(opts ConsoleLinkOptions) string {
+	// a console region wins, then the security hub region, then the
+	// finding's own region
+	region := opts.ConsoleRegion
+	if region == "" {
+		region = opts.SecurityHubRegion
+	}
 	if region == "" {
 		region = shf.Cloud.Region
 	}
 
 	var view string
-	findingType := shf.GetFindingCategory()
+	findingType := shf.FindingCategory(opts.CategoryMappings)
 
 	switch findingType {
 	case "Exposure":
@@ -298,14 +811,19 @@ func (shf *SecurityHubV2Finding) BuildConsoleUrl(consoleURL, accessPortalURL, ac
 	// example: https://console.aws.amazon.com/securityhub/v2/home?region=us-east-1#/postureManagement?findingDetailId=abc123...
 	dst := fmt.Sprintf(
 		"%s/securityhub/v2/home?region=%s#/%s?findingDetailId=%s",
-		consoleURL, region, view, shf.Metadata.UID,
+		opts.ConsoleURL, region, view, shf.Metadata.UID,
 	)
 
-	if accessPortalURL != "" && accessRoleName != "" {
+	accessRoleName := opts.AccessRoleName
+	if role, ok := opts.AccessRoleNames[shf.Cloud.Account.UID]; ok {
+		accessRoleName = role
+	}
+
+	if opts.AccessPortalURL != "" && accessRoleName != "" {
 		dstEncoded := url.QueryEscape(dst)
 		return fmt.Sprintf(
 			"%s/#/console?account_id=%s&role_name=%s&destination=%s",
-			accessPortalURL, shf.Cloud.Account.UID, accessRoleName, dstEncoded,
+			opts.AccessPortalURL, shf.Cloud.Account.UID, accessRoleName, dstEncoded,
 		)
 	}
 
@@ -5,15 +5,60 @@
 // - GuardDuty detection findings
 // - Security Hub CSPM compliance findings
 // - Alertability determination logic
+// - Slack message footer rendering
+// - Ticket reference rendered as a link when the rule has one
+// - Severity fallback from severity_id
+// - Primary resource access
+// - Remediation references rendered as capped link lists
+// - Resource deduplication by UID when collecting and rendering
+// - Product status normalization and its effect on alertability
+// - Category classification with default and reordered precedence
+// - Field lists split across sections at Slack's per-section limit
+// - Slack field selection and ordering
+// - Per-category field layouts with a fallback to the default fields
+// - Timestamps fall back to *_dt strings when the epoch is zero
+// - Per-account access portal roles in console links
+// - Console link region precedence: configured, passed, then finding region
+// - Compact resource list above the resource count threshold
+// - Block cap dropping low-priority blocks while keeping the essentials
+// - Severity ranking by name and id with default and custom orders
+// - Failed compliance checks raised to a severity floor without mutation
+// - Fingerprint stability and sensitivity to uid, modified time and status
+// - Field mappings normalizing a non-standard producer's title and severity
 package events
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
 )
 
+func loadTestFinding(t *testing.T, index int) *SecurityHubV2Finding {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+
+	var findings []json.RawMessage
+	if err := json.Unmarshal(raw, &findings); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+
+	finding, err := NewSecurityHubFinding(findings[index])
+	if err != nil {
+		t.Fatalf("failed to parse finding %d: %v", index, err)
+	}
+	return finding
+}
+
 // TestSecurityHubV2FindingParsing validates parsing of Security Hub v2 OCSF findings
 // from fixtures/samples.json, including both detection and compliance finding types.
 func TestSecurityHubV2FindingParsing(t *testing.T) {
@@ -70,3 +115,854 @@ func TestSecurityHubV2FindingParsing(t *testing.T) {
 		t.Error("Failed compliance finding should be alertable")
 	}
 }
+
+// TestSlackBlocks_Ticket validates that a matched rule's ticket renders as a
+// context block before the footer, linked when it has a url.
+func TestSlackBlocks_Ticket(t *testing.T) {
+	finding := &SecurityHubV2Finding{Severity: "High"}
+
+	ticketText := func(opts SlackMessageOptions) string {
+		for _, block := range finding.SlackBlocks(opts) {
+			if context, ok := block.(*slack.ContextBlock); ok && context.BlockID == "ticket" {
+				return context.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+			}
+		}
+		return ""
+	}
+
+	if got := ticketText(SlackMessageOptions{}); got != "" {
+		t.Errorf("expected no ticket block without a ticket, got %q", got)
+	}
+	if got := ticketText(SlackMessageOptions{Ticket: "SEC-123"}); got != "*Ticket:* SEC-123" {
+		t.Errorf("unexpected unlinked ticket: %q", got)
+	}
+
+	opts := SlackMessageOptions{Ticket: "SEC-123", TicketURL: "https://jira.example.com/browse/SEC-123", Footer: "footer"}
+	if got := ticketText(opts); got != "*Ticket:* <https://jira.example.com/browse/SEC-123|SEC-123>" {
+		t.Errorf("unexpected linked ticket: %q", got)
+	}
+	blocks := finding.SlackBlocks(opts)
+	if last := blocks[len(blocks)-1].(*slack.ContextBlock); last.BlockID != "footer" {
+		t.Errorf("expected the footer after the ticket, got %s", last.BlockID)
+	}
+}
+
+// TestSlackBlocks_Footer validates that a configured footer renders as a
+// trailing context block and is omitted when empty.
+func TestSlackBlocks_Footer(t *testing.T) {
+	finding := &SecurityHubV2Finding{Severity: "High"}
+	finding.FindingInfo.Title = "Test finding"
+
+	blocks := finding.SlackBlocks(SlackMessageOptions{})
+	for _, block := range blocks {
+		if block.BlockType() == slack.MBTContext {
+			t.Fatal("expected no context block without footer")
+		}
+	}
+
+	footer := "<https://runbooks.example.com|Runbooks> • <#C0SUPPORT>"
+	blocks = finding.SlackBlocks(SlackMessageOptions{Footer: footer})
+
+	last, ok := blocks[len(blocks)-1].(*slack.ContextBlock)
+	if !ok {
+		t.Fatalf("expected last block to be a context block, got %T", blocks[len(blocks)-1])
+	}
+
+	if len(last.ContextElements.Elements) != 1 {
+		t.Fatalf("expected 1 context element, got %d", len(last.ContextElements.Elements))
+	}
+
+	text, ok := last.ContextElements.Elements[0].(*slack.TextBlockObject)
+	if !ok {
+		t.Fatalf("expected text element, got %T", last.ContextElements.Elements[0])
+	}
+
+	if text.Type != "mrkdwn" || text.Text != footer {
+		t.Errorf("unexpected footer element: %s %q", text.Type, text.Text)
+	}
+}
+
+// TestNewSecurityHubFinding_SeverityFromID validates that a finding with only
+// severity_id gets the canonical severity string, which drives alertability
+// and the severity emoji.
+func TestNewSecurityHubFinding_SeverityFromID(t *testing.T) {
+	raw := []byte(`{"metadata": {"uid": "finding-1"}, "severity_id": 4, "status": "New"}`)
+
+	finding, err := NewSecurityHubFinding(raw)
+	if err != nil {
+		t.Fatalf("failed to parse finding: %v", err)
+	}
+
+	if finding.Severity != "High" {
+		t.Errorf("expected High severity, got %q", finding.Severity)
+	}
+
+	if !finding.IsAlertable() {
+		t.Error("High severity finding should be alertable")
+	}
+
+	if finding.GetSeverityEmoji() != "🟠" {
+		t.Errorf("expected High severity emoji, got %s", finding.GetSeverityEmoji())
+	}
+}
+
+// TestNewSecurityHubFinding_SeverityStringPreferred validates that an explicit
+// severity string is kept even if severity_id disagrees.
+func TestNewSecurityHubFinding_SeverityStringPreferred(t *testing.T) {
+	raw := []byte(`{"severity": "Low", "severity_id": 5}`)
+
+	finding, err := NewSecurityHubFinding(raw)
+	if err != nil {
+		t.Fatalf("failed to parse finding: %v", err)
+	}
+
+	if finding.Severity != "Low" {
+		t.Errorf("expected Low severity, got %q", finding.Severity)
+	}
+}
+
+// TestSeverityName validates the OCSF severity_id scale.
+func TestSeverityName(t *testing.T) {
+	expected := map[int]string{
+		0:  "Unknown",
+		1:  "Informational",
+		2:  "Low",
+		3:  "Medium",
+		4:  "High",
+		5:  "Critical",
+		6:  "Fatal",
+		99: "Other",
+	}
+
+	for id, name := range expected {
+		if got := SeverityName(id); got != name {
+			t.Errorf("severity_id %d: expected %s, got %s", id, name, got)
+		}
+	}
+}
+
+// TestPrimaryResource validates the primary resource accessor with zero and
+// multiple resources, and that Slack blocks render without resources.
+func TestPrimaryResource(t *testing.T) {
+	finding := &SecurityHubV2Finding{Severity: "High"}
+
+	if _, ok := finding.PrimaryResource(); ok {
+		t.Error("expected no primary resource")
+	}
+
+	if blocks := finding.SlackBlocks(SlackMessageOptions{}); len(blocks) == 0 {
+		t.Error("expected blocks for finding without resources")
+	}
+
+	finding.Resources = []OCSFResource{
+		{Type: "AWS::Container::Container", UID: "container-1"},
+		{Type: "AWS::EC2::Instance", UID: "i-0123456789"},
+	}
+
+	resource, ok := finding.PrimaryResource()
+	if !ok {
+		t.Fatal("expected primary resource")
+	}
+
+	if resource.UID != "container-1" {
+		t.Errorf("expected first resource, got %s", resource.UID)
+	}
+}
+
+// TestFindingCategory validates category precedence for a finding type that
+// contains both "Threats" and "Exposure".
+func TestFindingCategory(t *testing.T) {
+	finding := &SecurityHubV2Finding{CategoryName: "Findings"}
+	finding.FindingInfo.Types = []string{"Exposure/Threats/Unusual Behaviors"}
+
+	if got := finding.GetFindingCategory(); got != "Threats" {
+		t.Errorf("expected default precedence to pick Threats, got %s", got)
+	}
+
+	reordered := []CategoryMapping{
+		{Substring: "Exposure", Category: "Exposure"},
+		{Substring: "Threats", Category: "Threats"},
+	}
+
+	if got := finding.FindingCategory(reordered); got != "Exposure" {
+		t.Errorf("expected reordered precedence to pick Exposure, got %s", got)
+	}
+
+	if url := finding.BuildConsoleUrl(ConsoleLinkOptions{
+		ConsoleURL:        "https://console.aws.amazon.com",
+		SecurityHubRegion: "us-east-1",
+		CategoryMappings:  reordered,
+	}); !strings.Contains(url, "#/exposure?") {
+		t.Errorf("expected exposure view in console url, got %s", url)
+	}
+
+	if got := finding.FindingCategory([]CategoryMapping{{Substring: "Software", Category: "Software"}}); got != "Findings" {
+		t.Errorf("expected fallback to category name, got %s", got)
+	}
+}
+
+// TestSlackBlocks_RemediationReferences validates that every reference up to
+// the cap is rendered as a link and that the section is omitted without any.
+func TestSlackBlocks_RemediationReferences(t *testing.T) {
+	remediationText := func(blocks []slack.Block) string {
+		for _, block := range blocks {
+			section, ok := block.(*slack.SectionBlock)
+			if ok && section.Text != nil && strings.HasPrefix(section.Text.Text, "*Remediation*") {
+				return section.Text.Text
+			}
+		}
+		return ""
+	}
+
+	finding := &SecurityHubV2Finding{Severity: "High"}
+	if text := remediationText(finding.SlackBlocks(SlackMessageOptions{})); text != "" {
+		t.Errorf("expected no remediation section without remediation, got %q", text)
+	}
+
+	finding.Remediation = &Remediation{Desc: "Rotate the credentials."}
+	if text := remediationText(finding.SlackBlocks(SlackMessageOptions{})); text != "" {
+		t.Errorf("expected no remediation section without references, got %q", text)
+	}
+
+	finding.Remediation.References = []string{
+		"https://docs.aws.amazon.com/a",
+		"https://docs.aws.amazon.com/b",
+		"https://docs.aws.amazon.com/c",
+	}
+
+	text := remediationText(finding.SlackBlocks(SlackMessageOptions{}))
+	if !strings.Contains(text, "Rotate the credentials.") {
+		t.Errorf("expected remediation desc, got %q", text)
+	}
+
+	for _, ref := range finding.Remediation.References {
+		if !strings.Contains(text, "• <"+ref+">") {
+			t.Errorf("expected link for %s, got %q", ref, text)
+		}
+	}
+
+	var refs []string
+	for i := 0; i < MaxRemediationReferences+2; i++ {
+		refs = append(refs, fmt.Sprintf("https://docs.aws.amazon.com/%d", i))
+	}
+	finding.Remediation.References = refs
+
+	text = remediationText(finding.SlackBlocks(SlackMessageOptions{}))
+	if got := strings.Count(text, "• <"); got != MaxRemediationReferences {
+		t.Errorf("expected %d links, got %d", MaxRemediationReferences, got)
+	}
+
+	if strings.Contains(text, refs[MaxRemediationReferences]) {
+		t.Errorf("expected references past the cap to be omitted, got %q", text)
+	}
+
+	if !strings.Contains(text, "and 2 more") {
+		t.Errorf("expected overflow note, got %q", text)
+	}
+}
+
+// TestUniqueResources validates that resources repeated by UID are collected
+// and rendered once.
+func TestUniqueResources(t *testing.T) {
+	finding := &SecurityHubV2Finding{Severity: "High"}
+	finding.Resources = []OCSFResource{
+		{Type: "AWS::EC2::Instance", UID: "i-0123456789"},
+		{Type: "AWS::IAM::Role", UID: "arn:aws:iam::123456789012:role/runner"},
+		{Type: "AWS::EC2::Instance", UID: "i-0123456789"},
+		{Type: "AWS::IAM::Role", UID: "arn:aws:iam::123456789012:role/runner"},
+	}
+
+	if got := finding.UniqueResources(); len(got) != 2 {
+		t.Fatalf("expected 2 unique resources, got %d", len(got))
+	}
+
+	uids := finding.ResourceUIDs()
+	if strings.Join(uids, ",") != "i-0123456789,arn:aws:iam::123456789012:role/runner" {
+		t.Errorf("unexpected resource uids: %v", uids)
+	}
+
+	var rendered string
+	for _, block := range finding.SlackBlocks(SlackMessageOptions{}) {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil && strings.HasPrefix(section.Text.Text, "*Additional Resources*") {
+			rendered = section.Text.Text
+		}
+	}
+
+	if strings.Count(rendered, "role/runner") != 1 {
+		t.Errorf("expected the duplicate role once, got %q", rendered)
+	}
+
+	if strings.Contains(rendered, "i-0123456789") {
+		t.Errorf("expected the primary resource not to repeat, got %q", rendered)
+	}
+
+	finding.Resources = finding.Resources[:1]
+	for _, block := range finding.SlackBlocks(SlackMessageOptions{}) {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil && strings.HasPrefix(section.Text.Text, "*Additional Resources*") {
+			t.Errorf("expected no additional resources section for a single resource")
+		}
+	}
+}
+
+// TestNormalizeStatus validates that a remapped product status changes the
+// canonical status and alertability, and that unmapped statuses are kept.
+func TestNormalizeStatus(t *testing.T) {
+	finding := &SecurityHubV2Finding{Severity: "High", Status: "Open", StatusID: 99}
+
+	finding.NormalizeStatus(nil)
+	if finding.Status != "Open" || finding.IsAlertable() {
+		t.Fatalf("expected identity without mappings, got %s (alertable=%v)", finding.Status, finding.IsAlertable())
+	}
+
+	finding.NormalizeStatus(map[string]int{"Open": 1})
+	if finding.Status != "New" || finding.StatusID != 1 {
+		t.Errorf("expected New (1), got %s (%d)", finding.Status, finding.StatusID)
+	}
+
+	if !finding.IsAlertable() {
+		t.Error("expected remapped finding to be alertable")
+	}
+
+	finding.Status = "Dismissed"
+	finding.NormalizeStatus(map[string]int{"Open": 1, "Dismissed": 3})
+	if finding.Status != "Suppressed" || finding.StatusID != 3 || finding.IsAlertable() {
+		t.Errorf("expected Suppressed (3) and not alertable, got %s (%d)", finding.Status, finding.StatusID)
+	}
+}
+
+// TestFieldSections validates that fields beyond Slack's per-section limit
+// overflow into additional section blocks, in order.
+func TestFieldSections(t *testing.T) {
+	var fields []*slack.TextBlockObject
+	for i := range MaxSectionFields + 3 {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Field %d*\nvalue", i), false, false))
+	}
+
+	blocks := fieldSections(fields)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(blocks))
+	}
+
+	first, second := blocks[0].(*slack.SectionBlock), blocks[1].(*slack.SectionBlock)
+	if len(first.Fields) != MaxSectionFields || len(second.Fields) != 3 {
+		t.Errorf("expected %d and 3 fields, got %d and %d", MaxSectionFields, len(first.Fields), len(second.Fields))
+	}
+	if second.Fields[0].Text != fmt.Sprintf("*Field %d*\nvalue", MaxSectionFields) {
+		t.Errorf("expected overflow to continue in order, got %q", second.Fields[0].Text)
+	}
+
+	if blocks := fieldSections(fields[:4]); len(blocks) != 1 {
+		t.Errorf("expected 1 section for 4 fields, got %d", len(blocks))
+	}
+	if blocks := fieldSections(nil); len(blocks) != 0 {
+		t.Errorf("expected no sections without fields, got %d", len(blocks))
+	}
+}
+
+// TestTimestamp validates that epoch milliseconds are preferred and the
+// RFC3339 *_dt strings are used when the epoch is zero.
+func TestTimestamp(t *testing.T) {
+	raw := `{
+		"time_dt": "2025-10-03T20:37:13.911Z",
+		"finding_info": {
+			"first_seen_time_dt": "2025-10-01T08:00:00Z",
+			"last_seen_time": 1759523833911,
+			"last_seen_time_dt": "2000-01-01T00:00:00Z",
+			"created_time_dt": "not a time"
+		}
+	}`
+	finding, err := NewSecurityHubFinding([]byte(raw))
+	if err != nil {
+		t.Fatalf("failed to parse finding: %v", err)
+	}
+
+	tests := []struct {
+		field    string
+		expected time.Time
+	}{
+		{TimestampEvent, time.Date(2025, 10, 3, 20, 37, 13, 911000000, time.UTC)},
+		{TimestampFirstSeen, time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{TimestampLastSeen, time.UnixMilli(1759523833911)},
+		{TimestampCreated, time.Time{}},
+		{TimestampModified, time.Time{}},
+		{"unknown", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := finding.Timestamp(tt.field); !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSlackBlocks_Fields validates that only the selected fields are
+// rendered, in order, alongside the header and console button.
+func TestSlackBlocks_Fields(t *testing.T) {
+	finding := loadTestFinding(t, 1)
+
+	full := finding.SlackBlocks(SlackMessageOptions{})
+	if defaults := finding.SlackBlocks(SlackMessageOptions{Fields: DefaultSlackFields}); len(defaults) != len(full) {
+		t.Errorf("expected default fields to render the full message, got %d and %d blocks", len(defaults), len(full))
+	}
+
+	blocks := finding.SlackBlocks(SlackMessageOptions{Fields: []string{SlackFieldAccount, SlackFieldSeverity}})
+	if len(blocks) != 3 {
+		t.Fatalf("expected header, details and actions, got %d blocks", len(blocks))
+	}
+
+	if _, ok := blocks[0].(*slack.HeaderBlock); !ok {
+		t.Errorf("expected header block first, got %T", blocks[0])
+	}
+
+	section, ok := blocks[1].(*slack.SectionBlock)
+	if !ok || len(section.Fields) != 2 {
+		t.Fatalf("expected details section with 2 fields, got %#v", blocks[1])
+	}
+	if !strings.HasPrefix(section.Fields[0].Text, "*Account*") || !strings.HasPrefix(section.Fields[1].Text, "*Severity*") {
+		t.Errorf("expected account then severity, got %q and %q", section.Fields[0].Text, section.Fields[1].Text)
+	}
+
+	if _, ok := blocks[2].(*slack.ActionBlock); !ok {
+		t.Errorf("expected console button last, got %T", blocks[2])
+	}
+}
+
+// TestSlackBlocks_CategoryFields validates that findings render with their
+// category's layout, and other categories fall back to the default fields.
+func TestSlackBlocks_CategoryFields(t *testing.T) {
+	opts := SlackMessageOptions{
+		Fields: []string{SlackFieldSeverity},
+		CategoryFields: map[string][]string{
+			"Posture Management": {SlackFieldCompliance, SlackFieldSeverity},
+			"Threats":            {SlackFieldResource, SlackFieldRemediation},
+		},
+	}
+
+	// first field text of the first section after the header
+	firstField := func(blocks []slack.Block) string {
+		t.Helper()
+		section, ok := blocks[1].(*slack.SectionBlock)
+		if !ok {
+			t.Fatalf("expected a section after the header, got %T", blocks[1])
+		}
+		if section.Text != nil {
+			return section.Text.Text
+		}
+		return section.Fields[0].Text
+	}
+
+	compliance := loadTestFinding(t, 1)
+	blocks := compliance.SlackBlocks(opts)
+	section, ok := blocks[1].(*slack.SectionBlock)
+	if !ok || len(section.Fields) != 2 || !strings.HasPrefix(section.Fields[0].Text, "*Standards*") || section.Fields[1].Text != "*Control*\n`Config.1`" {
+		t.Errorf("expected standards and control first for a compliance finding, got %#v", blocks[1])
+	}
+	if got := firstField(blocks[1:]); !strings.HasPrefix(got, "*Severity*") {
+		t.Errorf("expected severity after compliance, got %q", got)
+	}
+
+	threat := loadTestFinding(t, 0)
+	if got := firstField(threat.SlackBlocks(opts)); !strings.HasPrefix(got, "*Resource Type*") {
+		t.Errorf("expected resource first for a threat, got %q", got)
+	}
+
+	other := &SecurityHubV2Finding{Severity: "Low", FindingInfo: FindingInfo{Types: []string{"Software and Configuration Checks"}}}
+	blocks = other.SlackBlocks(opts)
+	if got := firstField(blocks); !strings.HasPrefix(got, "*Severity*") || len(blocks) != 3 {
+		t.Errorf("expected the default fields for an unmapped category, got %q in %d blocks", got, len(blocks))
+	}
+}
+
+// TestBuildConsoleUrl_AccessRoleNames validates that the access portal link
+// uses the account's role override and falls back to the default role.
+func TestBuildConsoleUrl_AccessRoleNames(t *testing.T) {
+	finding := loadTestFinding(t, 0)
+	roles := map[string]string{"123456789012": "ProdSecurityAuditor"}
+
+	tests := []struct {
+		name     string
+		account  string
+		role     string
+		expected string
+	}{
+		{"account override", "123456789012", "SecurityAuditor", "role_name=ProdSecurityAuditor"},
+		{"default role", "210987654321", "SecurityAuditor", "role_name=SecurityAuditor"},
+		{"override without default", "123456789012", "", "role_name=ProdSecurityAuditor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding.Cloud.Account.UID = tt.account
+			url := finding.BuildConsoleUrl(ConsoleLinkOptions{
+				ConsoleURL:        "https://console.aws.amazon.com",
+				AccessPortalURL:   "https://portal.example.com",
+				AccessRoleName:    tt.role,
+				AccessRoleNames:   roles,
+				SecurityHubRegion: "us-east-1",
+			})
+			if !strings.Contains(url, "account_id="+tt.account+"&"+tt.expected+"&") {
+				t.Errorf("expected %s for account %s, got %s", tt.expected, tt.account, url)
+			}
+		})
+	}
+
+	finding.Cloud.Account.UID = "210987654321"
+	url := finding.BuildConsoleUrl(ConsoleLinkOptions{
+		ConsoleURL:        "https://console.aws.amazon.com",
+		AccessPortalURL:   "https://portal.example.com",
+		AccessRoleNames:   roles,
+		SecurityHubRegion: "us-east-1",
+	})
+	if strings.Contains(url, "portal.example.com") {
+		t.Errorf("expected a direct console link without a role, got %s", url)
+	}
+}
+
+// TestBuildConsoleUrl_RegionPrecedence validates that the configured console
+// region wins over the passed region, which wins over the finding's region.
+func TestBuildConsoleUrl_RegionPrecedence(t *testing.T) {
+	finding := &SecurityHubV2Finding{}
+	finding.Cloud.Region = "ap-southeast-2"
+
+	tests := []struct {
+		name          string
+		consoleRegion string
+		shRegion      string
+		expected      string
+	}{
+		{"configured console region", "us-west-2", "eu-west-1", "region=us-west-2"},
+		{"passed region", "", "eu-west-1", "region=eu-west-1"},
+		{"finding region", "", "", "region=ap-southeast-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := finding.BuildConsoleUrl(ConsoleLinkOptions{
+				ConsoleURL:        "https://console.aws.amazon.com",
+				ConsoleRegion:     tt.consoleRegion,
+				SecurityHubRegion: tt.shRegion,
+			})
+			if !strings.Contains(url, tt.expected+"#") {
+				t.Errorf("expected %s, got %s", tt.expected, url)
+			}
+		})
+	}
+}
+
+// TestSlackBlocks_CompactResources validates that findings with more than
+// CompactResourcesThreshold resources switch from per-resource fields to a
+// single truncated list of UIDs.
+func TestSlackBlocks_CompactResources(t *testing.T) {
+	withResources := func(n int) *SecurityHubV2Finding {
+		finding := &SecurityHubV2Finding{Severity: "High"}
+		for i := range n {
+			finding.Resources = append(finding.Resources, OCSFResource{
+				Type: "AWS::EC2::Instance",
+				UID:  fmt.Sprintf("arn:aws:ec2:us-east-1:123456789012:instance/i-%04d", i),
+			})
+		}
+		return finding
+	}
+
+	sectionTexts := func(finding *SecurityHubV2Finding) []string {
+		var texts []string
+		for _, block := range finding.SlackBlocks(SlackMessageOptions{Fields: []string{SlackFieldResource}}) {
+			section, ok := block.(*slack.SectionBlock)
+			if !ok {
+				continue
+			}
+			if section.Text != nil {
+				texts = append(texts, section.Text.Text)
+			}
+			for _, field := range section.Fields {
+				texts = append(texts, field.Text)
+			}
+		}
+		return texts
+	}
+
+	texts := strings.Join(sectionTexts(withResources(CompactResourcesThreshold)), "\n")
+	if !strings.Contains(texts, "*Resource Type*") || !strings.Contains(texts, "*Additional Resources*") || strings.Contains(texts, "*Resources (") {
+		t.Errorf("expected per-resource fields at the threshold, got %q", texts)
+	}
+
+	texts = strings.Join(sectionTexts(withResources(CompactResourcesThreshold+1)), "\n")
+	if strings.Contains(texts, "*Resource Type*") || !strings.Contains(texts, fmt.Sprintf("*Resources (%d)*", CompactResourcesThreshold+1)) {
+		t.Errorf("expected a compact list above the threshold, got %q", texts)
+	}
+	if strings.Contains(texts, "more_") {
+		t.Errorf("expected no truncation for a short list, got %q", texts)
+	}
+
+	total := MaxCompactResources + 10
+	compact := sectionTexts(withResources(total))
+	if len(compact) != 1 {
+		t.Fatalf("expected a single section, got %d", len(compact))
+	}
+	if got := strings.Count(compact[0], "arn:aws:ec2"); got != MaxCompactResources {
+		t.Errorf("expected %d listed resources, got %d", MaxCompactResources, got)
+	}
+	if !strings.HasSuffix(compact[0], "_and 10 more_") || len(compact[0]) > MaxSectionText {
+		t.Errorf("expected a truncated list within the section limit, got %q", compact[0])
+	}
+}
+
+// TestSlackBlocks_MaxBlocks validates that messages over the block cap drop
+// enrichments and then details, keeping the header, severity and console
+// button and ending with a truncation note.
+func TestSlackBlocks_MaxBlocks(t *testing.T) {
+	finding := &SecurityHubV2Finding{
+		Severity:    "High",
+		FindingInfo: FindingInfo{Title: "Test finding", Desc: "Test description"},
+		Remediation: &Remediation{Desc: "Fix it", References: []string{"https://example.com/fix"}},
+	}
+	finding.Metadata.UID = "finding-001"
+	for i := range 3 {
+		finding.Resources = append(finding.Resources, OCSFResource{
+			Type: "AWS::EC2::Instance",
+			UID:  fmt.Sprintf("arn:aws:ec2:us-east-1:123456789012:instance/i-%04d", i),
+		})
+	}
+	opts := SlackMessageOptions{
+		ConsoleLinkOptions: ConsoleLinkOptions{ConsoleURL: "https://console.aws.amazon.com"},
+		Footer:             "footer",
+		Ticket:             "SEC-1",
+	}
+
+	full := finding.SlackBlocks(opts)
+	if len(full) != 10 {
+		t.Fatalf("expected 10 uncapped blocks, got %d", len(full))
+	}
+
+	essentials := func(blocks []slack.Block) {
+		t.Helper()
+		if _, ok := blocks[0].(*slack.HeaderBlock); !ok {
+			t.Errorf("expected the header first, got %T", blocks[0])
+		}
+		var severity, button bool
+		for _, block := range blocks {
+			switch b := block.(type) {
+			case *slack.SectionBlock:
+				for _, field := range b.Fields {
+					severity = severity || strings.HasPrefix(field.Text, "*Severity*")
+				}
+			case *slack.ActionBlock:
+				button = true
+			}
+		}
+		if !severity || !button {
+			t.Errorf("expected severity and console button to survive, got severity=%t button=%t", severity, button)
+		}
+		note, ok := blocks[len(blocks)-1].(*slack.ContextBlock)
+		if !ok || note.BlockID != "truncated" {
+			t.Errorf("expected a truncation note last, got %T", blocks[len(blocks)-1])
+		}
+	}
+
+	opts.MaxBlocks = 5
+	capped := finding.SlackBlocks(opts)
+	if len(capped) != 5 {
+		t.Fatalf("expected 5 blocks, got %d", len(capped))
+	}
+	essentials(capped)
+	for _, block := range capped {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil && strings.Contains(section.Text.Text, "*Remediation*") {
+			t.Error("expected remediation to be dropped")
+		}
+		if context, ok := block.(*slack.ContextBlock); ok && context.BlockID == "footer" {
+			t.Error("expected the footer to be dropped before the description")
+		}
+	}
+
+	opts.MaxBlocks = 1
+	essentials(finding.SlackBlocks(opts))
+}
+
+// TestSeverityRanking validates ranking by severity string and severity_id,
+// and that a custom order changes which findings pass a minimum.
+func TestSeverityRanking(t *testing.T) {
+	ranking := DefaultSeverityRanking
+	if ranking.Rank("critical") <= ranking.Rank("High") || ranking.Rank("Low") <= ranking.Rank("Informational") {
+		t.Errorf("unexpected default ranks: %v", ranking)
+	}
+	if ranking.Rank("Other") != -1 || ranking.RankID(4) != ranking.Rank("High") {
+		t.Errorf("unexpected ranks for Other or severity_id 4")
+	}
+
+	low := &SecurityHubV2Finding{Severity: "Low", SeverityID: 2}
+	info := &SecurityHubV2Finding{Severity: "Informational", SeverityID: 1}
+	byID := &SecurityHubV2Finding{Severity: "Severe", SeverityID: 5}
+
+	if !ranking.AtLeast(low, "Informational") || ranking.AtLeast(info, "Low") {
+		t.Error("expected OCSF order to rank Low above Informational")
+	}
+	if !ranking.AtLeast(byID, "High") {
+		t.Error("expected unranked severity to fall back to severity_id")
+	}
+	if ranking.AtLeast(low, "Severe") {
+		t.Error("expected unranked minimum to pass nothing")
+	}
+
+	custom := SeverityRanking{"Unknown", "Low", "Informational", "Medium", "High", "Critical", "Fatal"}
+	if custom.AtLeast(low, "Informational") || !custom.AtLeast(info, "Low") {
+		t.Error("expected custom order to rank Informational above Low")
+	}
+
+	if got := strings.Join(custom.From("high"), ","); got != "High,Critical,Fatal" {
+		t.Errorf("unexpected severities from High: %s", got)
+	}
+	if custom.From("Severe") != nil {
+		t.Error("expected nil for an unranked minimum")
+	}
+}
+
+// TestSeverityRanking_EffectiveSeverity validates that only failed compliance
+// checks ranked below the floor are raised, and that the finding is unchanged.
+func TestSeverityRanking_EffectiveSeverity(t *testing.T) {
+	finding := func(severity, compliance string) *SecurityHubV2Finding {
+		f := &SecurityHubV2Finding{Severity: severity, SeverityID: SeverityID(severity)}
+		if compliance != "" {
+			f.Compliance = &OCSFCompliance{Status: compliance}
+		}
+		return f
+	}
+
+	tests := []struct {
+		name     string
+		finding  *SecurityHubV2Finding
+		floor    string
+		expected string
+	}{
+		{"failed informational raised", finding("Informational", "Fail"), "Medium", "Medium"},
+		{"failed high kept", finding("High", "Fail"), "Medium", "High"},
+		{"passed informational kept", finding("Informational", "Pass"), "Medium", "Informational"},
+		{"non-compliance kept", finding("Low", ""), "Medium", "Low"},
+		{"no floor", finding("Informational", "Fail"), "", "Informational"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.finding.Severity
+			if got := DefaultSeverityRanking.EffectiveSeverity(tt.finding, tt.floor); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+			if tt.finding.Severity != original {
+				t.Errorf("expected finding severity to stay %s, got %s", original, tt.finding.Severity)
+			}
+		})
+	}
+}
+
+// TestFingerprint validates that the fingerprint is stable for the same uid,
+// modified time and status, and changes when any of them does.
+func TestFingerprint(t *testing.T) {
+	base := `{"metadata": {"uid": "finding-1"}, "finding_info": {"modified_time": 1748779200000}, "severity": "High", "status_id": 1}`
+	parse := func(raw string) *SecurityHubV2Finding {
+		finding, err := NewSecurityHubFinding([]byte(raw))
+		if err != nil {
+			t.Fatalf("failed to parse finding: %v", err)
+		}
+		return finding
+	}
+
+	fingerprint := parse(base).Fingerprint()
+	if len(fingerprint) != 64 {
+		t.Fatalf("expected a sha256 hex fingerprint, got %q", fingerprint)
+	}
+	if again := parse(base).Fingerprint(); again != fingerprint {
+		t.Errorf("expected a stable fingerprint, got %s and %s", fingerprint, again)
+	}
+
+	// fields outside the fingerprint don't change it
+	if got := parse(strings.Replace(base, `"High"`, `"Low"`, 1)).Fingerprint(); got != fingerprint {
+		t.Error("expected severity not to change the fingerprint")
+	}
+
+	// the same instant as a *_dt string matches the epoch
+	dt := `{"metadata": {"uid": "finding-1"}, "finding_info": {"modified_time_dt": "2025-06-01T12:00:00Z"}, "status_id": 1}`
+	if got := parse(dt).Fingerprint(); got != fingerprint {
+		t.Error("expected modified_time_dt to fingerprint like the epoch")
+	}
+
+	changes := map[string]string{
+		"uid":           strings.Replace(base, "finding-1", "finding-2", 1),
+		"modified_time": strings.Replace(base, "1748779200000", "1748779260000", 1),
+		"status_id":     strings.Replace(base, `"status_id": 1`, `"status_id": 2`, 1),
+	}
+	for field, raw := range changes {
+		if got := parse(raw).Fingerprint(); got == fingerprint {
+			t.Errorf("expected a changed %s to change the fingerprint", field)
+		}
+	}
+}
+
+// TestNewSecurityHubFinding_FieldMappings validates that a quirky producer's
+// title and severity are normalized while other products are left as-is.
+func TestNewSecurityHubFinding_FieldMappings(t *testing.T) {
+	mappings := []FieldMapping{
+		{
+			Product:    "Acme Scanner",
+			TitlePath:  "unmapped.alerts[0].headline",
+			Severities: map[string]string{"sev1": "Critical", "sev2": "High"},
+		},
+		{
+			Product:      "Scored Scanner",
+			SeverityPath: "unmapped.score",
+			Severities:   map[string]string{"9.5": "Critical"},
+		},
+	}
+
+	quirky := `{"metadata": {"uid": "acme-1", "product": {"name": "Acme Scanner"}}, "severity": "SEV1", "status": "New", "unmapped": {"alerts": [{"headline": "Exposed admin panel"}]}}`
+	finding, err := NewSecurityHubFinding([]byte(quirky), mappings...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finding.FindingInfo.Title != "Exposed admin panel" {
+		t.Errorf("expected title from the alternate path, got %q", finding.FindingInfo.Title)
+	}
+	if finding.Severity != "Critical" || finding.SeverityID != 5 {
+		t.Errorf("expected Critical (5), got %s (%d)", finding.Severity, finding.SeverityID)
+	}
+	if !finding.IsAlertable() {
+		t.Error("expected the normalized finding to be alertable")
+	}
+
+	// a title already set is kept
+	titled := strings.Replace(quirky, `"severity"`, `"finding_info": {"title": "Original"}, "severity"`, 1)
+	if finding, _ := NewSecurityHubFinding([]byte(titled), mappings...); finding.FindingInfo.Title != "Original" {
+		t.Errorf("expected the existing title to be kept, got %q", finding.FindingInfo.Title)
+	}
+
+	// unmapped values and missing paths are left as-is
+	unmapped := strings.Replace(quirky, "SEV1", "sev9", 1)
+	unmapped = strings.Replace(unmapped, "alerts", "other", 1)
+	finding, _ = NewSecurityHubFinding([]byte(unmapped), mappings...)
+	if finding.Severity != "sev9" || finding.FindingInfo.Title != "" {
+		t.Errorf("expected unmapped fields to be left as-is, got %q %q", finding.Severity, finding.FindingInfo.Title)
+	}
+
+	scored := `{"metadata": {"product": {"name": "Scored Scanner"}}, "unmapped": {"score": 9.5}}`
+	if finding, _ := NewSecurityHubFinding([]byte(scored), mappings...); finding.Severity != "Critical" {
+		t.Errorf("expected numeric severity path to map to Critical, got %s", finding.Severity)
+	}
+
+	// aws-native findings don't match any mapping
+	native := loadTestFinding(t, 0)
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+	var samples []json.RawMessage
+	if err := json.Unmarshal(raw, &samples); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+	mapped, err := NewSecurityHubFinding(samples[0], append(mappings, FieldMapping{Product: "Acme", TitlePath: "metadata.uid"})...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapped.Severity != native.Severity || mapped.FindingInfo.Title != native.FindingInfo.Title {
+		t.Errorf("expected the GuardDuty finding to be unaffected, got %s %q", mapped.Severity, mapped.FindingInfo.Title)
+	}
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// FieldMapping normalizes findings from a producer that doesn't follow the
+// OCSF layout Security Hub uses for AWS products. it only applies to findings
+// whose metadata.product.name equals Product, so other producers are left
+// untouched.
+type FieldMapping struct {
+	Product string `json:"product"`
+
+	// TitlePath fills finding_info.title from another field when it's empty,
+	// e.g. "finding_info.desc" or "unmapped.headline"
+	TitlePath string `json:"title_path,omitempty"`
+
+	// SeverityPath reads the producer's severity from another field instead
+	// of severity
+	SeverityPath string `json:"severity_path,omitempty"`
+
+	// Severities maps the producer's severity values, ignoring case, to
+	// canonical OCSF severity names
+	Severities map[string]string `json:"severities,omitempty"`
+}
+
+// applies reports whether the mapping is for the finding's product.
+func (m FieldMapping) applies(finding *SecurityHubV2Finding) bool {
+	return m.Product != "" && m.Product == finding.Metadata.Product.Name
+}
+
+// apply rewrites the finding's title and severity from doc, the finding's raw
+// json decoded once by the caller.
+func (m FieldMapping) apply(finding *SecurityHubV2Finding, doc any) {
+	if m.TitlePath != "" && finding.FindingInfo.Title == "" {
+		if title, ok := lookupPath(doc, m.TitlePath).(string); ok {
+			finding.FindingInfo.Title = title
+		}
+	}
+
+	severity := finding.Severity
+	if m.SeverityPath != "" {
+		severity = scalarString(lookupPath(doc, m.SeverityPath))
+	}
+	for value, canonical := range m.Severities {
+		if strings.EqualFold(value, severity) {
+			finding.Severity = canonical
+			finding.SeverityID = SeverityID(canonical)
+			return
+		}
+	}
+	if m.SeverityPath != "" && severity != "" {
+		finding.Severity = severity
+	}
+}
+
+// applyFieldMappings applies the first mapping for the finding's product.
+func applyFieldMappings(finding *SecurityHubV2Finding, mappings []FieldMapping) error {
+	for _, m := range mappings {
+		if !m.applies(finding) {
+			continue
+		}
+		var doc any
+		if err := json.Unmarshal(finding.Raw, &doc); err != nil {
+			return err
+		}
+		m.apply(finding, doc)
+		return nil
+	}
+	return nil
+}
+
+// lookupPath returns the value at a dotted path such as "a.b[0].c", or nil
+// when any segment is missing.
+func lookupPath(doc any, path string) any {
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		name, index, hasIndex := strings.Cut(segment, "[")
+		if name != "" {
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil
+			}
+			current = obj[name]
+		}
+		if hasIndex {
+			i, err := strconv.Atoi(strings.TrimSuffix(index, "]"))
+			items, ok := current.([]any)
+			if err != nil || !ok || i < 0 || i >= len(items) {
+				return nil
+			}
+			current = items[i]
+		}
+	}
+	return current
+}
+
+// scalarString formats a string or number found at a path, so numeric
+// producer severities such as 8.5 can be mapped too.
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,67 @@
+package events
+
+import "strings"
+
+// SeverityRanking orders severity names from least to most severe. it backs
+// every severity comparison so an org can reorder the scale in one place.
+type SeverityRanking []string
+
+// DefaultSeverityRanking is the OCSF severity_id scale. Other is left out since
+// its id says nothing about how severe the finding is.
+var DefaultSeverityRanking = SeverityRanking{"Unknown", "Informational", "Low", "Medium", "High", "Critical", "Fatal"}
+
+// Rank returns the position of severity in the ranking, ignoring case, or -1
+// when it isn't ranked.
+func (r SeverityRanking) Rank(severity string) int {
+	for i, s := range r {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RankID ranks an OCSF severity_id by its canonical name.
+func (r SeverityRanking) RankID(severityID int) int {
+	return r.Rank(SeverityName(severityID))
+}
+
+// FindingRank ranks the finding's severity, falling back to its severity_id
+// when the severity string isn't ranked.
+func (r SeverityRanking) FindingRank(finding *SecurityHubV2Finding) int {
+	if rank := r.Rank(finding.Severity); rank >= 0 {
+		return rank
+	}
+	return r.RankID(finding.SeverityID)
+}
+
+// AtLeast reports whether the finding ranks at or above minSeverity. nothing
+// passes an unranked minimum, and unranked findings never pass.
+func (r SeverityRanking) AtLeast(finding *SecurityHubV2Finding, minSeverity string) bool {
+	minRank := r.Rank(minSeverity)
+	return minRank >= 0 && r.FindingRank(finding) >= minRank
+}
+
+// From returns the severities ranked at or above minSeverity, least severe
+// first, or nil when minSeverity isn't ranked.
+func (r SeverityRanking) From(minSeverity string) []string {
+	minRank := r.Rank(minSeverity)
+	if minRank < 0 {
+		return nil
+	}
+	return append([]string(nil), r[minRank:]...)
+}
+
+// EffectiveSeverity returns the severity a finding is alerted and routed by. a
+// failed compliance check ranked below failFloor is raised to it, so failing
+// controls reported as Informational still reach the right channel. the
+// finding itself is left unchanged.
+func (r SeverityRanking) EffectiveSeverity(finding *SecurityHubV2Finding, failFloor string) string {
+	if failFloor == "" || !finding.IsFailedCompliance() {
+		return finding.Severity
+	}
+	if r.FindingRank(finding) < r.Rank(failFloor) {
+		return failFloor
+	}
+	return finding.Severity
+}
@@ -0,0 +1,92 @@
+package filters
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+const (
+	AgeBasisFirstSeen = events.TimestampFirstSeen
+	AgeBasisLastSeen  = events.TimestampLastSeen
+	AgeBasisCreated   = events.TimestampCreated
+	AgeBasisModified  = events.TimestampModified
+)
+
+// Age is a duration written as a Go duration string (e.g. "36h") or a whole
+// number of days (e.g. "7d").
+type Age time.Duration
+
+func (a *Age) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Wrap(err, "age must be a string such as \"72h\" or \"7d\"")
+	}
+
+	d, err := parseAge(raw)
+	if err != nil {
+		return err
+	}
+	*a = Age(d)
+	return nil
+}
+
+func (a Age) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(a).String())
+}
+
+func parseAge(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.Newf("invalid age %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, errors.Newf("invalid age %q", raw)
+	}
+	return d, nil
+}
+
+func validAgeBasis(basis string) bool {
+	switch basis {
+	case "", AgeBasisFirstSeen, AgeBasisLastSeen, AgeBasisCreated, AgeBasisModified:
+		return true
+	}
+	return false
+}
+
+// findingTime returns the timestamp the age is measured from, defaulting to
+// first seen. the zero time means the finding has no such timestamp.
+func findingTime(finding *events.SecurityHubV2Finding, basis string) time.Time {
+	if basis == "" {
+		basis = AgeBasisFirstSeen
+	}
+	return finding.Timestamp(basis)
+}
+
+// matchesAge checks the finding's age against min_age and max_age. findings
+// without the basis timestamp never match.
+func matchesAge(finding *events.SecurityHubV2Finding, filters RuleFilters, now time.Time) bool {
+	ts := findingTime(finding, filters.AgeBasis)
+	if ts.IsZero() {
+		return false
+	}
+
+	age := now.Sub(ts)
+	if filters.MinAge > 0 && age < time.Duration(filters.MinAge) {
+		return false
+	}
+	if filters.MaxAge > 0 && age > time.Duration(filters.MaxAge) {
+		return false
+	}
+	return true
+}
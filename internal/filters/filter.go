@@ -1,58 +1,226 @@
 package filters
 
 import (
+	"slices"
+	"time"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/clock"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 )
 
+// FindingHistory reports whether a finding uid was processed before now, such
+// as a recent.Cache of processed uids.
+type FindingHistory interface {
+	Seen(key string, now time.Time) bool
+}
+
 type FilterEngine struct {
 	Rules []AutoCloseRule
+
+	// History backs the recurrence filter. without it every finding is new
+	History FindingHistory
+
+	// Clock is the time source for age and recurrence filters
+	Clock clock.Clock
+
+	// rule indexes by account, built once at construction. rules without an
+	// accounts filter apply to every account.
+	byAccount  map[string][]int
+	anyAccount []int
 }
 
 func NewFilterEngine(rules []AutoCloseRule) *FilterEngine {
-	return &FilterEngine{Rules: rules}
+	e := &FilterEngine{Rules: rules, Clock: clock.Real{}, byAccount: map[string][]int{}}
+	for i := range rules {
+		accounts := rules[i].Filters.Accounts
+		if len(accounts) == 0 {
+			e.anyAccount = append(e.anyAccount, i)
+			continue
+		}
+		for _, account := range accounts {
+			idx := e.byAccount[account]
+			if len(idx) > 0 && idx[len(idx)-1] == i {
+				continue
+			}
+			e.byAccount[account] = append(idx, i)
+		}
+	}
+	return e
 }
 
+// FindMatchingRule returns the first enabled rule matching the finding. only
+// rules for the finding's account and account-agnostic rules are evaluated,
+// in the same order as Rules.
 func (e *FilterEngine) FindMatchingRule(finding *events.SecurityHubV2Finding) (*AutoCloseRule, bool) {
-	for i := range e.Rules {
+	ev := e.evaluation(finding)
+	scoped := e.byAccount[finding.Cloud.Account.UID]
+	agnostic := e.anyAccount
+
+	for len(scoped) > 0 || len(agnostic) > 0 {
+		var i int
+		if len(agnostic) == 0 || (len(scoped) > 0 && scoped[0] < agnostic[0]) {
+			i, scoped = scoped[0], scoped[1:]
+		} else {
+			i, agnostic = agnostic[0], agnostic[1:]
+		}
+
 		rule := &e.Rules[i]
 		if !rule.Enabled {
 			continue
 		}
-		if e.matchesFilters(finding, rule.Filters) {
+		if matchesFilters(finding, rule.Filters, ev) {
 			return rule, true
 		}
 	}
 	return nil, false
 }
 
-func (e *FilterEngine) matchesFilters(finding *events.SecurityHubV2Finding, filters RuleFilters) bool {
+// RuleResult explains how a single rule evaluated against a finding.
+type RuleResult struct {
+	Rule    *AutoCloseRule
+	Matched bool
+	// Reason is "disabled" or the name of the first failed filter
+	Reason string
+}
+
+// Explain evaluates every rule against the finding in order, for debugging
+// why a rule did or did not match. the first matched result is the rule
+// FindMatchingRule returns.
+func (e *FilterEngine) Explain(finding *events.SecurityHubV2Finding) []RuleResult {
+	ev := e.evaluation(finding)
+	results := make([]RuleResult, 0, len(e.Rules))
+	for i := range e.Rules {
+		result := RuleResult{Rule: &e.Rules[i]}
+		if !result.Rule.Enabled {
+			result.Reason = "disabled"
+		} else {
+			result.Reason = failedFilter(finding, result.Rule.Filters, ev)
+			result.Matched = result.Reason == ""
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// evaluation is what filters see beyond the finding itself, looked up once per
+// finding.
+type evaluation struct {
+	now time.Time
+	// recurring is whether the finding uid was processed before
+	recurring bool
+	// raw is the finding's raw json for match conditions
+	raw *rawDocument
+}
+
+func (e *FilterEngine) evaluation(finding *events.SecurityHubV2Finding) evaluation {
+	ev := evaluation{now: e.Clock.Now(), raw: newRawDocument(finding)}
+	if e.History != nil {
+		ev.recurring = e.History.Seen(finding.Metadata.UID, ev.now)
+	}
+	return ev
+}
+
+// Matches reports whether the finding satisfies the rule's filters at the
+// clock's time, using the same logic as the engine without history, so every
+// finding is new. the enabled flag is left to the caller.
+func (r *AutoCloseRule) Matches(finding *events.SecurityHubV2Finding, c clock.Clock) bool {
+	return matchesFilters(finding, r.Filters, evaluation{now: c.Now(), raw: newRawDocument(finding)})
+}
+
+func matchesFilters(finding *events.SecurityHubV2Finding, filters RuleFilters, ev evaluation) bool {
+	return failedFilter(finding, filters, ev) == ""
+}
+
+// failedFilter returns the json name of the first filter the finding fails
+// ("age" for min_age and max_age), or an empty string if it passes them all.
+func failedFilter(finding *events.SecurityHubV2Finding, filters RuleFilters, ev evaluation) string {
 	if len(filters.FindingTypes) > 0 && !matchesFindingTypes(finding, filters.FindingTypes) {
-		return false
+		return "finding_types"
 	}
 
 	if len(filters.Severity) > 0 && !contains(filters.Severity, finding.Severity) {
-		return false
+		return "severity"
 	}
 
 	if len(filters.ProductName) > 0 && !contains(filters.ProductName, finding.Metadata.Product.Name) {
-		return false
+		return "product_name"
+	}
+
+	if len(filters.ProductARNs) > 0 && !matchesProductARNs(finding, filters.ProductARNs) {
+		return "product_arns"
+	}
+
+	if len(filters.FeatureNames) > 0 && !matchesFeatureNames(finding, filters.FeatureNames) {
+		return "feature_names"
 	}
 
 	if len(filters.ResourceTypes) > 0 && !matchesResourceTypes(finding, filters.ResourceTypes) {
-		return false
+		return "resource_types"
+	}
+
+	if len(filters.ResourceTags) > 0 && !matchesResourceTags(finding, filters.ResourceTags, filters.ResourceTagsMinMatches) {
+		return "resource_tags"
+	}
+
+	if filters.MinResources > 0 && len(finding.Resources) < filters.MinResources {
+		return "min_resources"
 	}
 
-	if len(filters.ResourceTags) > 0 && !matchesResourceTags(finding, filters.ResourceTags) {
-		return false
+	if filters.MaxResources > 0 && len(finding.Resources) > filters.MaxResources {
+		return "max_resources"
 	}
 
 	if len(filters.Accounts) > 0 && !contains(filters.Accounts, finding.Cloud.Account.UID) {
-		return false
+		return "accounts"
+	}
+
+	if len(filters.Regions) > 0 && !MatchesAnyGlob(filters.Regions, finding.Cloud.Region) {
+		return "regions"
+	}
+
+	if len(filters.FindingUIDs) > 0 && !MatchesAnyGlob(filters.FindingUIDs, finding.FindingInfo.UID) {
+		return "finding_uids"
+	}
+
+	if len(filters.FindingUIDAlts) > 0 && !MatchesAnyGlob(filters.FindingUIDAlts, finding.FindingInfo.UIDalt) {
+		return "finding_uid_alts"
+	}
+
+	if len(filters.ComplianceControls) > 0 && !matchesComplianceControls(finding, filters.ComplianceControls) {
+		return "compliance_controls"
+	}
+
+	if len(filters.ComplianceRequirements) > 0 && !matchesComplianceRequirements(finding, filters.ComplianceRequirements) {
+		return "compliance_requirements"
+	}
+
+	if len(filters.ActivityIDs) > 0 && !slices.Contains(filters.ActivityIDs, finding.ActivityID) {
+		return "activity_ids"
+	}
+
+	if len(filters.ActivityNames) > 0 && !contains(filters.ActivityNames, finding.ActivityName) {
+		return "activity_names"
+	}
+
+	if len(filters.TypeUIDs) > 0 && !slices.Contains(filters.TypeUIDs, finding.TypeUID) {
+		return "type_uids"
+	}
+
+	if filters.DescPattern != nil && !matchesDescPattern(finding, filters.DescPattern) {
+		return "desc_pattern"
+	}
+
+	if (filters.MinAge > 0 || filters.MaxAge > 0) && !matchesAge(finding, filters, ev.now) {
+		return "age"
+	}
+
+	if filters.Recurrence != "" && (filters.Recurrence == RecurrenceRecurring) != ev.recurring {
+		return "recurrence"
 	}
 
-	if len(filters.Regions) > 0 && !contains(filters.Regions, finding.Cloud.Region) {
-		return false
+	if len(filters.Match) > 0 && !matchesConditions(ev.raw, filters.Match) {
+		return "match"
 	}
 
-	return true
+	return ""
 }
@@ -5,16 +5,36 @@
 // - Disabled rule handling
 // - First-match-wins rule precedence
 // - Complex multi-filter rules
+// - Minimum resource tag match thresholds
+// - Resource count bounds with varying resource counts
+// - Finding UID and alternate UID glob patterns
+// - Product feature names, including findings without a feature
+// - Product ARN globs against metadata and finding_info product uids
+// - Single-rule evaluation agrees with the engine
+// - Resource tag whitespace trimming and deduplication
+// - Resource tag name prefixes with a trailing *
+// - Account-partitioned lookup agrees with full-list order
+// - Compliance control and requirement filters, including nil compliance
+// - OCSF activity id, activity name and type uid filters
+// - Description patterns compiled at load, with empty descriptions never matching
+// - Age filters for each age basis with a fixed clock
+// - Explain reports the first failed filter for every rule
+// - Recurrence filter against a processed-uid store, and without one
 // - Uses fixtures/samples.json for realistic OCSF findings
 package filters
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
+	"time"
 
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/clock"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/recent"
 )
 
 // TestFilterEngine_FindMatchingRule_RunsOnExample validates that a GuardDuty finding
@@ -226,3 +246,725 @@ func TestFilterEngine_MultipleFilters(t *testing.T) {
 		t.Errorf("expected rule name 'complex-rule', got %s", matchedRule.Name)
 	}
 }
+
+// TestFilterEngine_ResourceTagsMinMatches validates partial resource tag
+// matching thresholds against the runs-on.com finding, whose EC2 resource has
+// provider and environment tags but no team tag.
+func TestFilterEngine_ResourceTagsMinMatches(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	tags := []ResourceTagFilter{
+		{Name: "provider", Value: "runs-on.com"},
+		{Name: "environment", Value: "production"},
+		{Name: "team", Value: "platform"},
+	}
+
+	tests := []struct {
+		name       string
+		minMatches int
+		expected   bool
+	}{
+		{"unset requires all", 0, false},
+		{"one of three", 1, true},
+		{"two of three", 2, true},
+		{"three of three", 3, false},
+		{"above group size requires all", 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{
+					Name:    "tag-threshold",
+					Enabled: true,
+					Filters: RuleFilters{
+						ResourceTags:           tags,
+						ResourceTagsMinMatches: tt.minMatches,
+					},
+				},
+			})
+
+			_, matched := engine.FindMatchingRule(finding)
+			if matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+// TestFilterEngine_FindingUIDPatterns validates glob matching on the product's
+// native finding id and alternate id (fixtures/samples.json finding #3).
+func TestFilterEngine_FindingUIDPatterns(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	tests := []struct {
+		name     string
+		filters  RuleFilters
+		expected bool
+	}{
+		{"exact uid", RuleFilters{FindingUIDs: []string{finding.FindingInfo.UID}}, true},
+		{"uid wildcard across slashes", RuleFilters{FindingUIDs: []string{"arn:aws:guardduty:us-east-1:*/finding/d6cd5459c241b902f2aaff9db607c941"}}, true},
+		{"uid wildcard mismatch", RuleFilters{FindingUIDs: []string{"arn:aws:guardduty:us-west-2:*"}}, false},
+		{"uid alt single char wildcard", RuleFilters{FindingUIDAlts: []string{"d6cd5459c241b902f2aaff9db607c94?"}}, true},
+		{"uid alt prefix", RuleFilters{FindingUIDAlts: []string{"d6cd*"}}, true},
+		{"uid alt mismatch", RuleFilters{FindingUIDAlts: []string{"0000*"}}, false},
+		{"any pattern matches", RuleFilters{FindingUIDAlts: []string{"0000*", "*c941"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{Name: "uid-rule", Enabled: true, Filters: tt.filters},
+			})
+
+			_, matched := engine.FindMatchingRule(finding)
+			if matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+
+	// findings without an alternate id never match uid_alt patterns
+	noAlt := &events.SecurityHubV2Finding{}
+	noAlt.FindingInfo.UID = "finding-without-alt"
+
+	engine := NewFilterEngine([]AutoCloseRule{
+		{Name: "uid-rule", Enabled: true, Filters: RuleFilters{FindingUIDAlts: []string{"*"}}},
+	})
+	if _, matched := engine.FindMatchingRule(noAlt); matched {
+		t.Error("expected finding without uid_alt not to match")
+	}
+}
+
+// TestFilterEngine_ProductARNs validates matching the generating product's
+// ARN from metadata.product.uid, with a finding_info.product.uid fallback.
+func TestFilterEngine_ProductARNs(t *testing.T) {
+	finding := loadSampleFinding(t, 0)
+
+	tests := []struct {
+		name     string
+		arns     []string
+		expected bool
+	}{
+		{"exact arn", []string{"arn:aws:securityhub:us-east-1::productv2/aws/guardduty"}, true},
+		{"any region", []string{"arn:aws:securityhub:*::productv2/aws/guardduty"}, true},
+		{"any aws product", []string{"arn:aws:securityhub:us-east-1::productv2/aws/*"}, true},
+		{"other product", []string{"arn:aws:securityhub:*::productv2/aws/inspector"}, false},
+		{"other region", []string{"arn:aws:securityhub:eu-west-1::productv2/aws/guardduty"}, false},
+		{"any pattern matches", []string{"arn:aws:securityhub:*::productv2/aws/inspector", "*/guardduty"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{Name: "arn-rule", Enabled: true, Filters: RuleFilters{ProductARNs: tt.arns}},
+			})
+
+			_, matched := engine.FindMatchingRule(finding)
+			if matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+
+	engine := NewFilterEngine([]AutoCloseRule{
+		{Name: "arn-rule", Enabled: true, Filters: RuleFilters{ProductARNs: []string{"arn:aws:securityhub:*::product/acme/*"}}},
+	})
+
+	// findings without a metadata product uid fall back to finding_info
+	fallback := &events.SecurityHubV2Finding{}
+	fallback.FindingInfo.Product = &events.Product{UID: "arn:aws:securityhub:us-east-1::product/acme/scanner"}
+	if _, matched := engine.FindMatchingRule(fallback); !matched {
+		t.Error("expected finding_info product uid to match")
+	}
+
+	if _, matched := engine.FindMatchingRule(&events.SecurityHubV2Finding{}); matched {
+		t.Error("expected finding without a product uid not to match")
+	}
+}
+
+// TestFilterEngine_ResourceCount validates min_resources and max_resources
+// against findings with zero, one and many resources.
+func TestFilterEngine_ResourceCount(t *testing.T) {
+	withResources := func(n int) *events.SecurityHubV2Finding {
+		finding := &events.SecurityHubV2Finding{}
+		for i := range n {
+			finding.Resources = append(finding.Resources, events.OCSFResource{UID: fmt.Sprintf("i-%03d", i)})
+		}
+		return finding
+	}
+
+	tests := []struct {
+		name     string
+		min, max int
+		count    int
+		expected bool
+	}{
+		{"single resource", 0, 1, 1, true},
+		{"no resources under max", 0, 1, 0, true},
+		{"above max", 0, 1, 2, false},
+		{"blast radius", 10, 0, 25, true},
+		{"at min", 10, 0, 10, true},
+		{"below min", 10, 0, 9, false},
+		{"no resources below min", 1, 0, 0, false},
+		{"within range", 2, 5, 5, true},
+		{"outside range", 2, 5, 6, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{Name: "count-rule", Enabled: true, Filters: RuleFilters{MinResources: tt.min, MaxResources: tt.max}},
+			})
+
+			_, matched := engine.FindMatchingRule(withResources(tt.count))
+			if matched != tt.expected {
+				t.Errorf("expected matched=%v for %d resources, got %v", tt.expected, tt.count, matched)
+			}
+		})
+	}
+}
+
+// TestFilterEngine_FeatureNames validates matching on the metadata and
+// finding_info product feature names.
+func TestFilterEngine_FeatureNames(t *testing.T) {
+	engine := NewFilterEngine([]AutoCloseRule{
+		{Name: "runtime-rule", Enabled: true, Filters: RuleFilters{FeatureNames: []string{"RuntimeMonitoring"}}},
+	})
+
+	if _, matched := engine.FindMatchingRule(loadSampleFinding(t, 2)); !matched {
+		t.Error("expected runtime monitoring finding to match")
+	}
+
+	// the security hub compliance finding has no feature
+	if _, matched := engine.FindMatchingRule(loadSampleFinding(t, 1)); matched {
+		t.Error("expected finding without a feature not to match")
+	}
+
+	if _, matched := engine.FindMatchingRule(&events.SecurityHubV2Finding{}); matched {
+		t.Error("expected empty finding not to match")
+	}
+
+	infoOnly, err := events.NewSecurityHubFinding([]byte(`{"finding_info": {"product": {"feature": {"name": "RuntimeMonitoring"}}}}`))
+	if err != nil {
+		t.Fatalf("failed to parse finding: %v", err)
+	}
+
+	if _, matched := engine.FindMatchingRule(infoOnly); !matched {
+		t.Error("expected finding_info feature name to match")
+	}
+
+	other := NewFilterEngine([]AutoCloseRule{
+		{Name: "s3-rule", Enabled: true, Filters: RuleFilters{FeatureNames: []string{"S3Protection"}}},
+	})
+	if _, matched := other.FindMatchingRule(loadSampleFinding(t, 2)); matched {
+		t.Error("expected different feature name not to match")
+	}
+}
+
+// TestAutoCloseRule_Matches validates that evaluating a single rule agrees
+// with FindMatchingRule for every fixture finding.
+func TestAutoCloseRule_Matches(t *testing.T) {
+	rules := []AutoCloseRule{
+		{Name: "guardduty", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}}},
+		{Name: "critical", Enabled: true, Filters: RuleFilters{Severity: []string{"Critical"}}},
+		{Name: "runs-on", Enabled: true, Filters: RuleFilters{ResourceTags: []ResourceTagFilter{{Name: "provider", Value: "runs-on.com"}}}},
+		{Name: "runtime", Enabled: true, Filters: RuleFilters{FeatureNames: []string{"RuntimeMonitoring"}, Regions: []string{"us-east-1"}}},
+		{Name: "nothing", Enabled: true, Filters: RuleFilters{Accounts: []string{"000000000000"}}},
+	}
+
+	for i := 0; i < 3; i++ {
+		finding := loadSampleFinding(t, i)
+
+		for _, rule := range rules {
+			_, engineMatched := NewFilterEngine([]AutoCloseRule{rule}).FindMatchingRule(finding)
+			if got := rule.Matches(finding, clock.Real{}); got != engineMatched {
+				t.Errorf("finding %d rule %s: Matches=%v, engine=%v", i, rule.Name, got, engineMatched)
+			}
+		}
+	}
+
+	// the enabled flag is left to the caller
+	disabled := AutoCloseRule{Name: "disabled", Enabled: false, Filters: RuleFilters{ProductName: []string{"GuardDuty"}}}
+	if !disabled.Matches(loadSampleFinding(t, 0), clock.Real{}) {
+		t.Error("expected disabled rule filters to still match")
+	}
+}
+
+// TestFilterEngine_ResourceTagsNormalized validates that padded and duplicate
+// tags match a clean filter while case stays significant.
+func TestFilterEngine_ResourceTagsNormalized(t *testing.T) {
+	finding := &events.SecurityHubV2Finding{
+		Resources: []events.OCSFResource{
+			{
+				UID: "i-0123456789",
+				Tags: []events.ResourceTag{
+					{Name: " provider ", Value: "runs-on.com\n"},
+					{Name: "provider", Value: "runs-on.com"},
+					{Name: "Environment", Value: " dev"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		tags       []ResourceTagFilter
+		minMatches int
+		expected   bool
+	}{
+		{"padded resource tag matches clean filter", []ResourceTagFilter{{Name: "provider", Value: "runs-on.com"}}, 0, true},
+		{"padded filter matches", []ResourceTagFilter{{Name: "Environment ", Value: "dev "}}, 0, true},
+		{"case still significant", []ResourceTagFilter{{Name: "environment", Value: "dev"}}, 0, false},
+		{"duplicate filters count once", []ResourceTagFilter{
+			{Name: "provider", Value: "runs-on.com"},
+			{Name: " provider", Value: "runs-on.com"},
+			{Name: "team", Value: "platform"},
+		}, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{
+					Name:    "tag-rule",
+					Enabled: true,
+					Filters: RuleFilters{ResourceTags: tt.tags, ResourceTagsMinMatches: tt.minMatches},
+				},
+			})
+
+			if _, matched := engine.FindMatchingRule(finding); matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+// TestFilterEngine_ResourceTagNamePrefix validates that a tag name ending in *
+// matches any tag name with that prefix while other names stay exact.
+func TestFilterEngine_ResourceTagNamePrefix(t *testing.T) {
+	finding := &events.SecurityHubV2Finding{
+		Resources: []events.OCSFResource{
+			{
+				UID: "i-0123456789",
+				Tags: []events.ResourceTag{
+					{Name: "aws:cloudformation:stack-name", Value: "runners"},
+					{Name: "team", Value: "platform"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		tags     []ResourceTagFilter
+		expected bool
+	}{
+		{"prefix matches", []ResourceTagFilter{{Name: "aws:cloudformation:*", Value: "runners"}}, true},
+		{"prefix value must match", []ResourceTagFilter{{Name: "aws:cloudformation:*", Value: "other"}}, false},
+		{"prefix not matching", []ResourceTagFilter{{Name: "aws:autoscaling:*", Value: "runners"}}, false},
+		{"bare star matches any name", []ResourceTagFilter{{Name: "*", Value: "platform"}}, true},
+		{"exact name without star", []ResourceTagFilter{{Name: "aws:cloudformation:", Value: "runners"}}, false},
+		{"prefix combined with exact", []ResourceTagFilter{
+			{Name: "aws:cloudformation:*", Value: "runners"},
+			{Name: "team", Value: "platform"},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{
+				{Name: "tag-rule", Enabled: true, Filters: RuleFilters{ResourceTags: tt.tags}},
+			})
+
+			if _, matched := engine.FindMatchingRule(finding); matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+// TestFilterEngine_ActivityFilters validates matching on the top-level OCSF
+// activity and type of each fixture finding: Create (200401), Update (200302)
+// and Close (200403).
+func TestFilterEngine_ActivityFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  RuleFilters
+		expected []bool
+	}{
+		{"activity id", RuleFilters{ActivityIDs: []int{1}}, []bool{true, false, false}},
+		{"any activity id", RuleFilters{ActivityIDs: []int{2, 3}}, []bool{false, true, true}},
+		{"activity name", RuleFilters{ActivityNames: []string{"Update"}}, []bool{false, true, false}},
+		{"activity name is case sensitive", RuleFilters{ActivityNames: []string{"close"}}, []bool{false, false, false}},
+		{"type uid", RuleFilters{TypeUIDs: []int{200401, 200403}}, []bool{true, false, true}},
+		{"activity and type", RuleFilters{ActivityNames: []string{"Create", "Close"}, TypeUIDs: []int{200403}}, []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{{Name: "activity-rule", Enabled: true, Filters: tt.filters}})
+
+			for i, expected := range tt.expected {
+				if _, matched := engine.FindMatchingRule(loadSampleFinding(t, i)); matched != expected {
+					t.Errorf("sample %d: expected matched=%v, got %v", i, expected, matched)
+				}
+			}
+		})
+	}
+}
+
+// TestFilterEngine_DescPattern validates matching a phrase in the
+// description of the GuardDuty finding (fixtures/samples.json finding #1),
+// that findings without a description never match and that an invalid
+// pattern fails when the rule is parsed.
+func TestFilterEngine_DescPattern(t *testing.T) {
+	guardDuty := loadSampleFinding(t, 0)
+	cspm := loadSampleFinding(t, 1)
+
+	tests := []struct {
+		name    string
+		pattern string
+		matches []bool
+	}{
+		{"phrase", `newly created binary`, []bool{true, false}},
+		{"case insensitive", `(?i)A PROCESS HAS EXECUTED`, []bool{true, false}},
+		{"anchored", `^AWS Config is a web service`, []bool{false, true}},
+		{"no match", `cryptocurrency`, []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filters RuleFilters
+			raw, _ := json.Marshal(map[string]string{"desc_pattern": tt.pattern})
+			if err := json.Unmarshal(raw, &filters); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			engine := NewFilterEngine([]AutoCloseRule{{Name: "desc-rule", Enabled: true, Filters: filters}})
+
+			for i, finding := range []*events.SecurityHubV2Finding{guardDuty, cspm} {
+				if _, matched := engine.FindMatchingRule(finding); matched != tt.matches[i] {
+					t.Errorf("finding %d: expected matched=%v, got %v", i, tt.matches[i], matched)
+				}
+			}
+		})
+	}
+
+	engine := NewFilterEngine([]AutoCloseRule{{Name: "desc-rule", Enabled: true, Filters: RuleFilters{DescPattern: &Pattern{regexp.MustCompile(`.*`)}}}})
+	if _, matched := engine.FindMatchingRule(&events.SecurityHubV2Finding{}); matched {
+		t.Error("expected finding without a description not to match")
+	}
+
+	var filters RuleFilters
+	if err := json.Unmarshal([]byte(`{"desc_pattern": "binary("}`), &filters); err == nil {
+		t.Error("expected an invalid pattern to fail parsing")
+	}
+}
+
+// TestFilterEngine_ComplianceFilters validates matching on the compliance
+// control and requirements of the CSPM finding (fixtures/samples.json finding #2).
+func TestFilterEngine_ComplianceFilters(t *testing.T) {
+	cspm := loadSampleFinding(t, 1)
+	guardDuty := loadSampleFinding(t, 0)
+
+	tests := []struct {
+		name     string
+		filters  RuleFilters
+		expected bool
+	}{
+		{"control matches", RuleFilters{ComplianceControls: []string{"S3.1", "Config.1"}}, true},
+		{"control mismatch", RuleFilters{ComplianceControls: []string{"S3.1"}}, false},
+		{"requirement matches", RuleFilters{ComplianceRequirements: []string{"CIS AWS Foundations 2.5"}}, true},
+		{"requirement mismatch", RuleFilters{ComplianceRequirements: []string{"CIS AWS Foundations 1.1"}}, false},
+		{"control and requirement", RuleFilters{
+			ComplianceControls:     []string{"Config.1"},
+			ComplianceRequirements: []string{"CIS AWS Foundations 2.5"},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{{Name: "compliance-rule", Enabled: true, Filters: tt.filters}})
+
+			if _, matched := engine.FindMatchingRule(cspm); matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+
+			// the guardduty finding has no compliance data
+			if _, matched := engine.FindMatchingRule(guardDuty); matched {
+				t.Error("expected finding without compliance not to match")
+			}
+		})
+	}
+}
+
+// TestFilterEngine_AgeBasis validates that age_basis selects the timestamp
+// age filters are measured from, defaulting to first seen, for the engine and
+// for a single rule's Matches.
+func TestFilterEngine_AgeBasis(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) int64 { return now.Add(-d).UnixMilli() }
+
+	finding := &events.SecurityHubV2Finding{}
+	finding.FindingInfo.CreatedTime = ago(20 * 24 * time.Hour)
+	finding.FindingInfo.FirstSeenTime = ago(10 * 24 * time.Hour)
+	finding.FindingInfo.ModifiedTime = ago(2 * 24 * time.Hour)
+	finding.FindingInfo.LastSeenTime = ago(time.Hour)
+
+	tests := []struct {
+		name     string
+		filters  RuleFilters
+		expected bool
+	}{
+		{"default basis is first seen", RuleFilters{MinAge: Age(7 * 24 * time.Hour)}, true},
+		{"first seen min age", RuleFilters{MinAge: Age(7 * 24 * time.Hour), AgeBasis: AgeBasisFirstSeen}, true},
+		{"last seen min age", RuleFilters{MinAge: Age(7 * 24 * time.Hour), AgeBasis: AgeBasisLastSeen}, false},
+		{"created min age", RuleFilters{MinAge: Age(15 * 24 * time.Hour), AgeBasis: AgeBasisCreated}, true},
+		{"modified min age", RuleFilters{MinAge: Age(3 * 24 * time.Hour), AgeBasis: AgeBasisModified}, false},
+		{"last seen max age", RuleFilters{MaxAge: Age(2 * time.Hour), AgeBasis: AgeBasisLastSeen}, true},
+		{"first seen max age", RuleFilters{MaxAge: Age(2 * time.Hour)}, false},
+		{"age window", RuleFilters{MinAge: Age(24 * time.Hour), MaxAge: Age(3 * 24 * time.Hour), AgeBasis: AgeBasisModified}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine([]AutoCloseRule{{Name: "age-rule", Enabled: true, Filters: tt.filters}})
+			engine.Clock = clock.NewFake(now)
+
+			if _, matched := engine.FindMatchingRule(finding); matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+			rule := AutoCloseRule{Name: "age-rule", Enabled: true, Filters: tt.filters}
+			if matched := rule.Matches(finding, clock.NewFake(now)); matched != tt.expected {
+				t.Errorf("expected Matches=%v at the clock's time, got %v", tt.expected, matched)
+			}
+		})
+	}
+
+	// the *_dt string is used when the epoch is zero
+	dtOnly := &events.SecurityHubV2Finding{}
+	dtOnly.FindingInfo.FirstSeenTimeDt = now.Add(-10 * 24 * time.Hour).Format(time.RFC3339)
+	engine := NewFilterEngine([]AutoCloseRule{{Name: "age-rule", Enabled: true, Filters: RuleFilters{MinAge: Age(7 * 24 * time.Hour)}}})
+	engine.Clock = clock.NewFake(now)
+	if _, matched := engine.FindMatchingRule(dtOnly); !matched {
+		t.Error("expected finding with only first_seen_time_dt to match")
+	}
+
+	// a finding without the basis timestamp has no known age
+	engine = NewFilterEngine([]AutoCloseRule{{Name: "age-rule", Enabled: true, Filters: RuleFilters{MaxAge: Age(24 * time.Hour)}}})
+	engine.Clock = clock.NewFake(now)
+	if _, matched := engine.FindMatchingRule(&events.SecurityHubV2Finding{}); matched {
+		t.Error("expected finding without timestamps not to match")
+	}
+}
+
+// TestFilterEngine_Explain validates that every rule is reported with the
+// first filter it failed, and that the first match agrees with the engine.
+func TestFilterEngine_Explain(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+	engine := NewFilterEngine([]AutoCloseRule{
+		{Name: "disabled", Enabled: false, Filters: RuleFilters{ProductName: []string{"GuardDuty"}}},
+		{Name: "wrong-severity", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}, Severity: []string{"Critical"}}},
+		{Name: "wrong-tag", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}, ResourceTags: []ResourceTagFilter{{Name: "provider", Value: "github"}}}},
+		{Name: "runs-on", Enabled: true, Filters: RuleFilters{ResourceTags: []ResourceTagFilter{{Name: "provider", Value: "runs-on.com"}}}},
+		{Name: "later", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}}},
+	})
+
+	expected := []struct {
+		name    string
+		matched bool
+		reason  string
+	}{
+		{"disabled", false, "disabled"},
+		{"wrong-severity", false, "severity"},
+		{"wrong-tag", false, "resource_tags"},
+		{"runs-on", true, ""},
+		{"later", true, ""},
+	}
+
+	results := engine.Explain(finding)
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+
+	for i, want := range expected {
+		got := results[i]
+		if got.Rule.Name != want.name || got.Matched != want.matched || got.Reason != want.reason {
+			t.Errorf("result %d: expected %+v, got {%s %v %q}", i, want, got.Rule.Name, got.Matched, got.Reason)
+		}
+	}
+
+	rule, _ := engine.FindMatchingRule(finding)
+	if rule != results[3].Rule {
+		t.Errorf("expected first explained match to be the engine's match, got %s", rule.Name)
+	}
+}
+
+// TestFilterEngine_Recurrence validates that new and recurring rules match by
+// whether the finding uid is in the history store, and that without a store
+// every finding is new.
+func TestFilterEngine_Recurrence(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+	rules := []AutoCloseRule{
+		{Name: "close-recurring", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}, Recurrence: RecurrenceRecurring}},
+		{Name: "notify-new", Enabled: true, Filters: RuleFilters{ProductName: []string{"GuardDuty"}, Recurrence: RecurrenceNew}},
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	history := recent.NewCache(10, time.Hour)
+	engine := NewFilterEngine(rules)
+	engine.History = history
+	fake := clock.NewFake(now)
+	engine.Clock = fake
+
+	if rule, ok := engine.FindMatchingRule(finding); !ok || rule.Name != "notify-new" {
+		t.Errorf("expected first sight to match notify-new, got %v", rule)
+	}
+
+	history.Add(finding.Metadata.UID, now.Add(-time.Minute))
+	if rule, ok := engine.FindMatchingRule(finding); !ok || rule.Name != "close-recurring" {
+		t.Errorf("expected a processed finding to match close-recurring, got %v", rule)
+	}
+	if results := engine.Explain(finding); results[1].Matched || results[1].Reason != "recurrence" {
+		t.Errorf("expected notify-new to fail on recurrence, got %+v", results[1])
+	}
+
+	fake.Advance(2 * time.Hour)
+	if rule, ok := engine.FindMatchingRule(finding); !ok || rule.Name != "notify-new" {
+		t.Errorf("expected an expired uid to be new again, got %v", rule)
+	}
+
+	if rule, ok := NewFilterEngine(rules).FindMatchingRule(finding); !ok || rule.Name != "notify-new" {
+		t.Errorf("expected every finding to be new without history, got %v", rule)
+	}
+}
+
+// linearMatch is the unindexed reference for FindMatchingRule.
+func linearMatch(rules []AutoCloseRule, finding *events.SecurityHubV2Finding) (*AutoCloseRule, bool) {
+	for i := range rules {
+		if rules[i].Enabled && rules[i].Matches(finding, clock.Real{}) {
+			return &rules[i], true
+		}
+	}
+	return nil, false
+}
+
+// TestFilterEngine_AccountPartitioning validates that indexing rules by
+// account returns the same first match as evaluating every rule in order.
+func TestFilterEngine_AccountPartitioning(t *testing.T) {
+	findings := []*events.SecurityHubV2Finding{
+		loadSampleFinding(t, 0),
+		loadSampleFinding(t, 1),
+		loadSampleFinding(t, 2),
+	}
+	account := findings[1].Cloud.Account.UID
+
+	// the samples share one account, so move the first to another
+	findings[0].Cloud.Account.UID = "210987654321"
+
+	tests := []struct {
+		name     string
+		rules    []AutoCloseRule
+		expected []string
+	}{
+		{
+			name: "scoped rule before agnostic rule",
+			rules: []AutoCloseRule{
+				{Name: "scoped", Enabled: true, Filters: RuleFilters{Accounts: []string{account}}},
+				{Name: "agnostic", Enabled: true, Filters: RuleFilters{ProductName: []string{"Security Hub", "GuardDuty"}}},
+			},
+			expected: []string{"agnostic", "scoped", "scoped"},
+		},
+		{
+			name: "agnostic rule before scoped rule",
+			rules: []AutoCloseRule{
+				{Name: "agnostic", Enabled: true, Filters: RuleFilters{Severity: []string{"Critical"}}},
+				{Name: "scoped", Enabled: true, Filters: RuleFilters{Accounts: []string{"000000000000", account}}},
+			},
+			expected: []string{"", "agnostic", "scoped"},
+		},
+		{
+			name: "disabled scoped rule falls through",
+			rules: []AutoCloseRule{
+				{Name: "disabled", Enabled: false, Filters: RuleFilters{Accounts: []string{account}}},
+				{Name: "other-account", Enabled: true, Filters: RuleFilters{Accounts: []string{"210987654321"}}},
+				{Name: "fallback", Enabled: true, Filters: RuleFilters{Accounts: []string{account, account}}},
+			},
+			expected: []string{"other-account", "fallback", "fallback"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewFilterEngine(tt.rules)
+
+			for i, finding := range findings {
+				rule, matched := engine.FindMatchingRule(finding)
+				wantRule, wantMatched := linearMatch(tt.rules, finding)
+				if matched != wantMatched || rule != wantRule {
+					t.Errorf("finding %d: indexed lookup disagrees with full-list order", i)
+				}
+
+				name := ""
+				if matched {
+					name = rule.Name
+				}
+				if name != tt.expected[i] {
+					t.Errorf("finding %d: expected rule %q, got %q", i, tt.expected[i], name)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFilterEngine_FindMatchingRule(b *testing.B) {
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		b.Fatalf("failed to read samples: %v", err)
+	}
+
+	var samples []json.RawMessage
+	if err := json.Unmarshal(raw, &samples); err != nil {
+		b.Fatalf("failed to unmarshal samples: %v", err)
+	}
+
+	var findings []*events.SecurityHubV2Finding
+	for _, sample := range samples {
+		finding, err := events.NewSecurityHubFinding(sample)
+		if err != nil {
+			b.Fatalf("failed to parse sample: %v", err)
+		}
+		findings = append(findings, finding)
+	}
+
+	// many account-scoped rules that never match plus one agnostic rule
+	var rules []AutoCloseRule
+	for i := range 1000 {
+		rules = append(rules, AutoCloseRule{
+			Name:    fmt.Sprintf("account-%d", i),
+			Enabled: true,
+			Filters: RuleFilters{
+				Accounts:     []string{fmt.Sprintf("%012d", i)},
+				FindingTypes: []string{"does-not-match"},
+			},
+		})
+	}
+	rules = append(rules, AutoCloseRule{
+		Name:    "agnostic",
+		Enabled: true,
+		Filters: RuleFilters{Severity: []string{"Critical"}},
+	})
+
+	engine := NewFilterEngine(rules)
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			engine.FindMatchingRule(findings[i%len(findings)])
+		}
+	})
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			linearMatch(rules, findings[i%len(findings)])
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// RuleFixture is a finding and the decision a rule set is expected to make
+// for it, for testing rules declaratively.
+type RuleFixture struct {
+	Name    string          `json:"name"`
+	Finding json.RawMessage `json:"finding"`
+	Expect  RuleExpectation `json:"expect"`
+}
+
+// RuleExpectation is the expected decision for a fixture. an empty Rule
+// expects no rule to match, and unset fields are not checked.
+type RuleExpectation struct {
+	Rule             string `json:"rule,omitempty"`
+	Action           string `json:"action,omitempty"`
+	StatusID         int32  `json:"status_id,omitempty"`
+	SkipNotification *bool  `json:"skip_notification,omitempty"`
+}
+
+// LoadRuleFixtures reads a json array of rule fixtures from path.
+func LoadRuleFixtures(path string) ([]RuleFixture, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rule fixtures %s", path)
+	}
+
+	var fixtures []RuleFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		return nil, errors.Wrapf(err, "invalid rule fixtures %s", path)
+	}
+	for i, f := range fixtures {
+		if f.Name == "" {
+			return nil, errors.Newf("rule fixture %d has no name", i)
+		}
+		if len(f.Finding) == 0 || string(f.Finding) == "null" {
+			return nil, errors.Newf("rule fixture %q has no finding", f.Name)
+		}
+		switch f.Expect.Action {
+		case "", ActionTypeClose, ActionTypeObserve:
+		default:
+			return nil, errors.Newf("rule fixture %q has unknown action %q (expected 'close' or 'observe')", f.Name, f.Expect.Action)
+		}
+		if f.Expect.Rule == "" && (f.Expect.Action != "" || f.Expect.StatusID != 0 || f.Expect.SkipNotification != nil) {
+			return nil, errors.Newf("rule fixture %q expects no match but sets a decision", f.Name)
+		}
+	}
+	return fixtures, nil
+}
+
+// RunRuleFixtures validates the rules and runs each fixture in fixtureFile
+// against them as a subtest, so a rule set can be tested with a `go test`
+// file that only names its rules and fixtures.
+func RunRuleFixtures(t *testing.T, rules []AutoCloseRule, fixtureFile string) {
+	t.Helper()
+
+	if err := ValidateRules(rules); err != nil {
+		t.Fatalf("invalid rules: %v", err)
+	}
+
+	fixtures, err := LoadRuleFixtures(fixtureFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewFilterEngine(rules)
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			for _, problem := range checkRuleFixture(engine, f) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// checkRuleFixture returns how the engine's decision differs from the
+// fixture's expectation. a missed rule is explained by its failed filter.
+func checkRuleFixture(engine *FilterEngine, f RuleFixture) []string {
+	finding, err := events.NewSecurityHubFinding(f.Finding)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to parse finding: %v", err)}
+	}
+
+	rule, matched := engine.FindMatchingRule(finding)
+	if f.Expect.Rule == "" {
+		if matched {
+			return []string{fmt.Sprintf("expected no rule to match, got %q", rule.Name)}
+		}
+		return nil
+	}
+
+	if !matched || rule.Name != f.Expect.Rule {
+		got := "no rule"
+		if matched {
+			got = fmt.Sprintf("%q", rule.Name)
+		}
+		problem := fmt.Sprintf("expected rule %q to match, got %s", f.Expect.Rule, got)
+		for _, result := range engine.Explain(finding) {
+			if result.Rule.Name == f.Expect.Rule && !result.Matched {
+				problem += fmt.Sprintf(" (%q failed on %s)", f.Expect.Rule, result.Reason)
+			}
+		}
+		return []string{problem}
+	}
+
+	var problems []string
+	action := rule.Action.Type
+	if action == "" {
+		action = ActionTypeClose
+	}
+	if f.Expect.Action != "" && action != f.Expect.Action {
+		problems = append(problems, fmt.Sprintf("expected action %q, got %q", f.Expect.Action, action))
+	}
+	if f.Expect.StatusID != 0 && rule.Action.StatusID != f.Expect.StatusID {
+		problems = append(problems, fmt.Sprintf("expected status_id %d, got %d", f.Expect.StatusID, rule.Action.StatusID))
+	}
+	if f.Expect.SkipNotification != nil && rule.SkipNotification != *f.Expect.SkipNotification {
+		problems = append(problems, fmt.Sprintf("expected skip_notification %t, got %t", *f.Expect.SkipNotification, rule.SkipNotification))
+	}
+	return problems
+}
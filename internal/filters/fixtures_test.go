@@ -0,0 +1,115 @@
+// Package filters tests declarative rule fixtures.
+//
+// Tests cover:
+// - A sample rule set passes fixtures/rule_fixtures.json
+// - Wrong expectations are reported with the expected rule's failed filter
+// - Malformed fixture files are rejected
+package filters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sampleFixtureRules is the rule set fixtures/rule_fixtures.json is written
+// against.
+func sampleFixtureRules(t *testing.T) []AutoCloseRule {
+	t.Helper()
+
+	rules, err := parseRules([]byte(`[
+		{
+			"name": "close-github-runner-binaries",
+			"enabled": true,
+			"filters": {
+				"finding_types": ["Execution:Runtime/NewBinaryExecuted"],
+				"resource_tags": [{"name": "component-type", "value": "github-action-runners"}]
+			},
+			"action": {"status_id": 5, "comment": "Auto-closed: expected behavior for CI runners"},
+			"skip_notification": true
+		},
+		{
+			"name": "observe-sandbox-cis",
+			"enabled": true,
+			"filters": {
+				"accounts": ["210987654321"],
+				"product_name": ["Security Hub"]
+			},
+			"action": {"type": "observe", "status": "suppressed"}
+		}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to parse rules: %v", err)
+	}
+	return rules
+}
+
+// TestRunRuleFixtures validates the sample rule set against the sample
+// fixtures, as a rule author's test would.
+func TestRunRuleFixtures(t *testing.T) {
+	RunRuleFixtures(t, sampleFixtureRules(t), filepath.Join("..", "..", "fixtures", "rule_fixtures.json"))
+}
+
+// TestCheckRuleFixture_Mismatch validates that wrong expectations are
+// reported, naming the filter the expected rule failed on.
+func TestCheckRuleFixture_Mismatch(t *testing.T) {
+	fixtures, err := LoadRuleFixtures(filepath.Join("..", "..", "fixtures", "rule_fixtures.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	engine := NewFilterEngine(sampleFixtureRules(t))
+
+	closed := fixtures[0]
+	skip := false
+	closed.Expect.Action = ActionTypeObserve
+	closed.Expect.StatusID = 3
+	closed.Expect.SkipNotification = &skip
+	if problems := checkRuleFixture(engine, closed); len(problems) != 3 {
+		t.Errorf("expected action, status and notification problems, got %v", problems)
+	}
+
+	missed := fixtures[1]
+	missed.Expect.Rule = "close-github-runner-binaries"
+	problems := checkRuleFixture(engine, missed)
+	if len(problems) != 1 || !strings.Contains(problems[0], "failed on resource_tags") {
+		t.Errorf("expected the missed rule's failed filter, got %v", problems)
+	}
+
+	unexpected := fixtures[0]
+	unexpected.Expect = RuleExpectation{}
+	if problems := checkRuleFixture(engine, unexpected); len(problems) != 1 {
+		t.Errorf("expected an unexpected match problem, got %v", problems)
+	}
+}
+
+// TestLoadRuleFixtures_Invalid validates that malformed fixtures are rejected
+// before any are run.
+func TestLoadRuleFixtures_Invalid(t *testing.T) {
+	finding := json.RawMessage(`{"severity": "High"}`)
+	cases := map[string][]RuleFixture{
+		"missing name":     {{Finding: finding}},
+		"missing finding":  {{Name: "a"}},
+		"unknown action":   {{Name: "a", Finding: finding, Expect: RuleExpectation{Rule: "r", Action: "delete"}}},
+		"decision no rule": {{Name: "a", Finding: finding, Expect: RuleExpectation{StatusID: 5}}},
+	}
+
+	for name, fixtures := range cases {
+		raw, err := json.Marshal(fixtures)
+		if err != nil {
+			t.Fatalf("failed to marshal fixtures: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "fixtures.json")
+		if err := os.WriteFile(path, raw, 0o600); err != nil {
+			t.Fatalf("failed to write fixtures: %v", err)
+		}
+		if _, err := LoadRuleFixtures(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := LoadRuleFixtures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,127 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxHTTPRulesSize caps the rules document read from a url, so a misbehaving
+// server can't exhaust the Lambda's memory.
+const MaxHTTPRulesSize = 10 << 20
+
+// HTTPRulesLoader loads rules from a JSON or YAML document served over HTTP(S),
+// such as an internal config service.
+type HTTPRulesLoader struct {
+	client        *http.Client
+	url           string
+	authorization string
+	timeout       time.Duration
+}
+
+// NewHTTPRulesLoader creates a loader that GETs rules from url. authorization
+// is sent as the Authorization header when set, timeout bounds each load when
+// positive and a nil client uses http.DefaultClient.
+func NewHTTPRulesLoader(client *http.Client, url, authorization string, timeout time.Duration) *HTTPRulesLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPRulesLoader{
+		client:        client,
+		url:           url,
+		authorization: authorization,
+		timeout:       timeout,
+	}
+}
+
+// LoadRules fetches and parses the rules. the document is read as YAML when
+// its content type or url path says so, and as JSON otherwise. like an S3
+// rule file, an empty document or a disabled wrapper loads no rules.
+func (l *HTTPRulesLoader) LoadRules(ctx context.Context) ([]AutoCloseRule, error) {
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create rules request")
+	}
+	req.Header.Set("Accept", "application/json, application/yaml")
+	if l.authorization != "" {
+		req.Header.Set("Authorization", l.authorization)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch rules")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Newf("rules request returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxHTTPRulesSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rules response")
+	}
+	if len(data) > MaxHTTPRulesSize {
+		return nil, errors.Newf("rules response exceeds %d bytes", MaxHTTPRulesSize)
+	}
+
+	var rules []AutoCloseRule
+	if isYAML(resp.Header.Get("Content-Type"), l.url) {
+		rules, err = parseYAMLRules(data)
+	} else {
+		rules, err = parseRules(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// isYAML reports whether a response is YAML by its content type, falling back
+// to the url's file extension.
+func isYAML(contentType, rawURL string) bool {
+	if strings.Contains(contentType, "yaml") {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch path.Ext(u.Path) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// parseYAMLRules converts a YAML document to JSON so it's parsed by the same
+// rules as JSON files, including the single rule and wrapper forms.
+func parseYAMLRules(data []byte) ([]AutoCloseRule, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse yaml rules")
+	}
+	if doc == nil {
+		return nil, nil
+	}
+
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert yaml rules")
+	}
+	return parseRules(converted)
+}
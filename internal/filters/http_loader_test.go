@@ -0,0 +1,129 @@
+// Package filters tests HTTP(S) auto-close rule loading.
+//
+// Tests cover:
+// - JSON rules fetched with the configured Authorization header
+// - YAML rules detected by content type or url extension
+// - Error statuses, oversized documents and slow servers rejected
+// - Empty documents and disabled wrappers loading no rules
+//
+// Uses httptest servers in place of a config service.
+package filters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const yamlRules = `
+- name: close-low
+  enabled: true
+  filters:
+    severity: [Low]
+  action:
+    status_id: 3
+    comment: Accepted risk
+`
+
+// TestHTTPRulesLoader_JSON validates that rules are fetched with the
+// Authorization header and parsed like rule files.
+func TestHTTPRulesLoader_JSON(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"rules": [
+			{"name": "rule-1", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}},
+			{"name": "rule-2", "enabled": true, "filters": {"severity": ["High"]}, "action": {"status_id": 5}}
+		]}`))
+	}))
+	defer server.Close()
+
+	loader := NewHTTPRulesLoader(server.Client(), server.URL+"/rules", "Bearer secret", time.Second)
+	rules, err := loader.LoadRules(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if authorization != "Bearer secret" {
+		t.Errorf("expected the authorization header, got %q", authorization)
+	}
+	if len(rules) != 2 || rules[0].Name != "rule-1" || rules[1].Action.StatusID != 5 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+}
+
+// TestHTTPRulesLoader_YAML validates that YAML is parsed when the content type
+// or the url's extension says so.
+func TestHTTPRulesLoader_YAML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rules" {
+			w.Header().Set("Content-Type", "application/yaml")
+		}
+		w.Write([]byte(yamlRules))
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/rules", "/rules.yml"} {
+		loader := NewHTTPRulesLoader(server.Client(), server.URL+path, "", 0)
+		rules, err := loader.LoadRules(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if len(rules) != 1 || rules[0].Name != "close-low" || rules[0].Action.StatusID != 3 || rules[0].Filters.Severity[0] != "Low" {
+			t.Errorf("%s: unexpected rules: %+v", path, rules)
+		}
+	}
+}
+
+// TestHTTPRulesLoader_Errors validates that error statuses, oversized
+// documents and responses slower than the timeout fail the load.
+func TestHTTPRulesLoader_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/large":
+			w.Write([]byte(`[{"name": "` + strings.Repeat("a", MaxHTTPRulesSize) + `"}]`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(`[{"name": "late", "enabled": true}]`))
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/forbidden", "/large", "/slow"} {
+		loader := NewHTTPRulesLoader(server.Client(), server.URL+path, "", 50*time.Millisecond)
+		if _, err := loader.LoadRules(context.Background()); err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
+
+// TestHTTPRulesLoader_NoRules validates that an empty array or a disabled
+// wrapper loads no rules without failing, like an S3 rule file.
+func TestHTTPRulesLoader_NoRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty":
+			w.Write([]byte(`[]`))
+		case "/disabled":
+			w.Write([]byte(`{"enabled": false, "rules": [{"name": "parked", "enabled": true}]}`))
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/empty", "/disabled"} {
+		loader := NewHTTPRulesLoader(server.Client(), server.URL+path, "", 0)
+		rules, err := loader.LoadRules(context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", path, err)
+		}
+		if len(rules) != 0 {
+			t.Errorf("%s: expected no rules, got %+v", path, rules)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package filters
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// rawDocument parses a finding's raw json on first use, so every rule with
+// match conditions shares one parse per finding.
+type rawDocument struct {
+	raw    json.RawMessage
+	parsed bool
+	doc    any
+	ok     bool
+}
+
+func newRawDocument(finding *events.SecurityHubV2Finding) *rawDocument {
+	return &rawDocument{raw: finding.Raw}
+}
+
+// get returns the parsed document, or false when the finding has no raw json
+// or it doesn't parse.
+func (d *rawDocument) get() (any, bool) {
+	if !d.parsed {
+		d.parsed = true
+		if len(d.raw) > 0 {
+			d.ok = json.Unmarshal(d.raw, &d.doc) == nil
+		}
+	}
+	return d.doc, d.ok
+}
+
+func matchesConditions(raw *rawDocument, conditions []MatchCondition) bool {
+	doc, ok := raw.get()
+	if !ok {
+		return false
+	}
+
+	for _, cond := range conditions {
+		if !matchesCondition(doc, cond) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesCondition returns true if any value resolved from the path satisfies
+// the condition.
+func matchesCondition(doc any, cond MatchCondition) bool {
+	for _, actual := range resolvePath(doc, cond.Path) {
+		if compareValues(actual, cond.Op, cond.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath walks a dotted path with optional [n] or [*] index segments and
+// returns every value found.
+func resolvePath(doc any, path string) []any {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return []any{doc}
+	}
+
+	current := []any{doc}
+	for _, segment := range strings.Split(path, ".") {
+		key, indexes := splitIndexes(segment)
+
+		var next []any
+		for _, node := range current {
+			if key != "" {
+				obj, ok := node.(map[string]any)
+				if !ok {
+					continue
+				}
+				node, ok = obj[key]
+				if !ok {
+					continue
+				}
+			}
+			next = append(next, applyIndexes(node, indexes)...)
+		}
+
+		current = next
+		if len(current) == 0 {
+			return nil
+		}
+	}
+	return current
+}
+
+func splitIndexes(segment string) (string, []string) {
+	open := strings.Index(segment, "[")
+	if open == -1 {
+		return segment, nil
+	}
+
+	key := segment[:open]
+	var indexes []string
+	for _, part := range strings.Split(segment[open:], "[")[1:] {
+		indexes = append(indexes, strings.TrimSuffix(part, "]"))
+	}
+	return key, indexes
+}
+
+func applyIndexes(node any, indexes []string) []any {
+	nodes := []any{node}
+	for _, index := range indexes {
+		var next []any
+		for _, n := range nodes {
+			arr, ok := n.([]any)
+			if !ok {
+				continue
+			}
+			if index == "*" {
+				next = append(next, arr...)
+				continue
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 || i >= len(arr) {
+				continue
+			}
+			next = append(next, arr[i])
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// validMatchOp reports whether compareValues supports op, so a misspelled op
+// fails validation instead of never matching.
+func validMatchOp(op string) bool {
+	switch op {
+	case "eq", "ne", "in", "contains", "gt", "gte", "lt", "lte":
+		return true
+	}
+	return false
+}
+
+func compareValues(actual any, op string, expected any) bool {
+	switch op {
+	case "eq":
+		return reflect.DeepEqual(actual, expected)
+	case "ne":
+		return !reflect.DeepEqual(actual, expected)
+	case "in":
+		values, ok := expected.([]any)
+		if !ok {
+			return false
+		}
+		for _, v := range values {
+			if reflect.DeepEqual(actual, v) {
+				return true
+			}
+		}
+		return false
+	case "contains":
+		switch a := actual.(type) {
+		case string:
+			s, ok := expected.(string)
+			return ok && strings.Contains(a, s)
+		case []any:
+			for _, v := range a {
+				if reflect.DeepEqual(v, expected) {
+					return true
+				}
+			}
+		}
+		return false
+	case "gt", "gte", "lt", "lte":
+		cmp, ok := orderValues(actual, expected)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "gt":
+			return cmp > 0
+		case "gte":
+			return cmp >= 0
+		case "lt":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// orderValues compares two numbers or two strings, returning -1, 0 or 1.
+func orderValues(actual, expected any) (int, bool) {
+	switch a := actual.(type) {
+	case float64:
+		e, ok := expected.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < e:
+			return -1, true
+		case a > e:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		e, ok := expected.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, e), true
+	}
+	return 0, false
+}
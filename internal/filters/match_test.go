@@ -0,0 +1,150 @@
+// Package filters tests generic path-based match conditions.
+//
+// Tests cover:
+// - Path resolution with nested keys, indexes, and wildcards
+// - eq, in, contains, and gt operators
+// - Conditions combined with first-class filters
+// - The raw finding parsed once and shared across rules
+// - Uses fixtures/samples.json for realistic OCSF findings
+package filters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+func loadSampleFinding(t *testing.T, index int) *events.SecurityHubV2Finding {
+	t.Helper()
+
+	path := filepath.Join("..", "..", "fixtures", "samples.json")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+
+	var findings []json.RawMessage
+	if err := json.Unmarshal(raw, &findings); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+
+	if len(findings) <= index {
+		t.Fatalf("expected at least %d findings, got %d", index+1, len(findings))
+	}
+
+	finding, err := events.NewSecurityHubFinding(findings[index])
+	if err != nil {
+		t.Fatalf("failed to parse finding %d: %v", index, err)
+	}
+
+	return finding
+}
+
+// TestFilterEngine_MatchConditions validates path-based conditions against
+// the runs-on.com GuardDuty finding (fixtures/samples.json finding #3).
+func TestFilterEngine_MatchConditions(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	tests := []struct {
+		name     string
+		cond     string
+		expected bool
+	}{
+		{"eq nested string", `{"path": "metadata.product.feature.name", "op": "eq", "value": "RuntimeMonitoring"}`, true},
+		{"eq mismatch", `{"path": "metadata.product.feature.name", "op": "eq", "value": "S3Protection"}`, false},
+		{"eq with root prefix", `{"path": "$.cloud.account.name", "op": "eq", "value": "core-tools"}`, true},
+		{"in list", `{"path": "severity", "op": "in", "value": ["Low", "Medium"]}`, true},
+		{"in list mismatch", `{"path": "severity", "op": "in", "value": ["Critical", "High"]}`, false},
+		{"contains array element", `{"path": "finding_info.types", "op": "contains", "value": "Threats"}`, true},
+		{"contains substring", `{"path": "finding_info.desc", "op": "contains", "value": "host directory"}`, true},
+		{"wildcard index", `{"path": "resources[*].tags[*].value", "op": "eq", "value": "runs-on.com"}`, true},
+		{"fixed index", `{"path": "resources[1].type", "op": "eq", "value": "AWS::EC2::Instance"}`, true},
+		{"index out of range", `{"path": "resources[5].type", "op": "eq", "value": "AWS::EC2::Instance"}`, false},
+		{"gt number", `{"path": "severity_id", "op": "gt", "value": 2}`, true},
+		{"gt number mismatch", `{"path": "severity_id", "op": "gt", "value": 3}`, false},
+		{"missing path", `{"path": "finding_info.does_not_exist", "op": "eq", "value": "x"}`, false},
+		{"unknown op", `{"path": "severity", "op": "like", "value": "Medium"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cond MatchCondition
+			if err := json.Unmarshal([]byte(tt.cond), &cond); err != nil {
+				t.Fatalf("failed to parse condition: %v", err)
+			}
+
+			engine := NewFilterEngine([]AutoCloseRule{
+				{
+					Name:    "match-rule",
+					Enabled: true,
+					Filters: RuleFilters{Match: []MatchCondition{cond}},
+				},
+			})
+
+			_, matched := engine.FindMatchingRule(finding)
+			if matched != tt.expected {
+				t.Errorf("expected matched=%v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+// TestFilterEngine_MatchConditions_CombinedWithFilters validates that match
+// conditions use AND logic alongside first-class filters.
+func TestFilterEngine_MatchConditions_CombinedWithFilters(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	rules := []AutoCloseRule{
+		{
+			Name:    "combined-rule",
+			Enabled: true,
+			Filters: RuleFilters{
+				ProductName: []string{"GuardDuty"},
+				Match: []MatchCondition{
+					{Path: "finding_info.uid_alt", Op: "eq", Value: "d6cd5459c241b902f2aaff9db607c941"},
+					{Path: "count", Op: "gt", Value: float64(5)},
+				},
+			},
+		},
+	}
+
+	engine := NewFilterEngine(rules)
+
+	if _, matched := engine.FindMatchingRule(finding); matched {
+		t.Error("finding should not match when one condition fails")
+	}
+
+	rules[0].Filters.Match[1].Value = float64(0)
+	if _, matched := engine.FindMatchingRule(finding); !matched {
+		t.Error("finding should match when all conditions pass")
+	}
+}
+
+// TestFilterEngine_MatchConditions_ParsedOnce validates that every rule sees
+// the raw document parsed for the first rule with match conditions.
+func TestFilterEngine_MatchConditions_ParsedOnce(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	engine := NewFilterEngine([]AutoCloseRule{
+		{Name: "s3", Enabled: true, Filters: RuleFilters{Match: []MatchCondition{
+			{Path: "metadata.product.feature.name", Op: "eq", Value: "S3Protection"},
+		}}},
+		{Name: "runtime", Enabled: true, Filters: RuleFilters{Match: []MatchCondition{
+			{Path: "metadata.product.feature.name", Op: "eq", Value: "RuntimeMonitoring"},
+		}}},
+	})
+
+	ev := engine.evaluation(finding)
+	if matchesFilters(finding, engine.Rules[0].Filters, ev) {
+		t.Fatal("expected the first rule not to match")
+	}
+
+	// a second parse would fail on this
+	ev.raw.raw = json.RawMessage("not json")
+	if !matchesFilters(finding, engine.Rules[1].Filters, ev) {
+		t.Error("expected the second rule to match the cached document")
+	}
+}
@@ -1,6 +1,8 @@
 package filters
 
 import (
+	"strings"
+
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
 )
 
@@ -15,6 +17,60 @@ func matchesFindingTypes(finding *events.SecurityHubV2Finding, types []string) b
 	return false
 }
 
+// matchesFeatureNames checks the metadata and finding_info product feature
+// names. findings without a feature never match.
+func matchesFeatureNames(finding *events.SecurityHubV2Finding, names []string) bool {
+	if feature := finding.Metadata.Product.Feature; feature != nil && contains(names, feature.Name) {
+		return true
+	}
+	if product := finding.FindingInfo.Product; product != nil && product.Feature != nil && contains(names, product.Feature.Name) {
+		return true
+	}
+	return false
+}
+
+// matchesProductARNs checks the product arn globs against the metadata and
+// finding_info product uids.
+func matchesProductARNs(finding *events.SecurityHubV2Finding, patterns []string) bool {
+	if MatchesAnyGlob(patterns, finding.Metadata.Product.UID) {
+		return true
+	}
+	if product := finding.FindingInfo.Product; product != nil && MatchesAnyGlob(patterns, product.UID) {
+		return true
+	}
+	return false
+}
+
+// matchesComplianceControls checks the compliance control id. findings
+// without compliance data never match.
+func matchesComplianceControls(finding *events.SecurityHubV2Finding, controls []string) bool {
+	return finding.Compliance != nil && contains(controls, finding.Compliance.Control)
+}
+
+// matchesComplianceRequirements checks whether any of the finding's
+// compliance requirements is listed.
+func matchesComplianceRequirements(finding *events.SecurityHubV2Finding, requirements []string) bool {
+	if finding.Compliance == nil {
+		return false
+	}
+	for _, requirement := range finding.Compliance.Requirements {
+		if contains(requirements, requirement) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDescPattern checks the finding description against the pattern.
+// findings without a description never match.
+func matchesDescPattern(finding *events.SecurityHubV2Finding, pattern *Pattern) bool {
+	desc := finding.FindingInfo.Desc
+	if desc == "" || pattern.Regexp == nil {
+		return false
+	}
+	return pattern.MatchString(desc)
+}
+
 func matchesResourceTypes(finding *events.SecurityHubV2Finding, types []string) bool {
 	for _, resource := range finding.Resources {
 		for _, filterType := range types {
@@ -26,33 +82,121 @@ func matchesResourceTypes(finding *events.SecurityHubV2Finding, types []string)
 	return false
 }
 
-func matchesResourceTags(finding *events.SecurityHubV2Finding, tagFilters []ResourceTagFilter) bool {
+func matchesResourceTags(finding *events.SecurityHubV2Finding, tagFilters []ResourceTagFilter, minMatches int) bool {
 	if len(finding.Resources) == 0 {
 		return false
 	}
 
+	tagFilters = normalizeTagFilters(tagFilters)
+	if minMatches <= 0 || minMatches > len(tagFilters) {
+		minMatches = len(tagFilters)
+	}
+
 	for _, resource := range finding.Resources {
-		if resourceHasAllTags(resource.Tags, tagFilters) {
+		if resourceHasAtLeastNTags(normalizeTags(resource.Tags), tagFilters, minMatches) {
 			return true
 		}
 	}
 	return false
 }
 
-func resourceHasAllTags(resourceTags []events.ResourceTag, tagFilters []ResourceTagFilter) bool {
+// normalizeTags trims whitespace from tag names and values and drops
+// duplicates. case is preserved.
+func normalizeTags(tags []events.ResourceTag) []events.ResourceTag {
+	seen := make(map[events.ResourceTag]bool, len(tags))
+	normalized := make([]events.ResourceTag, 0, len(tags))
+	for _, tag := range tags {
+		tag.Name = strings.TrimSpace(tag.Name)
+		tag.Value = strings.TrimSpace(tag.Value)
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func normalizeTagFilters(tagFilters []ResourceTagFilter) []ResourceTagFilter {
+	seen := make(map[ResourceTagFilter]bool, len(tagFilters))
+	normalized := make([]ResourceTagFilter, 0, len(tagFilters))
+	for _, f := range tagFilters {
+		f.Name = strings.TrimSpace(f.Name)
+		f.Value = strings.TrimSpace(f.Value)
+		if !seen[f] {
+			seen[f] = true
+			normalized = append(normalized, f)
+		}
+	}
+	return normalized
+}
+
+// matches reports whether tag has the filter's name, or a name starting with
+// its prefix, and exactly the filter's value.
+func (f ResourceTagFilter) matches(tag events.ResourceTag) bool {
+	if tag.Value != f.Value {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(f.Name, "*"); ok {
+		return strings.HasPrefix(tag.Name, prefix)
+	}
+	return tag.Name == f.Name
+}
+
+func resourceHasAtLeastNTags(resourceTags []events.ResourceTag, tagFilters []ResourceTagFilter, n int) bool {
+	matched := 0
 	for _, filterTag := range tagFilters {
-		found := false
 		for _, tag := range resourceTags {
-			if tag.Name == filterTag.Name && tag.Value == filterTag.Value {
-				found = true
+			if filterTag.matches(tag) {
+				matched++
 				break
 			}
 		}
-		if !found {
+		if matched >= n {
+			return true
+		}
+	}
+	return matched >= n
+}
+
+// MatchesAnyGlob reports whether a non-empty value matches any of the glob
+// patterns.
+func MatchesAnyGlob(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if globMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether value matches pattern, where * matches any run of
+// characters (including /) and ? matches a single character.
+func globMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star != -1:
+			p = star + 1
+			mark++
+			v = mark
+		default:
 			return false
 		}
 	}
-	return true
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
 }
 
 func contains(slice []string, item string) bool {
@@ -0,0 +1,35 @@
+package filters
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Pattern is a regular expression written as a string and compiled when the
+// rule is loaded, so a bad pattern fails the load instead of every match.
+type Pattern struct {
+	*regexp.Regexp
+}
+
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Wrap(err, "pattern must be a string")
+	}
+
+	re, err := regexp.Compile(raw)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pattern %q", raw)
+	}
+	p.Regexp = re
+	return nil
+}
+
+func (p Pattern) MarshalJSON() ([]byte, error) {
+	if p.Regexp == nil {
+		return json.Marshal("")
+	}
+	return json.Marshal(p.String())
+}
@@ -0,0 +1,220 @@
+package filters
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// queryListFields maps the list filters a query can set, by json name, to
+// their field in RuleFilters, in the order String writes them.
+var queryListFields = []struct {
+	name  string
+	field func(f *RuleFilters) *[]string
+}{
+	{"finding_types", func(f *RuleFilters) *[]string { return &f.FindingTypes }},
+	{"severity", func(f *RuleFilters) *[]string { return &f.Severity }},
+	{"product_name", func(f *RuleFilters) *[]string { return &f.ProductName }},
+	{"product_arns", func(f *RuleFilters) *[]string { return &f.ProductARNs }},
+	{"feature_names", func(f *RuleFilters) *[]string { return &f.FeatureNames }},
+	{"resource_types", func(f *RuleFilters) *[]string { return &f.ResourceTypes }},
+	{"accounts", func(f *RuleFilters) *[]string { return &f.Accounts }},
+	{"regions", func(f *RuleFilters) *[]string { return &f.Regions }},
+	{"finding_uids", func(f *RuleFilters) *[]string { return &f.FindingUIDs }},
+	{"finding_uid_alts", func(f *RuleFilters) *[]string { return &f.FindingUIDAlts }},
+	{"compliance_controls", func(f *RuleFilters) *[]string { return &f.ComplianceControls }},
+	{"compliance_requirements", func(f *RuleFilters) *[]string { return &f.ComplianceRequirements }},
+}
+
+// queryAliases are the short query keys accepted for filter json names.
+var queryAliases = map[string]string{
+	"type":     "finding_types",
+	"types":    "finding_types",
+	"product":  "product_name",
+	"arn":      "product_arns",
+	"feature":  "feature_names",
+	"resource": "resource_types",
+	"account":  "accounts",
+	"region":   "regions",
+	"uid":      "finding_uids",
+	"uid_alt":  "finding_uid_alts",
+	"control":  "compliance_controls",
+	"req":      "compliance_requirements",
+	"tag":      "resource_tags",
+	"tags":     "resource_tags",
+}
+
+// ParseQuery builds filters from a compact query such as
+// "severity=Low,Medium;product=Inspector;region=us-*". keys are filter json
+// names or their short aliases, values are comma-separated, and tags are
+// written as name:value. match conditions are not supported.
+func ParseQuery(query string) (RuleFilters, error) {
+	var f RuleFilters
+	seen := make(map[string]bool)
+
+	for _, clause := range strings.Split(query, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(clause, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if alias, ok := queryAliases[key]; ok {
+			key = alias
+		}
+		if !ok || key == "" {
+			return RuleFilters{}, errors.Newf("invalid clause %q (expected key=value)", clause)
+		}
+		if seen[key] {
+			return RuleFilters{}, errors.Newf("filter %q is set more than once", key)
+		}
+		seen[key] = true
+
+		values := splitQueryValues(value)
+		if len(values) == 0 {
+			return RuleFilters{}, errors.Newf("filter %q has no value", key)
+		}
+
+		if err := setQueryFilter(&f, key, values); err != nil {
+			return RuleFilters{}, err
+		}
+	}
+
+	if f.IsEmpty() {
+		return RuleFilters{}, errors.New("query sets no filters")
+	}
+	return f, nil
+}
+
+func setQueryFilter(f *RuleFilters, key string, values []string) error {
+	for _, lf := range queryListFields {
+		if lf.name == key {
+			*lf.field(f) = values
+			return nil
+		}
+	}
+
+	single := func() (string, error) {
+		if len(values) != 1 {
+			return "", errors.Newf("filter %q takes a single value", key)
+		}
+		return values[0], nil
+	}
+
+	switch key {
+	case "resource_tags":
+		for _, v := range values {
+			name, value, ok := strings.Cut(v, ":")
+			if !ok || name == "" {
+				return errors.Newf("invalid tag %q (expected name:value)", v)
+			}
+			f.ResourceTags = append(f.ResourceTags, ResourceTagFilter{Name: name, Value: value})
+		}
+	case "resource_tags_min_matches":
+		v, err := single()
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.Newf("invalid resource_tags_min_matches %q", v)
+		}
+		f.ResourceTagsMinMatches = n
+	case "min_age", "max_age":
+		v, err := single()
+		if err != nil {
+			return err
+		}
+		d, err := parseAge(v)
+		if err != nil {
+			return err
+		}
+		if key == "min_age" {
+			f.MinAge = Age(d)
+		} else {
+			f.MaxAge = Age(d)
+		}
+	case "age_basis":
+		v, err := single()
+		if err != nil {
+			return err
+		}
+		if !validAgeBasis(v) {
+			return errors.Newf("unknown age_basis %q", v)
+		}
+		f.AgeBasis = v
+	case "recurrence":
+		v, err := single()
+		if err != nil {
+			return err
+		}
+		if v != RecurrenceNew && v != RecurrenceRecurring {
+			return errors.Newf("unknown recurrence %q", v)
+		}
+		f.Recurrence = v
+	default:
+		return errors.Newf("unknown filter %q (expected one of %s)", key, strings.Join(QueryKeys(), ", "))
+	}
+	return nil
+}
+
+// QueryKeys returns the filter json names a query can set.
+func QueryKeys() []string {
+	keys := make([]string, 0, len(queryListFields)+6)
+	for _, lf := range queryListFields {
+		keys = append(keys, lf.name)
+	}
+	keys = append(keys, "resource_tags", "resource_tags_min_matches", "min_age", "max_age", "age_basis", "recurrence")
+	slices.Sort(keys)
+	return keys
+}
+
+func splitQueryValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Query returns the filters as a query ParseQuery accepts, using json names.
+// match conditions are left out.
+func (f RuleFilters) Query() string {
+	var clauses []string
+	for _, lf := range queryListFields {
+		if values := *lf.field(&f); len(values) > 0 {
+			clauses = append(clauses, lf.name+"="+strings.Join(values, ","))
+		}
+	}
+
+	if len(f.ResourceTags) > 0 {
+		tags := make([]string, len(f.ResourceTags))
+		for i, tag := range f.ResourceTags {
+			tags[i] = tag.Name + ":" + tag.Value
+		}
+		clauses = append(clauses, "resource_tags="+strings.Join(tags, ","))
+	}
+	if f.ResourceTagsMinMatches > 0 {
+		clauses = append(clauses, "resource_tags_min_matches="+strconv.Itoa(f.ResourceTagsMinMatches))
+	}
+	if f.MinAge > 0 {
+		clauses = append(clauses, "min_age="+time.Duration(f.MinAge).String())
+	}
+	if f.MaxAge > 0 {
+		clauses = append(clauses, "max_age="+time.Duration(f.MaxAge).String())
+	}
+	if f.AgeBasis != "" {
+		clauses = append(clauses, "age_basis="+f.AgeBasis)
+	}
+	if f.Recurrence != "" {
+		clauses = append(clauses, "recurrence="+f.Recurrence)
+	}
+
+	return strings.Join(clauses, ";")
+}
@@ -0,0 +1,101 @@
+// Package filters tests building rule filters from compact query strings.
+//
+// Tests cover:
+// - Round-tripping a query through RuleFilters and back
+// - Short key aliases, tags, age and recurrence values
+// - Parsed filters matching a fixture finding, including region globs
+// - Rejection of unknown keys, repeated keys and malformed clauses
+// - Uses fixtures/samples.json for realistic OCSF findings
+package filters
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/clock"
+)
+
+// TestParseQuery_RoundTrip validates that a query parses into the expected
+// filters and that Query writes it back in canonical form.
+func TestParseQuery_RoundTrip(t *testing.T) {
+	f, err := ParseQuery("severity=Low, Medium; product=Inspector;region=us-*;tag=team:platform,env:dev;min_age=7d;recurrence=recurring")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(f.Severity, []string{"Low", "Medium"}) ||
+		!slices.Equal(f.ProductName, []string{"Inspector"}) ||
+		!slices.Equal(f.Regions, []string{"us-*"}) ||
+		len(f.ResourceTags) != 2 || f.ResourceTags[1] != (ResourceTagFilter{Name: "env", Value: "dev"}) ||
+		time.Duration(f.MinAge) != 7*24*time.Hour ||
+		f.Recurrence != RecurrenceRecurring {
+		t.Fatalf("unexpected filters: %+v", f)
+	}
+
+	query := f.Query()
+	expected := "severity=Low,Medium;product_name=Inspector;regions=us-*;resource_tags=team:platform,env:dev;min_age=168h0m0s;recurrence=recurring"
+	if query != expected {
+		t.Errorf("expected %q, got %q", expected, query)
+	}
+
+	again, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error re-parsing %q: %v", query, err)
+	}
+	if again.Query() != query {
+		t.Errorf("expected a stable round trip, got %q", again.Query())
+	}
+}
+
+// TestParseQuery_MatchesFinding validates parsed filters against the
+// runs-on.com fixture finding.
+func TestParseQuery_MatchesFinding(t *testing.T) {
+	finding := loadSampleFinding(t, 2)
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"product=GuardDuty;region=us-*;tag=provider:runs-on.com", true},
+		{"severity=Medium;account=123456789012;feature=RuntimeMonitoring", true},
+		{"product=GuardDuty;region=eu-*", false},
+		{"severity=Low,High", false},
+		{"arn=arn:aws:securityhub:*::productv2/aws/guardduty", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			rule := AutoCloseRule{Name: "adhoc", Enabled: true, Filters: f}
+			if got := rule.Matches(finding, clock.Real{}); got != tt.expected {
+				t.Errorf("expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestParseQuery_Invalid validates that malformed queries are rejected.
+func TestParseQuery_Invalid(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"colour=red",
+		"severity",
+		"severity=",
+		"severity=Low;severity=High",
+		"region=us-east-1;regions=us-west-2",
+		"tag=provider",
+		"min_age=soon",
+		"age_basis=yesterday",
+		"recurrence=sometimes",
+		"max_age=1d,2d",
+		"match=count",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
@@ -1,29 +1,374 @@
 package filters
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/locale"
+)
+
 type AutoCloseRule struct {
 	Name             string      `json:"name"`
 	Enabled          bool        `json:"enabled"`
 	Filters          RuleFilters `json:"filters"`
 	Action           RuleAction  `json:"action"`
 	SkipNotification bool        `json:"skip_notification"`
+	MatchAll         bool        `json:"match_all,omitempty"`
+	ConsoleRegion    string      `json:"console_region,omitempty"`
+
+	// Notifiers limits the rule's notifications to these APP_NOTIFIER
+	// backends. empty means every backend
+	Notifiers []string `json:"notifiers,omitempty"`
+
+	// audit metadata, ignored by matching
+	Owner  string `json:"owner,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RuleVarPrefix is the prefix an environment variable needs to be referenced
+// from a rule. other variables, such as credentials, are never expanded.
+const RuleVarPrefix = "APP_RULE_VAR_"
+
+// envRefPattern matches ${NAME} references to environment variables.
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${APP_RULE_VAR_*} references using lookup. unset
+// variables, other references and any other text, including a bare $NAME,
+// are left as-is.
+func expandEnv(s string, lookup func(string) (string, bool)) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if !strings.HasPrefix(name, RuleVarPrefix) {
+			return ref
+		}
+		if value, ok := lookup(name); ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// UnexpandedEnvRefs returns the ${NAME} references without the RuleVarPrefix
+// in the action comment and the owner, ticket and reason, as "${NAME} in
+// field". ExpandEnv leaves them as-is.
+func (r *AutoCloseRule) UnexpandedEnvRefs() []string {
+	var refs []string
+	for _, field := range []struct{ name, value string }{
+		{"comment", r.Action.Comment}, {"owner", r.Owner}, {"ticket", r.Ticket}, {"reason", r.Reason},
+	} {
+		for _, m := range envRefPattern.FindAllStringSubmatch(field.value, -1) {
+			if !strings.HasPrefix(m[1], RuleVarPrefix) {
+				refs = append(refs, m[0]+" in "+field.name)
+			}
+		}
+	}
+	return refs
+}
+
+// ExpandEnv returns a copy of the rules with ${APP_RULE_VAR_*} references in
+// the action comment and the owner, ticket and reason replaced using lookup,
+// such as os.LookupEnv, so shared rule files can name deployment-specific
+// values.
+func ExpandEnv(rules []AutoCloseRule, lookup func(string) (string, bool)) []AutoCloseRule {
+	expanded := make([]AutoCloseRule, len(rules))
+	for i, r := range rules {
+		r.Action.Comment = expandEnv(r.Action.Comment, lookup)
+		r.Owner = expandEnv(r.Owner, lookup)
+		r.Ticket = expandEnv(r.Ticket, lookup)
+		r.Reason = expandEnv(r.Reason, lookup)
+		expanded[i] = r
+	}
+	return expanded
+}
+
+// TicketPlaceholder is replaced with the rule's ticket in a ticket url
+// template.
+const TicketPlaceholder = "{ticket}"
+
+// TicketURL returns a link for the rule's ticket: the ticket itself when it's
+// a url, otherwise the template with TicketPlaceholder replaced. it's empty
+// when there's nothing to link.
+func (r *AutoCloseRule) TicketURL(template string) string {
+	if r.Ticket == "" {
+		return ""
+	}
+	if strings.HasPrefix(r.Ticket, "https://") || strings.HasPrefix(r.Ticket, "http://") {
+		return r.Ticket
+	}
+	if template == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, TicketPlaceholder, url.PathEscape(r.Ticket))
+}
+
+// ActionComment returns the action comment with the rule's owner and ticket
+// appended, if set, labeled in loc. the ticket is written as its link when
+// ticketURLTemplate or the ticket itself makes one.
+func (r *AutoCloseRule) ActionComment(ticketURLTemplate string, loc locale.Locale) string {
+	var refs []string
+	if r.Owner != "" {
+		refs = append(refs, loc.Text(locale.Owner)+": "+r.Owner)
+	}
+	if link := r.TicketURL(ticketURLTemplate); link != "" {
+		refs = append(refs, loc.Text(locale.Ticket)+": "+link)
+	} else if r.Ticket != "" {
+		refs = append(refs, loc.Text(locale.Ticket)+": "+r.Ticket)
+	}
+	if len(refs) == 0 {
+		return r.Action.Comment
+	}
+
+	suffix := "(" + strings.Join(refs, ", ") + ")"
+	if r.Action.Comment == "" {
+		return suffix
+	}
+	return r.Action.Comment + " " + suffix
+}
+
+// Validate rejects unknown age bases and recurrences, invalid resource count
+// bounds, and enabled rules with no filters unless match_all opts in, since
+// such a rule would close every finding. every problem with the rule is
+// reported.
+func (r *AutoCloseRule) Validate() error {
+	var v ValidationError
+	r.validate(&v)
+	return v.Err()
+}
+
+func (r *AutoCloseRule) validate(v *ValidationError) {
+	if !validAgeBasis(r.Filters.AgeBasis) {
+		v.Add(r.Name, "has unknown age_basis %q (expected 'first_seen', 'last_seen', 'created' or 'modified')", r.Filters.AgeBasis)
+	}
+	switch r.Filters.Recurrence {
+	case "", RecurrenceNew, RecurrenceRecurring:
+	default:
+		v.Add(r.Name, "has unknown recurrence %q (expected 'new' or 'recurring')", r.Filters.Recurrence)
+	}
+	if r.Filters.MinResources < 0 || r.Filters.MaxResources < 0 {
+		v.Add(r.Name, "has a negative min_resources or max_resources")
+	}
+	if r.Filters.MaxResources > 0 && r.Filters.MinResources > r.Filters.MaxResources {
+		v.Add(r.Name, "has min_resources %d above max_resources %d", r.Filters.MinResources, r.Filters.MaxResources)
+	}
+	for _, c := range r.Filters.Match {
+		if !validMatchOp(c.Op) {
+			v.Add(r.Name, "has unknown match op %q for path %q (expected 'eq', 'ne', 'in', 'contains', 'gt', 'gte', 'lt' or 'lte')", c.Op, c.Path)
+		}
+	}
+	if r.Enabled && !r.MatchAll && r.Filters.IsEmpty() {
+		v.Add(r.Name, "has no filters and would match every finding (set match_all to opt in)")
+	}
+}
+
+// ValidateRules reports every problem across the rules at once, so operators
+// can fix them in one pass.
+func ValidateRules(rules []AutoCloseRule) error {
+	return RuleProblems(rules).Err()
+}
+
+// RuleProblems collects the problems with every rule. callers can add their
+// own checks before calling Err.
+func RuleProblems(rules []AutoCloseRule) *ValidationError {
+	v := &ValidationError{}
+	for i := range rules {
+		rules[i].validate(v)
+	}
+	return v
+}
+
+// RuleProblem is one issue with one rule.
+type RuleProblem struct {
+	Rule    string
+	Problem string
+}
+
+func (p RuleProblem) String() string {
+	return fmt.Sprintf("rule %q %s", p.Rule, p.Problem)
+}
+
+// ValidationError lists every problem found in a rule set.
+type ValidationError struct {
+	Problems []RuleProblem
+}
+
+// Add records a problem with the named rule.
+func (v *ValidationError) Add(rule, format string, args ...any) {
+	v.Problems = append(v.Problems, RuleProblem{Rule: rule, Problem: fmt.Sprintf(format, args...)})
+}
+
+// Err returns the validation error, or nil when there are no problems.
+func (v *ValidationError) Err() error {
+	if len(v.Problems) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v *ValidationError) Error() string {
+	if len(v.Problems) == 1 {
+		return v.Problems[0].String()
+	}
+
+	lines := make([]string, len(v.Problems))
+	for i, p := range v.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("%d problems: %s", len(v.Problems), strings.Join(lines, "; "))
 }
 
 type RuleFilters struct {
-	FindingTypes  []string            `json:"finding_types,omitempty"`
-	Severity      []string            `json:"severity,omitempty"`
-	ProductName   []string            `json:"product_name,omitempty"`
-	ResourceTypes []string            `json:"resource_types,omitempty"`
-	ResourceTags  []ResourceTagFilter `json:"resource_tags,omitempty"`
-	Accounts      []string            `json:"accounts,omitempty"`
-	Regions       []string            `json:"regions,omitempty"`
+	FindingTypes           []string            `json:"finding_types,omitempty"`
+	Severity               []string            `json:"severity,omitempty"`
+	ProductName            []string            `json:"product_name,omitempty"`
+	ProductARNs            []string            `json:"product_arns,omitempty"`
+	FeatureNames           []string            `json:"feature_names,omitempty"`
+	ResourceTypes          []string            `json:"resource_types,omitempty"`
+	ResourceTags           []ResourceTagFilter `json:"resource_tags,omitempty"`
+	ResourceTagsMinMatches int                 `json:"resource_tags_min_matches,omitempty"`
+	MinResources           int                 `json:"min_resources,omitempty"`
+	MaxResources           int                 `json:"max_resources,omitempty"`
+	Accounts               []string            `json:"accounts,omitempty"`
+	Regions                []string            `json:"regions,omitempty"`
+	FindingUIDs            []string            `json:"finding_uids,omitempty"`
+	FindingUIDAlts         []string            `json:"finding_uid_alts,omitempty"`
+	ComplianceControls     []string            `json:"compliance_controls,omitempty"`
+	ComplianceRequirements []string            `json:"compliance_requirements,omitempty"`
+	ActivityIDs            []int               `json:"activity_ids,omitempty"`
+	ActivityNames          []string            `json:"activity_names,omitempty"`
+	TypeUIDs               []int               `json:"type_uids,omitempty"`
+	DescPattern            *Pattern            `json:"desc_pattern,omitempty"`
+	MinAge                 Age                 `json:"min_age,omitempty"`
+	MaxAge                 Age                 `json:"max_age,omitempty"`
+	AgeBasis               string              `json:"age_basis,omitempty"`
+	Recurrence             string              `json:"recurrence,omitempty"`
+	Match                  []MatchCondition    `json:"match,omitempty"`
+}
+
+// recurrence filter values: a finding uid seen for the first time is new, and
+// one processed before is recurring
+const (
+	RecurrenceNew       = "new"
+	RecurrenceRecurring = "recurring"
+)
+
+// IsEmpty reports whether no filter is set, in which case every finding matches.
+func (f RuleFilters) IsEmpty() bool {
+	return len(f.FindingTypes) == 0 &&
+		len(f.Severity) == 0 &&
+		len(f.ProductName) == 0 &&
+		len(f.ProductARNs) == 0 &&
+		len(f.FeatureNames) == 0 &&
+		len(f.ResourceTypes) == 0 &&
+		len(f.ResourceTags) == 0 &&
+		f.MinResources == 0 &&
+		f.MaxResources == 0 &&
+		len(f.Accounts) == 0 &&
+		len(f.Regions) == 0 &&
+		len(f.FindingUIDs) == 0 &&
+		len(f.FindingUIDAlts) == 0 &&
+		len(f.ComplianceControls) == 0 &&
+		len(f.ComplianceRequirements) == 0 &&
+		len(f.ActivityIDs) == 0 &&
+		len(f.ActivityNames) == 0 &&
+		len(f.TypeUIDs) == 0 &&
+		f.DescPattern == nil &&
+		f.MinAge == 0 &&
+		f.MaxAge == 0 &&
+		f.Recurrence == "" &&
+		len(f.Match) == 0
 }
 
+// ResourceTagFilter matches a resource tag by name and value. a name ending in
+// * matches any tag name with that prefix, e.g. "aws:cloudformation:*".
 type ResourceTagFilter struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
 }
 
+// MatchCondition compares the value at a path in the raw finding json, such as
+// "finding_info.types[*]" or "resources[0].data.ami", using op.
+type MatchCondition struct {
+	Path  string `json:"path"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
 type RuleAction struct {
+	Type     string `json:"type,omitempty"`
 	StatusID int32  `json:"status_id"`
+	Status   string `json:"status,omitempty"`
 	Comment  string `json:"comment"`
+
+	// CloseAfterSeconds notifies on the first match and only closes when the
+	// finding matches again this long after the bot first saw it
+	CloseAfterSeconds int64 `json:"close_after_seconds,omitempty"`
+}
+
+const (
+	ActionTypeClose = "close"
+
+	// ActionTypeObserve records matches without updating the finding, for
+	// staging a rule before it is allowed to close anything.
+	ActionTypeObserve = "observe"
+)
+
+// Observe reports whether the action only records matches.
+func (a RuleAction) Observe() bool {
+	return a.Type == ActionTypeObserve
+}
+
+// CloseDelay returns the grace period before a matched finding is closed.
+func (a RuleAction) CloseDelay() time.Duration {
+	return time.Duration(a.CloseAfterSeconds) * time.Second
+}
+
+// StatusPresets maps action status names to OCSF status ids. security hub v2
+// has no separate reason field, so false positives and benign findings are
+// both suppressed.
+var StatusPresets = map[string]int32{
+	"suppressed":     3,
+	"false_positive": 3,
+	"benign":         3,
+	"resolved":       4,
+}
+
+func (a *RuleAction) UnmarshalJSON(data []byte) error {
+	type ruleAction RuleAction
+	var raw ruleAction
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch raw.Type {
+	case "", ActionTypeClose, ActionTypeObserve:
+	default:
+		return errors.Newf("unknown action type %q (expected 'close' or 'observe')", raw.Type)
+	}
+
+	if raw.CloseAfterSeconds < 0 {
+		return errors.Newf("invalid close_after_seconds %d", raw.CloseAfterSeconds)
+	}
+
+	if raw.Status != "" {
+		statusID, ok := StatusPresets[raw.Status]
+		if !ok {
+			return errors.Newf("unknown action status %q", raw.Status)
+		}
+		if raw.StatusID != 0 && raw.StatusID != statusID {
+			return errors.Newf("action status %q conflicts with status_id %d", raw.Status, raw.StatusID)
+		}
+		raw.StatusID = statusID
+	}
+
+	*a = RuleAction(raw)
+	return nil
 }
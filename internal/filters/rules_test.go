@@ -0,0 +1,447 @@
+// Package filters tests auto-close rule parsing.
+//
+// Tests cover:
+// - Action status presets resolving to OCSF status ids
+// - Unknown and conflicting status presets
+// - Close and observe action types, and close delay validation
+// - Age durations and age basis validation
+// - Rejection of match-everything rules without opt-in, and unknown recurrences
+// - Resource count bound validation
+// - Unknown match condition ops
+// - Every problem across a rule set reported in one error
+// - Environment variable expansion in comments and audit metadata
+// - References to variables without the APP_RULE_VAR_ prefix reported but accepted
+// - Audit metadata in action comments, labeled in the configured locale
+// - Ticket links from url templates and url tickets
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/locale"
+)
+
+// TestRuleAction_StatusPresets validates that each named status preset
+// resolves to the expected OCSF status id.
+func TestRuleAction_StatusPresets(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected int32
+	}{
+		{"false_positive", 3},
+		{"benign", 3},
+		{"suppressed", 3},
+		{"resolved", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			var action RuleAction
+			input := `{"status": "` + tt.status + `", "comment": "Test"}`
+			if err := json.Unmarshal([]byte(input), &action); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if action.StatusID != tt.expected {
+				t.Errorf("expected status_id %d, got %d", tt.expected, action.StatusID)
+			}
+
+			if action.Comment != "Test" {
+				t.Errorf("expected comment 'Test', got %s", action.Comment)
+			}
+		})
+	}
+}
+
+// TestRuleAction_StatusIDOnly validates that numeric status ids are still
+// accepted without a preset.
+func TestRuleAction_StatusIDOnly(t *testing.T) {
+	var action RuleAction
+	if err := json.Unmarshal([]byte(`{"status_id": 5}`), &action); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if action.StatusID != 5 {
+		t.Errorf("expected status_id 5, got %d", action.StatusID)
+	}
+}
+
+// TestRuleAction_InvalidStatus validates that unknown presets and presets
+// conflicting with an explicit status_id are rejected.
+func TestRuleAction_InvalidStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown preset", `{"status": "ignored"}`},
+		{"conflicting status_id", `{"status": "resolved", "status_id": 3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var action RuleAction
+			if err := json.Unmarshal([]byte(tt.input), &action); err == nil {
+				t.Error("expected error for invalid status")
+			}
+		})
+	}
+}
+
+// TestRuleAction_Type validates that observe actions are recognized and
+// unknown action types and negative close delays are rejected.
+func TestRuleAction_Type(t *testing.T) {
+	tests := []struct {
+		input   string
+		observe bool
+		wantErr bool
+	}{
+		{`{"status_id": 5}`, false, false},
+		{`{"type": "close", "status_id": 5}`, false, false},
+		{`{"type": "observe"}`, true, false},
+		{`{"type": "observe", "status": "suppressed"}`, true, false},
+		{`{"type": "delete"}`, false, true},
+		{`{"status_id": 5, "close_after_seconds": 86400}`, false, false},
+		{`{"status_id": 5, "close_after_seconds": -1}`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var action RuleAction
+			err := json.Unmarshal([]byte(tt.input), &action)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if action.Observe() != tt.observe {
+				t.Errorf("expected observe=%v, got %v", tt.observe, action.Observe())
+			}
+		})
+	}
+}
+
+// TestRuleFilters_Age validates that ages accept Go durations and whole days,
+// and that unknown age bases are rejected.
+func TestRuleFilters_Age(t *testing.T) {
+	var filters RuleFilters
+	if err := json.Unmarshal([]byte(`{"min_age": "7d", "max_age": "36h", "age_basis": "last_seen"}`), &filters); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if time.Duration(filters.MinAge) != 7*24*time.Hour || time.Duration(filters.MaxAge) != 36*time.Hour {
+		t.Errorf("unexpected ages: %v, %v", time.Duration(filters.MinAge), time.Duration(filters.MaxAge))
+	}
+
+	for _, input := range []string{`{"min_age": "seven days"}`, `{"min_age": "-1d"}`, `{"min_age": 7}`} {
+		if err := json.Unmarshal([]byte(input), &filters); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+
+	rule := AutoCloseRule{Name: "bad-basis", Enabled: true, Filters: RuleFilters{MinAge: Age(time.Hour), AgeBasis: "updated"}}
+	if err := rule.Validate(); err == nil {
+		t.Error("expected error for unknown age_basis")
+	}
+}
+
+// TestAutoCloseRule_Validate_EmptyFilters validates that an enabled rule with
+// no filters is rejected unless match_all is set.
+func TestAutoCloseRule_Validate_EmptyFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"empty filters", `{"name": "r", "enabled": true, "filters": {}, "action": {"status_id": 5}}`, true},
+		{"missing filters", `{"name": "r", "enabled": true, "action": {"status_id": 5}}`, true},
+		{"empty filter lists", `{"name": "r", "enabled": true, "filters": {"severity": [], "accounts": []}}`, true},
+		{"match_all opt-in", `{"name": "r", "enabled": true, "match_all": true, "filters": {}}`, false},
+		{"disabled rule", `{"name": "r", "enabled": false, "filters": {}}`, false},
+		{"only tag threshold", `{"name": "r", "enabled": true, "filters": {"resource_tags_min_matches": 2}}`, true},
+		{"with filter", `{"name": "r", "enabled": true, "filters": {"severity": ["Low"]}}`, false},
+		{"only recurrence", `{"name": "r", "enabled": true, "filters": {"recurrence": "recurring"}}`, false},
+		{"unknown recurrence", `{"name": "r", "enabled": true, "filters": {"severity": ["Low"], "recurrence": "repeat"}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule AutoCloseRule
+			if err := json.Unmarshal([]byte(tt.input), &rule); err != nil {
+				t.Fatalf("failed to parse rule: %v", err)
+			}
+
+			err := rule.Validate()
+			if tt.wantErr && err == nil {
+				t.Error("expected error for match-everything rule")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestAutoCloseRule_Validate_ResourceCount validates that negative and
+// inverted resource count bounds are rejected.
+func TestAutoCloseRule_Validate_ResourceCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		wantErr  bool
+	}{
+		{"min only", 5, 0, false},
+		{"max only", 0, 1, false},
+		{"equal bounds", 3, 3, false},
+		{"inverted bounds", 5, 2, true},
+		{"negative min", -1, 0, true},
+		{"negative max", 0, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := AutoCloseRule{Name: "r", Enabled: true, Filters: RuleFilters{MinResources: tt.min, MaxResources: tt.max}}
+			err := rule.Validate()
+			if tt.wantErr && err == nil {
+				t.Error("expected error for invalid resource count bounds")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestAutoCloseRule_Validate_MatchOp validates that match conditions with an
+// unknown or missing op are rejected instead of never matching.
+func TestAutoCloseRule_Validate_MatchOp(t *testing.T) {
+	tests := []struct {
+		op      string
+		wantErr bool
+	}{
+		{"eq", false},
+		{"contains", false},
+		{"lte", false},
+		{"equals", true},
+		{"GT", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			rule := AutoCloseRule{Name: "r", Enabled: true, Filters: RuleFilters{
+				Match: []MatchCondition{{Path: "count", Op: tt.op, Value: 1}},
+			}}
+			err := rule.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for op %q", tt.op)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateRules validates that a single invalid rule fails the whole set.
+func TestValidateRules(t *testing.T) {
+	rules := []AutoCloseRule{
+		{Name: "valid", Enabled: true, Filters: RuleFilters{Severity: []string{"Low"}}},
+		{Name: "invalid", Enabled: true},
+	}
+
+	if err := ValidateRules(rules); err == nil {
+		t.Error("expected error for rule set containing an empty-filter rule")
+	}
+
+	if err := ValidateRules(rules[:1]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestValidateRules_AllProblems validates that problems in several rules, and
+// several problems in one rule, are all reported in a single error.
+func TestValidateRules_AllProblems(t *testing.T) {
+	rules := []AutoCloseRule{
+		{Name: "valid", Enabled: true, Filters: RuleFilters{Severity: []string{"Low"}}},
+		{Name: "empty", Enabled: true},
+		{Name: "bad-basis", Enabled: true, Filters: RuleFilters{Severity: []string{"Low"}, AgeBasis: "updated"}},
+		{Name: "bad-bounds", Enabled: true, Filters: RuleFilters{MinResources: 5, MaxResources: 2, AgeBasis: "seen"}},
+	}
+
+	err := ValidateRules(rules)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	expected := []RuleProblem{
+		{Rule: "empty", Problem: "has no filters and would match every finding (set match_all to opt in)"},
+		{Rule: "bad-basis", Problem: `has unknown age_basis "updated" (expected 'first_seen', 'last_seen', 'created' or 'modified')`},
+		{Rule: "bad-bounds", Problem: `has unknown age_basis "seen" (expected 'first_seen', 'last_seen', 'created' or 'modified')`},
+		{Rule: "bad-bounds", Problem: "has min_resources 5 above max_resources 2"},
+	}
+	if len(verr.Problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expected), len(verr.Problems), err)
+	}
+	for i, p := range expected {
+		if verr.Problems[i] != p {
+			t.Errorf("problem %d: expected %+v, got %+v", i, p, verr.Problems[i])
+		}
+	}
+
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "4 problems: ") {
+		t.Errorf("expected the problem count in %q", msg)
+	}
+	for _, name := range []string{"empty", "bad-basis", "bad-bounds"} {
+		if !strings.Contains(msg, fmt.Sprintf("rule %q", name)) {
+			t.Errorf("expected %s in %q", name, msg)
+		}
+	}
+}
+
+// TestAutoCloseRule_ActionComment validates that owner and ticket are
+// appended to the action comment only when set.
+func TestAutoCloseRule_ActionComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     AutoCloseRule
+		expected string
+	}{
+		{"no metadata", AutoCloseRule{Action: RuleAction{Comment: "Closed"}}, "Closed"},
+		{"owner only", AutoCloseRule{Owner: "team-a", Action: RuleAction{Comment: "Closed"}}, "Closed (owner: team-a)"},
+		{"owner and ticket", AutoCloseRule{Owner: "team-a", Ticket: "SEC-1", Action: RuleAction{Comment: "Closed"}}, "Closed (owner: team-a, ticket: SEC-1)"},
+		{"no comment", AutoCloseRule{Ticket: "SEC-1"}, "(ticket: SEC-1)"},
+		{"reason not in comment", AutoCloseRule{Reason: "noisy", Action: RuleAction{Comment: "Closed"}}, "Closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.ActionComment("", locale.Locale{}); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	de, err := locale.Parse("de")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rule := AutoCloseRule{Owner: "team-a", Ticket: "SEC-1", Action: RuleAction{Comment: "Accepted risk"}}
+	if got := rule.ActionComment("", de); got != "Accepted risk (Verantwortlich: team-a, Ticket: SEC-1)" {
+		t.Errorf("expected the rule comment verbatim with German labels, got %q", got)
+	}
+
+	rule = AutoCloseRule{}
+	input := `{"name": "r", "owner": "team-a", "ticket": "SEC-1", "reason": "noisy", "action": {"status_id": 3}}`
+	if err := json.Unmarshal([]byte(input), &rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rule.Owner != "team-a" || rule.Ticket != "SEC-1" || rule.Reason != "noisy" {
+		t.Errorf("unexpected audit fields: %+v", rule)
+	}
+}
+
+// TestAutoCloseRule_TicketURL validates ticket links built from the template,
+// tickets that are already urls, and the comment written with the link.
+func TestAutoCloseRule_TicketURL(t *testing.T) {
+	const template = "https://jira.example.com/browse/{ticket}"
+
+	tests := []struct {
+		name     string
+		ticket   string
+		template string
+		expected string
+	}{
+		{"templated", "SEC-123", template, "https://jira.example.com/browse/SEC-123"},
+		{"escaped", "SEC 1/2", template, "https://jira.example.com/browse/SEC%201%2F2"},
+		{"url ticket", "https://tickets.example.com/42", template, "https://tickets.example.com/42"},
+		{"url ticket without template", "https://tickets.example.com/42", "", "https://tickets.example.com/42"},
+		{"no template", "SEC-123", "", ""},
+		{"no ticket", "", template, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := AutoCloseRule{Ticket: tt.ticket}
+			if got := rule.TicketURL(tt.template); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	rule := AutoCloseRule{Owner: "team-a", Ticket: "SEC-123", Action: RuleAction{Comment: "Closed"}}
+	if got := rule.ActionComment(template, locale.Locale{}); got != "Closed (owner: team-a, ticket: https://jira.example.com/browse/SEC-123)" {
+		t.Errorf("unexpected comment with ticket link: %q", got)
+	}
+}
+
+// TestExpandEnv validates that ${APP_RULE_VAR_*} references in the comment and
+// audit metadata are expanded, while other references, literal text and unset
+// variables are left unchanged and the input rules aren't modified.
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{
+		"APP_RULE_VAR_ENVIRONMENT": "staging",
+		"APP_RULE_VAR_REGION":      "eu-west-1",
+		"APP_RULE_VAR_TEAM":        "platform",
+		"AWS_SECRET_ACCESS_KEY":    "secret",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	rules := []AutoCloseRule{
+		{
+			Name:   "expanded",
+			Action: RuleAction{Comment: "Accepted in ${APP_RULE_VAR_ENVIRONMENT} (${APP_RULE_VAR_REGION})"},
+			Owner:  "${APP_RULE_VAR_TEAM}-team",
+			Ticket: "SEC-${APP_RULE_VAR_ENVIRONMENT}",
+			Reason: "noisy in ${APP_RULE_VAR_ENVIRONMENT}",
+		},
+		{Name: "literal", Action: RuleAction{Comment: "Costs $5 per $HOST, see ${APP_RULE_VAR_UNSET} and ${not valid}"}},
+		{Name: "disallowed", Action: RuleAction{Comment: "key ${AWS_SECRET_ACCESS_KEY}"}},
+	}
+
+	expanded := ExpandEnv(rules, lookup)
+
+	got := expanded[0]
+	if got.Action.Comment != "Accepted in staging (eu-west-1)" || got.Owner != "platform-team" || got.Ticket != "SEC-staging" || got.Reason != "noisy in staging" {
+		t.Errorf("unexpected expansion: %+v", got)
+	}
+	if expanded[1].Action.Comment != rules[1].Action.Comment {
+		t.Errorf("expected literal text to be unchanged, got %q", expanded[1].Action.Comment)
+	}
+	if expanded[2].Action.Comment != "key ${AWS_SECRET_ACCESS_KEY}" {
+		t.Errorf("expected a reference without the prefix to be left as-is, got %q", expanded[2].Action.Comment)
+	}
+	if rules[0].Action.Comment != "Accepted in ${APP_RULE_VAR_ENVIRONMENT} (${APP_RULE_VAR_REGION})" {
+		t.Errorf("expected the input rules to be unchanged, got %q", rules[0].Action.Comment)
+	}
+}
+
+// TestValidateRules_EnvRefs validates that references to variables without the
+// APP_RULE_VAR_ prefix pass validation and are reported as unexpanded, while
+// prefixed ones aren't.
+func TestValidateRules_EnvRefs(t *testing.T) {
+	rules := []AutoCloseRule{
+		{Name: "allowed", MatchAll: true, Action: RuleAction{Comment: "in ${APP_RULE_VAR_ENVIRONMENT}"}},
+		{Name: "secret", MatchAll: true, Action: RuleAction{Comment: "key ${AWS_SECRET_ACCESS_KEY}"}, Owner: "${USER}"},
+	}
+
+	if err := ValidateRules(rules); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refs := rules[0].UnexpandedEnvRefs(); len(refs) != 0 {
+		t.Errorf("expected the prefixed reference to be expanded, got %v", refs)
+	}
+	want := []string{"${AWS_SECRET_ACCESS_KEY} in comment", "${USER} in owner"}
+	if refs := rules[1].UnexpandedEnvRefs(); !slices.Equal(refs, want) {
+		t.Errorf("expected %v, got %v", want, refs)
+	}
+}
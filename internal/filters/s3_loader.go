@@ -5,24 +5,32 @@ import (
 	"encoding/json"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/cockroachdb/errors"
 )
 
+// DisabledRulesSuffix marks a rule file that is staged but not loaded.
+const DisabledRulesSuffix = ".disabled.json"
+
 type S3Client interface {
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 }
 
 type S3RulesLoader struct {
-	client S3Client
+	client  S3Client
+	retries int
+	backoff time.Duration
 }
 
 func NewS3RulesLoader(client S3Client) *S3RulesLoader {
 	return &S3RulesLoader{
-		client: client,
+		client:  client,
+		retries: 3,
+		backoff: 200 * time.Millisecond,
 	}
 }
 
@@ -38,7 +46,7 @@ func (l *S3RulesLoader) LoadRules(ctx context.Context, bucket, prefix string) ([
 
 	var allRules []AutoCloseRule
 	for _, key := range keys {
-		if !strings.HasSuffix(key, ".json") {
+		if !strings.HasSuffix(key, ".json") || strings.HasSuffix(key, DisabledRulesSuffix) {
 			continue
 		}
 
@@ -57,6 +65,35 @@ func (l *S3RulesLoader) LoadRules(ctx context.Context, bucket, prefix string) ([
 	return allRules, nil
 }
 
+// LoadRulesFromPrefixes loads and merges the rules under each prefix in
+// order. when several prefixes define a rule with the same name, the later
+// prefix wins and keeps the position of the first definition.
+func (l *S3RulesLoader) LoadRulesFromPrefixes(ctx context.Context, bucket string, prefixes []string) ([]AutoCloseRule, error) {
+	if len(prefixes) == 0 {
+		return nil, errors.New("no S3 prefixes configured")
+	}
+
+	var merged []AutoCloseRule
+	index := make(map[string]int)
+	for _, prefix := range prefixes {
+		rules, err := l.LoadRules(ctx, bucket, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			if i, ok := index[rule.Name]; ok {
+				merged[i] = rule
+				continue
+			}
+			index[rule.Name] = len(merged)
+			merged = append(merged, rule)
+		}
+	}
+
+	return merged, nil
+}
+
 func (l *S3RulesLoader) listObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
 	var keys []string
 	paginator := s3.NewListObjectsV2Paginator(l.client, &s3.ListObjectsV2Input{
@@ -65,7 +102,12 @@ func (l *S3RulesLoader) listObjects(ctx context.Context, bucket, prefix string)
 	})
 
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
+		var page *s3.ListObjectsV2Output
+		err := l.withRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx, noSDKRetries)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -81,9 +123,14 @@ func (l *S3RulesLoader) listObjects(ctx context.Context, bucket, prefix string)
 }
 
 func (l *S3RulesLoader) loadRulesFromObject(ctx context.Context, bucket, key string) ([]AutoCloseRule, error) {
-	result, err := l.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+	var result *s3.GetObjectOutput
+	err := l.withRetry(ctx, func() error {
+		var err error
+		result, err = l.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, noSDKRetries)
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -98,6 +145,51 @@ func (l *S3RulesLoader) loadRulesFromObject(ctx context.Context, bucket, key str
 	return parseRules(data)
 }
 
+// noSDKRetries turns off the SDK retryer for calls wrapped in withRetry, so
+// the attempts don't multiply.
+func noSDKRetries(o *s3.Options) {
+	o.RetryMaxAttempts = 1
+}
+
+// withRetry retries op with exponential backoff while it fails with a
+// transient error.
+func (l *S3RulesLoader) withRetry(ctx context.Context, op func() error) error {
+	backoff := l.backoff
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= l.retries || !isTransientS3Error(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientS3Error reports whether err is worth retrying: throttling or
+// 5xx. a missing object is permanent.
+func isTransientS3Error(err error) bool {
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "SlowDown", "Throttling", "ThrottlingException", "RequestTimeout", "InternalError", "ServiceUnavailable":
+			return true
+		}
+	}
+
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		code := respErr.HTTPStatusCode()
+		return code == 429 || code >= 500
+	}
+
+	return false
+}
+
 func parseRules(data []byte) ([]AutoCloseRule, error) {
 	data = []byte(strings.TrimSpace(string(data)))
 	if len(data) == 0 {
@@ -112,6 +204,26 @@ func parseRules(data []byte) ([]AutoCloseRule, error) {
 		return rules, nil
 	}
 
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to parse rule file")
+	}
+
+	// a file-level wrapper can park all of its rules with "enabled": false
+	if _, ok := fields["rules"]; ok {
+		var wrapper struct {
+			Enabled *bool           `json:"enabled"`
+			Rules   []AutoCloseRule `json:"rules"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, errors.Wrap(err, "failed to parse rules wrapper")
+		}
+		if wrapper.Enabled != nil && !*wrapper.Enabled {
+			return nil, nil
+		}
+		return wrapper.Rules, nil
+	}
+
 	var rule AutoCloseRule
 	if err := json.Unmarshal(data, &rule); err != nil {
 		return nil, errors.Wrap(err, "failed to parse single rule")
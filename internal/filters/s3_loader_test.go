@@ -7,6 +7,9 @@
 // - Non-JSON file filtering
 // - Empty and invalid JSON handling
 // - Complex rule filter parsing
+// - Retries on transient list and get errors, with the SDK retryer off
+// - Disabled rule files by suffix or file-level wrapper
+// - Merging rules across multiple prefixes with name dedupe
 //
 // Uses mock S3 client to avoid actual AWS calls.
 package filters
@@ -16,6 +19,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -545,3 +549,234 @@ func TestParseRules_WhitespaceOnly(t *testing.T) {
 		t.Errorf("expected 0 rules for whitespace-only data, got %d", len(rules))
 	}
 }
+
+type apiError struct {
+	code string
+}
+
+func (e apiError) Error() string     { return e.code }
+func (e apiError) ErrorCode() string { return e.code }
+
+// flakyS3Client fails the first calls of each operation before delegating to
+// the wrapped mock.
+type flakyS3Client struct {
+	*mockS3Client
+	listFailures int
+	getFailures  int
+	err          error
+	listCalls    int
+	getCalls     int
+	sdkAttempts  []int
+}
+
+// sdkAttempts returns the SDK retry attempts optFns leave in place.
+func sdkAttempts(optFns []func(*s3.Options)) int {
+	o := s3.Options{RetryMaxAttempts: 3}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	return o.RetryMaxAttempts
+}
+
+func (m *flakyS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.listCalls++
+	m.sdkAttempts = append(m.sdkAttempts, sdkAttempts(optFns))
+	if m.listCalls <= m.listFailures {
+		return nil, m.err
+	}
+	return m.mockS3Client.ListObjectsV2(ctx, params, optFns...)
+}
+
+func (m *flakyS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.getCalls++
+	m.sdkAttempts = append(m.sdkAttempts, sdkAttempts(optFns))
+	if m.getCalls <= m.getFailures {
+		return nil, m.err
+	}
+	return m.mockS3Client.GetObject(ctx, params, optFns...)
+}
+
+// TestS3RulesLoader_LoadRules_Retry validates that transient list and get
+// errors are retried without the SDK retryer, while permanent errors,
+// including a missing object, fail immediately.
+func TestS3RulesLoader_LoadRules_Retry(t *testing.T) {
+	objects := map[string]string{
+		"rules/rule.json": `{"name": "test-rule", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+	}
+
+	tests := []struct {
+		name         string
+		listFailures int
+		getFailures  int
+		err          error
+		wantErr      bool
+		wantList     int
+		wantGet      int
+	}{
+		{"list throttled then succeeds", 2, 0, apiError{"SlowDown"}, false, 3, 1},
+		{"get fails then succeeds", 0, 1, apiError{"InternalError"}, false, 1, 2},
+		{"missing object not retried", 0, 10, &types.NoSuchKey{}, true, 1, 1},
+		{"permanent error not retried", 0, 10, apiError{"AccessDenied"}, true, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &flakyS3Client{
+				mockS3Client: &mockS3Client{objects: objects},
+				listFailures: tt.listFailures,
+				getFailures:  tt.getFailures,
+				err:          tt.err,
+			}
+
+			loader := NewS3RulesLoader(client)
+			loader.backoff = time.Millisecond
+
+			rules, err := loader.LoadRules(context.Background(), "test-bucket", "rules/")
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if len(rules) != 1 {
+				t.Errorf("expected 1 rule, got %d", len(rules))
+			}
+
+			if client.listCalls != tt.wantList || client.getCalls != tt.wantGet {
+				t.Errorf("expected %d list and %d get calls, got %d and %d", tt.wantList, tt.wantGet, client.listCalls, client.getCalls)
+			}
+			for _, attempts := range client.sdkAttempts {
+				if attempts != 1 {
+					t.Errorf("expected the SDK retryer to be off, got %d attempts", attempts)
+				}
+			}
+		})
+	}
+}
+
+// TestS3RulesLoader_LoadRules_RetryRespectsContext validates that a
+// cancelled context stops retrying.
+func TestS3RulesLoader_LoadRules_RetryRespectsContext(t *testing.T) {
+	client := &flakyS3Client{
+		mockS3Client: &mockS3Client{},
+		listFailures: 10,
+		err:          apiError{"SlowDown"},
+	}
+
+	loader := NewS3RulesLoader(client)
+	loader.backoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := loader.LoadRules(ctx, "test-bucket", "rules/"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if client.listCalls != 1 {
+		t.Errorf("expected 1 list call, got %d", client.listCalls)
+	}
+}
+
+// TestS3RulesLoader_LoadRules_DisabledFiles validates that files parked with
+// the .disabled.json suffix or a disabled wrapper are skipped entirely.
+func TestS3RulesLoader_LoadRules_DisabledFiles(t *testing.T) {
+	client := &mockS3Client{
+		objects: map[string]string{
+			"rules/active.json":          `{"name": "active", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+			"rules/staged.disabled.json": `{"name": "staged", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+			"rules/parked.json": `{
+				"enabled": false,
+				"rules": [
+					{"name": "parked", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}
+				]
+			}`,
+			"rules/wrapped.json": `{
+				"rules": [
+					{"name": "wrapped", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}
+				]
+			}`,
+		},
+	}
+
+	rules, err := NewS3RulesLoader(client).LoadRules(context.Background(), "test-bucket", "rules/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		names[rule.Name] = true
+	}
+
+	if len(rules) != 2 || !names["active"] || !names["wrapped"] {
+		t.Errorf("expected only active and wrapped rules, got %v", names)
+	}
+}
+
+// TestS3RulesLoader_LoadRulesFromPrefixes validates that rules under every
+// prefix are merged in order and that a later prefix overrides a rule with
+// the same name.
+func TestS3RulesLoader_LoadRulesFromPrefixes(t *testing.T) {
+	client := &mockS3Client{
+		objects: map[string]string{
+			"rules/global/base.json":   `{"name": "base", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+			"rules/global/shared.json": `{"name": "shared", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+			"rules/prod/shared.json":   `{"name": "shared", "enabled": true, "filters": {"severity": ["Medium"]}, "action": {"status_id": 5}}`,
+			"rules/prod/extra.json":    `{"name": "extra", "enabled": true, "filters": {"severity": ["High"]}, "action": {"status_id": 3}}`,
+			"rules/dev/ignored.json":   `{"name": "ignored", "enabled": true, "filters": {"severity": ["Low"]}, "action": {"status_id": 3}}`,
+		},
+	}
+	loader := NewS3RulesLoader(client)
+
+	rules, err := loader.LoadRulesFromPrefixes(context.Background(), "test-bucket", []string{"rules/global/", "rules/prod/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	if len(names) != 3 || names[len(names)-1] != "extra" {
+		t.Fatalf("expected base, shared and extra with extra last, got %v", names)
+	}
+
+	for _, rule := range rules {
+		if rule.Name == "shared" && rule.Action.StatusID != 5 {
+			t.Errorf("expected rules/prod/ to override shared, got status_id %d", rule.Action.StatusID)
+		}
+	}
+
+	single, err := loader.LoadRulesFromPrefixes(context.Background(), "test-bucket", []string{"rules/dev/"})
+	if err != nil || len(single) != 1 || single[0].Name != "ignored" {
+		t.Errorf("expected single prefix to load one rule, got %v (err: %v)", single, err)
+	}
+
+	if _, err := loader.LoadRulesFromPrefixes(context.Background(), "test-bucket", []string{"rules/global/", "rules/missing/"}); err == nil {
+		t.Error("expected error for prefix without objects")
+	}
+}
+
+// TestParseRules_Wrapper validates parsing of the file-level wrapper.
+func TestParseRules_Wrapper(t *testing.T) {
+	rules, err := parseRules([]byte(`{"enabled": true, "rules": [{"name": "a", "enabled": true, "action": {"status_id": 3}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Name != "a" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	rules, err = parseRules([]byte(`{"enabled": false, "rules": [{"name": "a", "enabled": true, "action": {"status_id": 3}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 0 {
+		t.Errorf("expected disabled wrapper to yield no rules, got %d", len(rules))
+	}
+
+	if _, err := parseRules([]byte(`{"rules": [{"name": "a", "action": {"status": "bogus"}}]}`)); err == nil {
+		t.Error("expected error for invalid rule in wrapper")
+	}
+}
@@ -0,0 +1,111 @@
+// Package locale translates the phrases the bot writes into finding comments
+// and messages. rule-authored text is never translated.
+package locale
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// English is the default locale and the fallback for missing phrases.
+const English = "en"
+
+// phrase keys. formatted phrases take fmt verbs in the documented order
+const (
+	// Owner labels the rule owner in close comments
+	Owner = "owner"
+	// Ticket labels the rule ticket in close comments
+	Ticket = "ticket"
+	// StampedAt is a notify comment and the time it was stamped
+	StampedAt = "stamped_at"
+	// InformationalComment is the default close comment for informational findings
+	InformationalComment = "informational_comment"
+	// DigestTitle is the number of findings and the rule of an auto-close digest
+	DigestTitle = "digest_title"
+)
+
+var catalog = map[string]map[string]string{
+	English: {
+		Owner:                "owner",
+		Ticket:               "ticket",
+		StampedAt:            "%s at %s",
+		InformationalComment: "Auto-closed: informational finding",
+		DigestTitle:          "Auto-closed %d findings via %s",
+	},
+	"de": {
+		Owner:                "Verantwortlich",
+		Ticket:               "Ticket",
+		StampedAt:            "%s am %s",
+		InformationalComment: "Automatisch geschlossen: informativer Befund",
+		DigestTitle:          "%d Befunde automatisch geschlossen durch %s",
+	},
+	"es": {
+		Owner:                "responsable",
+		Ticket:               "ticket",
+		StampedAt:            "%s el %s",
+		InformationalComment: "Cerrado automáticamente: hallazgo informativo",
+		DigestTitle:          "%d hallazgos cerrados automáticamente por %s",
+	},
+	"fr": {
+		Owner:                "responsable",
+		Ticket:               "ticket",
+		StampedAt:            "%s le %s",
+		InformationalComment: "Fermé automatiquement : résultat informatif",
+		DigestTitle:          "%d résultats fermés automatiquement par %s",
+	},
+	"ja": {
+		Owner:                "担当",
+		Ticket:               "チケット",
+		StampedAt:            "%s（%s）",
+		InformationalComment: "自動クローズ: 情報レベルの検出結果",
+		DigestTitle:          "%[2]s により %[1]d 件の検出結果を自動クローズ",
+	},
+}
+
+// Locale selects the catalog for bot-generated phrases. the zero value is
+// English.
+type Locale struct {
+	lang string
+}
+
+// Parse returns the locale for a language tag such as "de" or "de-DE". only
+// the language is used, and an empty tag is English.
+func Parse(tag string) (Locale, error) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	if lang == "" {
+		return Locale{}, nil
+	}
+	if _, ok := catalog[lang]; !ok {
+		return Locale{}, errors.Newf("unsupported locale %q (expected one of %s)", tag, strings.Join(Supported(), ", "))
+	}
+	return Locale{lang: lang}, nil
+}
+
+// Supported returns the supported languages.
+func Supported() []string {
+	langs := make([]string, 0, len(catalog))
+	for lang := range catalog {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// Lang returns the locale's language.
+func (l Locale) Lang() string {
+	if l.lang == "" {
+		return English
+	}
+	return l.lang
+}
+
+// Text returns the phrase for key, falling back to English.
+func (l Locale) Text(key string) string {
+	if text, ok := catalog[l.Lang()][key]; ok {
+		return text
+	}
+	return catalog[English][key]
+}
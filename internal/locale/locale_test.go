@@ -0,0 +1,63 @@
+// Package locale tests the phrase catalog.
+//
+// Tests cover:
+// - Language tags parsed with or without a region, and unknown ones rejected
+// - Every locale translates every English phrase
+// - Missing phrases fall back to English
+package locale
+
+import (
+	"testing"
+)
+
+// TestParse validates that tags are matched by language and that unsupported
+// languages are rejected.
+func TestParse(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"":      English,
+		"de":    "de",
+		"de-DE": "de",
+		"FR_ca": "fr",
+		" ja ":  "ja",
+	} {
+		l, err := Parse(tag)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tag, err)
+			continue
+		}
+		if l.Lang() != expected {
+			t.Errorf("%q: expected %s, got %s", tag, expected, l.Lang())
+		}
+	}
+
+	if _, err := Parse("xx-YY"); err == nil {
+		t.Error("expected an unsupported locale to fail")
+	}
+}
+
+// TestCatalog validates that every locale has every English phrase, so a
+// missing translation is caught here rather than by the English fallback.
+func TestCatalog(t *testing.T) {
+	for lang, phrases := range catalog {
+		for key := range catalog[English] {
+			if phrases[key] == "" {
+				t.Errorf("%s: missing %s", lang, key)
+			}
+		}
+	}
+}
+
+// TestText_Fallback validates that the zero locale is English and that a
+// phrase missing from a locale falls back to English.
+func TestText_Fallback(t *testing.T) {
+	if got := (Locale{}).Text(Owner); got != "owner" {
+		t.Errorf("expected the zero locale to be English, got %q", got)
+	}
+
+	catalog[English]["test_only"] = "fallback"
+	defer delete(catalog[English], "test_only")
+
+	if got := (Locale{lang: "de"}).Text("test_only"); got != "fallback" {
+		t.Errorf("expected the English fallback, got %q", got)
+	}
+}
@@ -0,0 +1,277 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	Namespace      = "SecurityHubV2Bot"
+	RuleHitsMetric = "RuleHits"
+
+	UnknownSeverityMetric = "UnknownSeverity"
+
+	// FindingsOverflowMetric counts findings past the per-event cap
+	FindingsOverflowMetric = "FindingsOverflow"
+
+	RulesReloadsMetric        = "RulesReloads"
+	RulesReloadErrorsMetric   = "RulesReloadErrors"
+	RulesReloadDurationMetric = "RulesReloadDuration"
+	RulesAddedMetric          = "RulesAdded"
+	RulesRemovedMetric        = "RulesRemoved"
+	RulesChangedMetric        = "RulesChanged"
+
+	ActionClosed   = "closed"
+	ActionSkipped  = "skipped"
+	ActionBlocked  = "blocked"
+	ActionCapped   = "capped"
+	ActionObserved = "observed"
+	ActionDelayed  = "delayed"
+)
+
+// RuleHit identifies a rule hit counter by its dimensions.
+type RuleHit struct {
+	RuleName string
+	Action   string
+	Severity string
+}
+
+// RulesReload describes one rules reload. the deltas compare rules by name
+// with the rules loaded before.
+type RulesReload struct {
+	Added    int
+	Removed  int
+	Changed  int
+	Duration time.Duration
+	Failed   bool
+}
+
+// Recorder counts rule hits and unknown severities and writes them as
+// CloudWatch embedded metric format (EMF) lines.
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	hits    map[RuleHit]int
+	unknown map[string]int
+	reloads []RulesReload
+	// overflow is the findings dropped or rejected by the per-event cap
+	overflow int
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, hits: make(map[RuleHit]int), unknown: make(map[string]int)}
+}
+
+func (r *Recorder) RecordRuleHit(ruleName, action, severity string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hits[RuleHit{RuleName: ruleName, Action: action, Severity: severity}]++
+}
+
+// RecordUnknownSeverity counts a finding whose severity is outside the known
+// set.
+func (r *Recorder) RecordUnknownSeverity(severity string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unknown[severity]++
+}
+
+// RecordRulesReload records a rules reload, written as its own EMF line.
+func (r *Recorder) RecordRulesReload(reload RulesReload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloads = append(r.reloads, reload)
+}
+
+// RecordFindingsOverflow counts findings in an event beyond the per-event
+// cap.
+func (r *Recorder) RecordFindingsOverflow(count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overflow += count
+}
+
+// UnknownSeverities returns a copy of the unknown severity counters.
+func (r *Recorder) UnknownSeverities() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int, len(r.unknown))
+	for k, v := range r.unknown {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Counts returns a copy of the current counters.
+func (r *Recorder) Counts() map[RuleHit]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[RuleHit]int, len(r.hits))
+	for k, v := range r.hits {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Flush writes one EMF line per counter and resets the counters.
+func (r *Recorder) Flush(now time.Time) error {
+	r.mu.Lock()
+	hits := r.hits
+	unknown := r.unknown
+	reloads := r.reloads
+	overflow := r.overflow
+	r.hits = make(map[RuleHit]int)
+	r.unknown = make(map[string]int)
+	r.reloads = nil
+	r.overflow = 0
+	r.mu.Unlock()
+
+	keys := make([]RuleHit, 0, len(hits))
+	for k := range hits {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RuleName != keys[j].RuleName {
+			return keys[i].RuleName < keys[j].RuleName
+		}
+		if keys[i].Action != keys[j].Action {
+			return keys[i].Action < keys[j].Action
+		}
+		return keys[i].Severity < keys[j].Severity
+	})
+
+	for _, k := range keys {
+		if err := r.write(newEMFRecord(k, hits[k], now)); err != nil {
+			return err
+		}
+	}
+
+	severities := make([]string, 0, len(unknown))
+	for k := range unknown {
+		severities = append(severities, k)
+	}
+	sort.Strings(severities)
+
+	for _, severity := range severities {
+		if err := r.write(newUnknownSeverityRecord(severity, unknown[severity], now)); err != nil {
+			return err
+		}
+	}
+
+	for _, reload := range reloads {
+		if err := r.write(newRulesReloadRecord(reload, now)); err != nil {
+			return err
+		}
+	}
+
+	if overflow > 0 {
+		if err := r.write(newFindingsOverflowRecord(overflow, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) write(record map[string]any) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal metric")
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write metric")
+	}
+	return nil
+}
+
+func newEMFRecord(hit RuleHit, count int, now time.Time) map[string]any {
+	return map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": now.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{
+				{
+					"Namespace":  Namespace,
+					"Dimensions": [][]string{{"RuleName", "Action", "Severity"}},
+					"Metrics":    []map[string]string{{"Name": RuleHitsMetric, "Unit": "Count"}},
+				},
+			},
+		},
+		"RuleName":     hit.RuleName,
+		"Action":       hit.Action,
+		"Severity":     hit.Severity,
+		RuleHitsMetric: count,
+	}
+}
+
+func newUnknownSeverityRecord(severity string, count int, now time.Time) map[string]any {
+	return map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": now.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{
+				{
+					"Namespace":  Namespace,
+					"Dimensions": [][]string{{"Severity"}},
+					"Metrics":    []map[string]string{{"Name": UnknownSeverityMetric, "Unit": "Count"}},
+				},
+			},
+		},
+		"Severity":            severity,
+		UnknownSeverityMetric: count,
+	}
+}
+
+// newRulesReloadRecord has no dimensions, so the metrics aggregate across
+// every reload.
+func newRulesReloadRecord(reload RulesReload, now time.Time) map[string]any {
+	failed := 0
+	if reload.Failed {
+		failed = 1
+	}
+	return map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": now.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{
+				{
+					"Namespace":  Namespace,
+					"Dimensions": [][]string{{}},
+					"Metrics": []map[string]string{
+						{"Name": RulesReloadsMetric, "Unit": "Count"},
+						{"Name": RulesReloadErrorsMetric, "Unit": "Count"},
+						{"Name": RulesReloadDurationMetric, "Unit": "Milliseconds"},
+						{"Name": RulesAddedMetric, "Unit": "Count"},
+						{"Name": RulesRemovedMetric, "Unit": "Count"},
+						{"Name": RulesChangedMetric, "Unit": "Count"},
+					},
+				},
+			},
+		},
+		RulesReloadsMetric:        1,
+		RulesReloadErrorsMetric:   failed,
+		RulesReloadDurationMetric: reload.Duration.Milliseconds(),
+		RulesAddedMetric:          reload.Added,
+		RulesRemovedMetric:        reload.Removed,
+		RulesChangedMetric:        reload.Changed,
+	}
+}
+
+func newFindingsOverflowRecord(count int, now time.Time) map[string]any {
+	return map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": now.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{
+				{
+					"Namespace":  Namespace,
+					"Dimensions": [][]string{{}},
+					"Metrics":    []map[string]string{{"Name": FindingsOverflowMetric, "Unit": "Count"}},
+				},
+			},
+		},
+		FindingsOverflowMetric: count,
+	}
+}
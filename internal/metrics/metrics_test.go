@@ -0,0 +1,249 @@
+// Package metrics tests rule hit counters and their EMF output.
+//
+// Tests cover:
+// - Counting hits per rule, action and severity
+// - EMF lines are valid JSON with the expected metric and dimensions
+// - Flush resets counters and writes nothing when empty
+// - Unknown severity counts written with a Severity dimension
+// - Rules reloads written as one undimensioned line each
+// - Findings overflow summed into one undimensioned line per flush
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecorder_Flush validates that each counter is written as a valid EMF
+// line.
+func TestRecorder_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+
+	r.RecordRuleHit("close-runs-on", ActionClosed, "Medium")
+	r.RecordRuleHit("close-runs-on", ActionClosed, "Medium")
+	r.RecordRuleHit("close-runs-on", ActionSkipped, "Medium")
+
+	if got := r.Counts()[RuleHit{RuleName: "close-runs-on", Action: ActionClosed, Severity: "Medium"}]; got != 2 {
+		t.Errorf("expected 2 closed hits, got %d", got)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := r.Flush(now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 EMF lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var record struct {
+		AWS struct {
+			Timestamp         int64 `json:"Timestamp"`
+			CloudWatchMetrics []struct {
+				Namespace  string     `json:"Namespace"`
+				Dimensions [][]string `json:"Dimensions"`
+				Metrics    []struct {
+					Name string `json:"Name"`
+					Unit string `json:"Unit"`
+				} `json:"Metrics"`
+			} `json:"CloudWatchMetrics"`
+		} `json:"_aws"`
+		RuleName string `json:"RuleName"`
+		Action   string `json:"Action"`
+		Severity string `json:"Severity"`
+		RuleHits int    `json:"RuleHits"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid EMF JSON: %v", err)
+	}
+
+	if record.AWS.Timestamp != now.UnixMilli() {
+		t.Errorf("unexpected timestamp: %d", record.AWS.Timestamp)
+	}
+
+	if len(record.AWS.CloudWatchMetrics) != 1 {
+		t.Fatalf("expected 1 metric directive, got %d", len(record.AWS.CloudWatchMetrics))
+	}
+
+	directive := record.AWS.CloudWatchMetrics[0]
+	if directive.Namespace != Namespace {
+		t.Errorf("unexpected namespace: %s", directive.Namespace)
+	}
+
+	if len(directive.Metrics) != 1 || directive.Metrics[0].Name != RuleHitsMetric || directive.Metrics[0].Unit != "Count" {
+		t.Errorf("unexpected metrics: %+v", directive.Metrics)
+	}
+
+	if len(directive.Dimensions) != 1 || strings.Join(directive.Dimensions[0], ",") != "RuleName,Action,Severity" {
+		t.Errorf("unexpected dimensions: %v", directive.Dimensions)
+	}
+
+	if record.RuleName != "close-runs-on" || record.Action != ActionClosed || record.Severity != "Medium" || record.RuleHits != 2 {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	buf.Reset()
+	if err := r.Flush(now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output after reset, got %s", buf.String())
+	}
+}
+
+// TestRecorder_UnknownSeverity validates that unknown severities are written
+// as their own EMF lines after rule hits.
+func TestRecorder_UnknownSeverity(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+
+	r.RecordRuleHit("close-runs-on", ActionClosed, "Medium")
+	r.RecordUnknownSeverity("Severe")
+	r.RecordUnknownSeverity("Severe")
+	r.RecordUnknownSeverity("")
+
+	if got := r.UnknownSeverities()["Severe"]; got != 2 {
+		t.Errorf("expected 2 unknown severity counts, got %d", got)
+	}
+
+	if err := r.Flush(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 EMF lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var record struct {
+		AWS struct {
+			CloudWatchMetrics []struct {
+				Dimensions [][]string `json:"Dimensions"`
+				Metrics    []struct {
+					Name string `json:"Name"`
+				} `json:"Metrics"`
+			} `json:"CloudWatchMetrics"`
+		} `json:"_aws"`
+		Severity        string `json:"Severity"`
+		UnknownSeverity int    `json:"UnknownSeverity"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &record); err != nil {
+		t.Fatalf("invalid EMF JSON: %v", err)
+	}
+
+	if record.Severity != "Severe" || record.UnknownSeverity != 2 {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	directive := record.AWS.CloudWatchMetrics[0]
+	if directive.Metrics[0].Name != UnknownSeverityMetric || strings.Join(directive.Dimensions[0], ",") != "Severity" {
+		t.Errorf("unexpected directive: %+v", directive)
+	}
+
+	if len(r.UnknownSeverities()) != 0 {
+		t.Error("expected counters to be reset after flush")
+	}
+}
+
+// TestRecorder_RulesReload validates that each reload is written as its own
+// EMF line with the delta, duration and error count.
+func TestRecorder_RulesReload(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+
+	r.RecordRulesReload(RulesReload{Added: 2, Removed: 1, Changed: 3, Duration: 1500 * time.Millisecond})
+	r.RecordRulesReload(RulesReload{Duration: 20 * time.Millisecond, Failed: true})
+
+	if err := r.Flush(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 EMF lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var record struct {
+		AWS struct {
+			CloudWatchMetrics []struct {
+				Dimensions [][]string `json:"Dimensions"`
+				Metrics    []struct {
+					Name string `json:"Name"`
+					Unit string `json:"Unit"`
+				} `json:"Metrics"`
+			} `json:"CloudWatchMetrics"`
+		} `json:"_aws"`
+		RulesReloads        int `json:"RulesReloads"`
+		RulesReloadErrors   int `json:"RulesReloadErrors"`
+		RulesReloadDuration int `json:"RulesReloadDuration"`
+		RulesAdded          int `json:"RulesAdded"`
+		RulesRemoved        int `json:"RulesRemoved"`
+		RulesChanged        int `json:"RulesChanged"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid EMF JSON: %v", err)
+	}
+
+	if record.RulesReloads != 1 || record.RulesReloadErrors != 0 || record.RulesReloadDuration != 1500 ||
+		record.RulesAdded != 2 || record.RulesRemoved != 1 || record.RulesChanged != 3 {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	directive := record.AWS.CloudWatchMetrics[0]
+	if len(directive.Dimensions) != 1 || len(directive.Dimensions[0]) != 0 {
+		t.Errorf("expected a single empty dimension set, got %v", directive.Dimensions)
+	}
+	if len(directive.Metrics) != 6 || directive.Metrics[2].Name != RulesReloadDurationMetric || directive.Metrics[2].Unit != "Milliseconds" {
+		t.Errorf("unexpected metrics: %+v", directive.Metrics)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("invalid EMF JSON: %v", err)
+	}
+	if record.RulesReloadErrors != 1 {
+		t.Errorf("expected the failed reload to count an error, got %+v", record)
+	}
+
+	buf.Reset()
+	if err := r.Flush(time.Now()); err != nil || buf.Len() != 0 {
+		t.Errorf("expected reloads to be reset after flush, got %q, %v", buf.String(), err)
+	}
+}
+
+// TestRecorder_FindingsOverflow validates that overflow from several events
+// is summed into a single line and reset by the flush.
+func TestRecorder_FindingsOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+
+	r.RecordFindingsOverflow(10)
+	r.RecordFindingsOverflow(5)
+
+	if err := r.Flush(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var record struct {
+		FindingsOverflow int `json:"FindingsOverflow"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &record); err != nil {
+		t.Fatalf("expected a single EMF line: %v", err)
+	}
+	if record.FindingsOverflow != 15 {
+		t.Errorf("expected an overflow of 15, got %d", record.FindingsOverflow)
+	}
+
+	buf.Reset()
+	if err := r.Flush(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing after the reset, got %s", buf.String())
+	}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/decisionlog"
+)
+
+// prometheus metric names
+const (
+	FindingsProcessedMetric = "securityhubv2bot_findings_processed_total"
+	FindingsClosedMetric    = "securityhubv2bot_findings_closed_total"
+	NotificationsMetric     = "securityhubv2bot_notifications_sent_total"
+	ErrorsMetric            = "securityhubv2bot_errors_total"
+	RuleHitsTotalMetric     = "securityhubv2bot_rule_hits_total"
+)
+
+// Counters keeps cumulative processing counts for Prometheus scraping.
+// unlike Recorder they are never reset. it counts decision log entries, so it
+// is wired in as a decision log sink.
+type Counters struct {
+	mu        sync.Mutex
+	processed int
+	closed    int
+	notified  int
+	errors    int
+	hits      map[RuleHit]int
+}
+
+func NewCounters() *Counters {
+	return &Counters{hits: make(map[RuleHit]int)}
+}
+
+// Write counts the decision for one finding. duplicates within an event are
+// not counted as processed.
+func (c *Counters) Write(ctx context.Context, entry decisionlog.Entry) error {
+	if entry.Action == decisionlog.ActionDuplicate {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.processed++
+	if entry.Action == ActionClosed {
+		c.closed++
+	}
+	if entry.Notification == decisionlog.NotificationSent {
+		c.notified++
+	}
+	if entry.Error != "" {
+		c.errors++
+	}
+	if entry.Rule != "" && entry.Action != decisionlog.ActionError {
+		c.hits[RuleHit{RuleName: entry.Rule, Action: entry.Action, Severity: entry.Severity}]++
+	}
+	return nil
+}
+
+// WritePrometheus writes the counters in the Prometheus text format.
+func (c *Counters) WritePrometheus(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, FindingsProcessedMetric, "Findings processed.", c.processed)
+	writeCounter(&b, FindingsClosedMetric, "Findings auto-closed.", c.closed)
+	writeCounter(&b, NotificationsMetric, "Notifications sent.", c.notified)
+	writeCounter(&b, ErrorsMetric, "Findings that failed to process.", c.errors)
+
+	keys := make([]RuleHit, 0, len(c.hits))
+	for k := range c.hits {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RuleName != keys[j].RuleName {
+			return keys[i].RuleName < keys[j].RuleName
+		}
+		if keys[i].Action != keys[j].Action {
+			return keys[i].Action < keys[j].Action
+		}
+		return keys[i].Severity < keys[j].Severity
+	})
+
+	fmt.Fprintf(&b, "# HELP %s Rule matches by rule, action and severity.\n# TYPE %s counter\n", RuleHitsTotalMetric, RuleHitsTotalMetric)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s{rule=\"%s\",action=\"%s\",severity=\"%s\"} %d\n",
+			RuleHitsTotalMetric, labelEscaper.Replace(k.RuleName), labelEscaper.Replace(k.Action), labelEscaper.Replace(k.Severity), c.hits[k])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (c *Counters) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WritePrometheus(w)
+}
+
+func writeCounter(b *strings.Builder, name, help string, value int) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+// labelEscaper escapes label values for the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
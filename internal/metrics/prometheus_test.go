@@ -0,0 +1,55 @@
+// Package metrics tests cumulative counters and their Prometheus output.
+//
+// Tests cover:
+// - Decision log entries counted as processed, closed, notified and errors
+// - Duplicates and failed closes excluded from the matching counters
+// - Label values escaped in the text format
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/decisionlog"
+)
+
+// TestCounters_WritePrometheus validates counts derived from decision log
+// entries and the rendered text format.
+func TestCounters_WritePrometheus(t *testing.T) {
+	c := NewCounters()
+	for _, entry := range []decisionlog.Entry{
+		{Rule: "close-low", Action: ActionClosed, Severity: "Low", Notification: decisionlog.NotificationSent},
+		{Rule: "close-low", Action: ActionClosed, Severity: "Low", Notification: decisionlog.NotificationNone},
+		{Rule: "close-low", Action: decisionlog.ActionError, Severity: "Low", Error: "boom"},
+		{Action: decisionlog.ActionNone, Severity: "High", Notification: decisionlog.NotificationFailed},
+		{Action: decisionlog.ActionDuplicate},
+		{Rule: `odd "rule"\`, Action: ActionObserved, Severity: "Medium"},
+	} {
+		c.Write(context.Background(), entry)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePrometheus(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		FindingsProcessedMetric + " 5\n",
+		FindingsClosedMetric + " 2\n",
+		NotificationsMetric + " 1\n",
+		ErrorsMetric + " 1\n",
+		RuleHitsTotalMetric + `{rule="close-low",action="closed",severity="Low"} 2` + "\n",
+		RuleHitsTotalMetric + `{rule="odd \"rule\"\\",action="observed",severity="Medium"} 1` + "\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, `action="error"`) {
+		t.Errorf("expected failed closes not to count as rule hits:\n%s", out)
+	}
+}
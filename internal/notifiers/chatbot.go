@@ -0,0 +1,135 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// SNSClient is the subset of the SNS API used to publish notifications.
+type SNSClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// chatbot limits for custom notification content
+const (
+	chatbotMaxTitle       = 250
+	chatbotMaxDescription = 8000
+)
+
+// ChatbotMessage is an AWS Chatbot custom notification, which Chatbot renders
+// in the Slack channels or Teams chats subscribed to the topic.
+type ChatbotMessage struct {
+	Version  string          `json:"version"`
+	Source   string          `json:"source"`
+	ID       string          `json:"id,omitempty"`
+	Content  ChatbotContent  `json:"content"`
+	Metadata ChatbotMetadata `json:"metadata"`
+}
+
+type ChatbotContent struct {
+	TextType    string   `json:"textType"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	NextSteps   []string `json:"nextSteps,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
+
+type ChatbotMetadata struct {
+	ThreadID          string            `json:"threadId,omitempty"`
+	Summary           string            `json:"summary,omitempty"`
+	EventType         string            `json:"eventType,omitempty"`
+	RelatedResources  []string          `json:"relatedResources,omitempty"`
+	AdditionalContext map[string]string `json:"additionalContext,omitempty"`
+}
+
+type ChatbotNotifier struct {
+	client   SNSClient
+	topicARN string
+	links    events.ConsoleLinkOptions
+}
+
+// NewChatbotNotifier creates a notifier that publishes AWS Chatbot custom
+// notifications to the SNS topic topicARN, with console links built from
+// links.
+func NewChatbotNotifier(client SNSClient, topicARN string, links events.ConsoleLinkOptions) *ChatbotNotifier {
+	return &ChatbotNotifier{
+		client:   client,
+		topicARN: topicARN,
+		links:    links,
+	}
+}
+
+// Message builds the Chatbot custom notification for a finding. the thread id
+// is the finding uid so updates to a finding thread together.
+func (c *ChatbotNotifier) Message(ctx context.Context, finding *events.SecurityHubV2Finding) ChatbotMessage {
+	consoleURL := finding.BuildConsoleUrl(consoleLinks(ctx, c.links))
+
+	nextSteps := []string{fmt.Sprintf("<%s|View finding in Security Hub>", consoleURL)}
+	if id, url := Ticket(ctx); id != "" {
+		if url != "" {
+			nextSteps = append(nextSteps, fmt.Sprintf("Ticket: <%s|%s>", url, id))
+		} else {
+			nextSteps = append(nextSteps, "Ticket: "+id)
+		}
+	}
+
+	additional := map[string]string{
+		"account":  finding.Cloud.Account.UID,
+		"region":   finding.Cloud.Region,
+		"severity": finding.Severity,
+		"category": finding.FindingCategory(c.links.CategoryMappings),
+	}
+	if rule := MatchedRule(ctx); rule != "" {
+		additional["matched_rule"] = rule
+	}
+
+	return ChatbotMessage{
+		Version: "1.0",
+		Source:  "custom",
+		ID:      finding.Fingerprint(),
+		Content: ChatbotContent{
+			TextType:    "client-markdown",
+			Title:       truncate(fmt.Sprintf("%s %s", finding.GetSeverityEmoji(), finding.FindingInfo.Title), chatbotMaxTitle),
+			Description: truncate(finding.FindingInfo.Desc, chatbotMaxDescription),
+			NextSteps:   nextSteps,
+			Keywords:    []string{finding.Severity, finding.Metadata.Product.Name},
+		},
+		Metadata: ChatbotMetadata{
+			ThreadID:          finding.Metadata.UID,
+			Summary:           fmt.Sprintf("%s finding in %s: %s", finding.Severity, finding.Cloud.Account.UID, finding.FindingInfo.Title),
+			EventType:         "SecurityHubFinding",
+			RelatedResources:  finding.ResourceUIDs(),
+			AdditionalContext: additional,
+		},
+	}
+}
+
+func (c *ChatbotNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	body, err := json.Marshal(c.Message(ctx, finding))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal chatbot message")
+	}
+
+	if _, err := c.client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(c.topicARN),
+		Message:  aws.String(string(body)),
+	}); err != nil {
+		return errors.Wrap(err, "failed to publish chatbot message")
+	}
+	return nil
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
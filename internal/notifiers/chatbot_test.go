@@ -0,0 +1,148 @@
+// Package notifiers tests the AWS Chatbot notifier and its SNS messages.
+//
+// Tests cover:
+// - Published message follows the Chatbot custom notification schema
+// - Ticket and matched rule are carried in next steps and context
+// - Long titles are truncated to the Chatbot limit
+// - Publish failures are errors
+// - Uses fixtures/samples.json for realistic OCSF findings
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+type fakeSNSClient struct {
+	inputs []*sns.PublishInput
+	err    error
+}
+
+func (f *fakeSNSClient) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.inputs = append(f.inputs, params)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sns.PublishOutput{MessageId: aws.String("msg-1")}, nil
+}
+
+// TestChatbotNotifier_Notify validates that the message published to the topic
+// has the fields Chatbot requires for a custom notification.
+func TestChatbotNotifier_Notify(t *testing.T) {
+	client := &fakeSNSClient{}
+	notifier := NewChatbotNotifier(client, "arn:aws:sns:us-east-1:123456789012:chatbot", events.ConsoleLinkOptions{
+		ConsoleURL:        "https://console.aws.amazon.com",
+		SecurityHubRegion: "us-east-1",
+	})
+	finding := loadSampleFinding(t, 0)
+
+	ctx := WithTicket(WithMatchedRule(context.Background(), "close-dev"), "SEC-1", "https://tickets.example.com/SEC-1")
+	if err := notifier.Notify(ctx, finding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.inputs) != 1 {
+		t.Fatalf("expected 1 publish, got %d", len(client.inputs))
+	}
+	if arn := aws.ToString(client.inputs[0].TopicArn); arn != "arn:aws:sns:us-east-1:123456789012:chatbot" {
+		t.Errorf("unexpected topic: %s", arn)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal([]byte(aws.ToString(client.inputs[0].Message)), &msg); err != nil {
+		t.Fatalf("invalid message JSON: %v", err)
+	}
+
+	if msg["version"] != "1.0" || msg["source"] != "custom" {
+		t.Errorf("unexpected version or source: %v, %v", msg["version"], msg["source"])
+	}
+	if msg["id"] != finding.Fingerprint() {
+		t.Errorf("expected id %s, got %v", finding.Fingerprint(), msg["id"])
+	}
+
+	content, ok := msg["content"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected content object, got %v", msg["content"])
+	}
+	if content["textType"] != "client-markdown" {
+		t.Errorf("unexpected textType: %v", content["textType"])
+	}
+	if title, _ := content["title"].(string); !strings.Contains(title, finding.FindingInfo.Title) {
+		t.Errorf("expected title to contain %q, got %q", finding.FindingInfo.Title, title)
+	}
+	if content["description"] != finding.FindingInfo.Desc {
+		t.Errorf("unexpected description: %v", content["description"])
+	}
+
+	steps, _ := content["nextSteps"].([]any)
+	if len(steps) != 2 {
+		t.Fatalf("expected console and ticket next steps, got %v", content["nextSteps"])
+	}
+	if step, _ := steps[0].(string); !strings.Contains(step, "findingDetailId="+finding.Metadata.UID) {
+		t.Errorf("expected console link, got %q", step)
+	}
+	if steps[1] != "Ticket: <https://tickets.example.com/SEC-1|SEC-1>" {
+		t.Errorf("unexpected ticket step: %v", steps[1])
+	}
+
+	metadata, ok := msg["metadata"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected metadata object, got %v", msg["metadata"])
+	}
+	if metadata["threadId"] != finding.Metadata.UID {
+		t.Errorf("expected threadId %s, got %v", finding.Metadata.UID, metadata["threadId"])
+	}
+	if metadata["eventType"] != "SecurityHubFinding" {
+		t.Errorf("unexpected eventType: %v", metadata["eventType"])
+	}
+	if summary, _ := metadata["summary"].(string); summary == "" {
+		t.Error("expected a summary")
+	}
+
+	additional, _ := metadata["additionalContext"].(map[string]any)
+	if additional["account"] != finding.Cloud.Account.UID || additional["severity"] != finding.Severity || additional["matched_rule"] != "close-dev" {
+		t.Errorf("unexpected additionalContext: %v", additional)
+	}
+}
+
+// TestChatbotNotifier_Message_Truncates validates that titles are cut to the
+// Chatbot limit and that optional fields are omitted.
+func TestChatbotNotifier_Message_Truncates(t *testing.T) {
+	notifier := NewChatbotNotifier(&fakeSNSClient{}, "arn", events.ConsoleLinkOptions{ConsoleURL: "https://console.aws.amazon.com"})
+
+	finding := &events.SecurityHubV2Finding{Severity: "High"}
+	finding.FindingInfo.Title = strings.Repeat("é", 300)
+
+	msg := notifier.Message(context.Background(), finding)
+	if n := utf8.RuneCountInString(msg.Content.Title); n != chatbotMaxTitle {
+		t.Errorf("expected title of %d runes, got %d", chatbotMaxTitle, n)
+	}
+	if !strings.HasSuffix(msg.Content.Title, "…") {
+		t.Errorf("expected truncated title to end with an ellipsis, got %q", msg.Content.Title)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(body), "matched_rule") || strings.Contains(string(body), "relatedResources") {
+		t.Errorf("expected optional fields to be omitted, got %s", body)
+	}
+}
+
+// TestChatbotNotifier_PublishError validates that SNS failures fail the
+// notification.
+func TestChatbotNotifier_PublishError(t *testing.T) {
+	notifier := NewChatbotNotifier(&fakeSNSClient{err: errors.New("access denied")}, "arn", events.ConsoleLinkOptions{})
+	if err := notifier.Notify(context.Background(), &events.SecurityHubV2Finding{Severity: "High"}); err == nil {
+		t.Error("expected error for publish failure")
+	}
+}
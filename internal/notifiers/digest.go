@@ -0,0 +1,38 @@
+package notifiers
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// Digest summarizes the findings one rule auto-closed in a single event.
+type Digest struct {
+	Rule     string
+	Findings []*events.SecurityHubV2Finding
+	// Status is the name of the status the findings were closed as
+	Status string
+}
+
+// DigestNotifier is implemented by notifiers that send a Digest as a single
+// message.
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, digest Digest) error
+}
+
+// NotifyDigest sends the digest as one message when the notifier supports it,
+// and notifies each finding otherwise.
+func NotifyDigest(ctx context.Context, notifier Notifier, digest Digest) error {
+	if d, ok := notifier.(DigestNotifier); ok {
+		return d.NotifyDigest(ctx, digest)
+	}
+
+	var errs []error
+	for _, finding := range digest.Findings {
+		if err := notifier.Notify(ctx, finding); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to notify %s", finding.Metadata.UID))
+		}
+	}
+	return errors.Join(errs...)
+}
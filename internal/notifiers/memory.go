@@ -0,0 +1,52 @@
+package notifiers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// MemoryNotifier records findings instead of sending them, for tests and
+// local runs.
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	findings []*events.SecurityHubV2Finding
+	digests  []Digest
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (m *MemoryNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findings = append(m.findings, finding)
+	return nil
+}
+
+// Findings returns a copy of all recorded findings in notification order.
+func (m *MemoryNotifier) Findings() []*events.SecurityHubV2Finding {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	findings := make([]*events.SecurityHubV2Finding, len(m.findings))
+	copy(findings, m.findings)
+	return findings
+}
+
+func (m *MemoryNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.digests = append(m.digests, digest)
+	return nil
+}
+
+// Digests returns a copy of all recorded digests in notification order.
+func (m *MemoryNotifier) Digests() []Digest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	digests := make([]Digest, len(m.digests))
+	copy(digests, m.digests)
+	return digests
+}
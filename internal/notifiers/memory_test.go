@@ -0,0 +1,42 @@
+// Package notifiers tests the in-memory notifier.
+//
+// Tests cover:
+// - Recording findings in notification order
+// - Accessor returns a copy safe from later mutation
+package notifiers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// TestMemoryNotifier_RecordsFindings validates that notified findings are
+// recorded in order and exposed via the accessor.
+func TestMemoryNotifier_RecordsFindings(t *testing.T) {
+	notifier := NewMemoryNotifier()
+
+	f1 := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	f2 := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-2"}}
+
+	for _, f := range []*events.SecurityHubV2Finding{f1, f2} {
+		if err := notifier.Notify(context.Background(), f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	findings := notifier.Findings()
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+
+	if findings[0].Metadata.UID != "finding-1" || findings[1].Metadata.UID != "finding-2" {
+		t.Errorf("unexpected finding order: %s, %s", findings[0].Metadata.UID, findings[1].Metadata.UID)
+	}
+
+	findings[0] = nil
+	if notifier.Findings()[0] == nil {
+		t.Error("expected accessor to return a copy")
+	}
+}
@@ -0,0 +1,92 @@
+package notifiers
+
+import (
+	"context"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// MultiNotifier sends each notification to several named backends, in the
+// order they were added. a rule can narrow its notifications to some of them
+// with WithSelectedNotifiers.
+type MultiNotifier struct {
+	names     []string
+	notifiers map[string]Notifier
+}
+
+func NewMultiNotifier() *MultiNotifier {
+	return &MultiNotifier{notifiers: make(map[string]Notifier)}
+}
+
+// Add registers a backend under name, replacing any backend with that name.
+func (m *MultiNotifier) Add(name string, notifier Notifier) {
+	if _, ok := m.notifiers[name]; !ok {
+		m.names = append(m.names, name)
+	}
+	m.notifiers[name] = notifier
+}
+
+type deliveriesKey struct{}
+
+// WithDeliveries returns a context recording which backends a multi-notifier
+// has delivered to, so a retry with it only resends to the backends that
+// failed.
+func WithDeliveries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deliveriesKey{}, make(map[string]bool))
+}
+
+// Notify sends to the backends selected in the context, or to all of them.
+// every selected backend is tried and the failures are returned together.
+func (m *MultiNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	return m.send(ctx, func(n Notifier) error {
+		return n.Notify(ctx, finding)
+	})
+}
+
+// NotifyDigest sends the digest to the selected backends like Notify. backends
+// without digest support are sent each finding instead.
+func (m *MultiNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	return m.send(ctx, func(n Notifier) error {
+		return NotifyDigest(ctx, n, digest)
+	})
+}
+
+// send calls send for each selected backend that hasn't been delivered to
+// with ctx yet.
+func (m *MultiNotifier) send(ctx context.Context, send func(Notifier) error) error {
+	selected := SelectedNotifiers(ctx)
+	delivered, _ := ctx.Value(deliveriesKey{}).(map[string]bool)
+
+	var errs []error
+	for _, name := range m.names {
+		if len(selected) > 0 && !slices.Contains(selected, name) {
+			continue
+		}
+		if delivered[name] {
+			continue
+		}
+		if err := send(m.notifiers[name]); err != nil {
+			errs = append(errs, errors.Wrapf(err, "%s notifier failed", name))
+			continue
+		}
+		if delivered != nil {
+			delivered[name] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flushes the backends that buffer notifications.
+func (m *MultiNotifier) Flush(ctx context.Context) error {
+	var errs []error
+	for _, name := range m.names {
+		if f, ok := m.notifiers[name].(interface{ Flush(context.Context) error }); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to flush %s notifier", name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
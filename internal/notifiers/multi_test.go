@@ -0,0 +1,169 @@
+// Package notifiers tests fanning notifications out to several backends.
+//
+// Tests cover:
+// - Every backend is notified when no backends are selected
+// - Only the selected backends are notified
+// - A failing backend doesn't stop the others and its error is returned
+// - Digests sent whole to digest backends and per finding to the others
+// - A retry with the same deliveries only resends to the failed backends
+package notifiers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	return errors.New("pagerduty unavailable")
+}
+
+// flakyNotifier fails its first failures calls.
+type flakyNotifier struct {
+	failures int
+	calls    int
+}
+
+func (n *flakyNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	n.calls++
+	if n.calls <= n.failures {
+		return errors.New("pagerduty unavailable")
+	}
+	return nil
+}
+
+// notifyOnly hides a memory notifier's digest support.
+type notifyOnly struct {
+	memory *MemoryNotifier
+}
+
+func (n notifyOnly) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	return n.memory.Notify(ctx, finding)
+}
+
+// TestMultiNotifier_Selection validates that notifications go to every
+// backend by default and only to the selected ones when the context names
+// them.
+func TestMultiNotifier_Selection(t *testing.T) {
+	slack, webhook := NewMemoryNotifier(), NewMemoryNotifier()
+	multi := NewMultiNotifier()
+	multi.Add("slack", slack)
+	multi.Add("webhook", webhook)
+
+	finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	if err := multi.Notify(context.Background(), finding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slack.Findings()) != 1 || len(webhook.Findings()) != 1 {
+		t.Errorf("expected both backends to be notified, got slack=%d webhook=%d", len(slack.Findings()), len(webhook.Findings()))
+	}
+
+	ctx := WithSelectedNotifiers(context.Background(), []string{"webhook"})
+	if err := multi.Notify(ctx, finding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slack.Findings()) != 1 || len(webhook.Findings()) != 2 {
+		t.Errorf("expected only webhook to be notified, got slack=%d webhook=%d", len(slack.Findings()), len(webhook.Findings()))
+	}
+}
+
+// TestMultiNotifier_Failure validates that a failing backend is reported
+// without skipping the backends after it.
+func TestMultiNotifier_Failure(t *testing.T) {
+	slack := NewMemoryNotifier()
+	multi := NewMultiNotifier()
+	multi.Add("pagerduty", failingNotifier{})
+	multi.Add("slack", slack)
+
+	err := multi.Notify(context.Background(), &events.SecurityHubV2Finding{})
+	if err == nil || !strings.Contains(err.Error(), "pagerduty notifier failed") {
+		t.Errorf("expected the pagerduty failure, got %v", err)
+	}
+	if len(slack.Findings()) != 1 {
+		t.Error("expected slack to be notified despite the failure")
+	}
+}
+
+// TestMultiNotifier_Digest validates that a digest is sent as one
+// notification to backends that support it and as one notification per
+// finding to the others.
+func TestMultiNotifier_Digest(t *testing.T) {
+	slack, webhook := NewMemoryNotifier(), NewMemoryNotifier()
+	multi := NewMultiNotifier()
+	multi.Add("slack", slack)
+	multi.Add("webhook", notifyOnly{webhook})
+
+	digest := Digest{
+		Rule:   "close-runners",
+		Status: "Suppressed",
+		Findings: []*events.SecurityHubV2Finding{
+			{Metadata: events.Metadata{UID: "finding-1"}},
+			{Metadata: events.Metadata{UID: "finding-2"}},
+		},
+	}
+	if err := NotifyDigest(context.Background(), multi, digest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if digests := slack.Digests(); len(digests) != 1 || len(digests[0].Findings) != 2 || len(slack.Findings()) != 0 {
+		t.Errorf("expected a single digest for slack, got %d digests and %d findings", len(digests), len(slack.Findings()))
+	}
+	if len(webhook.Findings()) != 2 {
+		t.Errorf("expected each finding for the webhook, got %d", len(webhook.Findings()))
+	}
+
+	ctx := WithSelectedNotifiers(context.Background(), []string{"webhook"})
+	if err := NotifyDigest(ctx, multi, digest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slack.Digests()) != 1 || len(webhook.Findings()) != 4 {
+		t.Errorf("expected only the webhook to be notified, got %d slack digests and %d webhook findings", len(slack.Digests()), len(webhook.Findings()))
+	}
+}
+
+// TestMultiNotifier_RetryFailedOnly validates that resending with the same
+// deliveries context skips the backends that already succeeded, for single
+// notifications and digests.
+func TestMultiNotifier_RetryFailedOnly(t *testing.T) {
+	slack := NewMemoryNotifier()
+	pagerduty := &flakyNotifier{failures: 1}
+	multi := NewMultiNotifier()
+	multi.Add("slack", slack)
+	multi.Add("pagerduty", pagerduty)
+
+	ctx := WithDeliveries(context.Background())
+	finding := &events.SecurityHubV2Finding{Metadata: events.Metadata{UID: "finding-1"}}
+	if err := multi.Notify(ctx, finding); err == nil {
+		t.Fatal("expected the pagerduty failure on the first attempt")
+	}
+	if err := multi.Notify(ctx, finding); err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if len(slack.Findings()) != 1 {
+		t.Errorf("expected slack to be notified once, got %d", len(slack.Findings()))
+	}
+	if pagerduty.calls != 2 {
+		t.Errorf("expected 2 pagerduty attempts, got %d", pagerduty.calls)
+	}
+
+	pagerduty.failures, pagerduty.calls = 1, 0
+	ctx = WithDeliveries(context.Background())
+	digest := Digest{Rule: "close-runners", Findings: []*events.SecurityHubV2Finding{finding}}
+	if err := multi.NotifyDigest(ctx, digest); err == nil {
+		t.Fatal("expected the pagerduty digest failure on the first attempt")
+	}
+	if err := multi.NotifyDigest(ctx, digest); err != nil {
+		t.Fatalf("unexpected error on digest retry: %v", err)
+	}
+	if len(slack.Digests()) != 1 {
+		t.Errorf("expected slack to get the digest once, got %d", len(slack.Digests()))
+	}
+	if pagerduty.calls != 2 {
+		t.Errorf("expected 2 pagerduty digest attempts, got %d", pagerduty.calls)
+	}
+}
@@ -9,3 +9,92 @@ import (
 type Notifier interface {
 	Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error
 }
+
+type consoleRegionKey struct{}
+
+// WithConsoleRegion returns a context overriding the region used in console
+// links for notifications sent with it.
+func WithConsoleRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, consoleRegionKey{}, region)
+}
+
+// ConsoleRegion returns the console link region override from the context,
+// falling back to the given default.
+func ConsoleRegion(ctx context.Context, fallback string) string {
+	if region, ok := ctx.Value(consoleRegionKey{}).(string); ok && region != "" {
+		return region
+	}
+	return fallback
+}
+
+// consoleLinks returns links with the console region override from the
+// context applied.
+func consoleLinks(ctx context.Context, links events.ConsoleLinkOptions) events.ConsoleLinkOptions {
+	links.ConsoleRegion = ConsoleRegion(ctx, links.ConsoleRegion)
+	return links
+}
+
+type matchedRuleKey struct{}
+
+// WithMatchedRule returns a context carrying the name of the auto-close rule
+// that matched the finding being notified.
+func WithMatchedRule(ctx context.Context, rule string) context.Context {
+	return context.WithValue(ctx, matchedRuleKey{}, rule)
+}
+
+// MatchedRule returns the matched rule name from the context, if any.
+func MatchedRule(ctx context.Context) string {
+	rule, _ := ctx.Value(matchedRuleKey{}).(string)
+	return rule
+}
+
+type selectedNotifiersKey struct{}
+
+// WithSelectedNotifiers returns a context limiting a multi-notifier to the
+// named backends, such as those a matched rule selects. an empty list keeps
+// every backend.
+func WithSelectedNotifiers(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, selectedNotifiersKey{}, names)
+}
+
+// SelectedNotifiers returns the backend names from the context, if any.
+func SelectedNotifiers(ctx context.Context) []string {
+	names, _ := ctx.Value(selectedNotifiersKey{}).([]string)
+	return names
+}
+
+type ticketKey struct{}
+
+type ticket struct {
+	id  string
+	url string
+}
+
+// WithTicket returns a context carrying the matched rule's ticket and its
+// link, which may be empty.
+func WithTicket(ctx context.Context, id, url string) context.Context {
+	return context.WithValue(ctx, ticketKey{}, ticket{id: id, url: url})
+}
+
+// Ticket returns the ticket and link from the context, if any.
+func Ticket(ctx context.Context) (id, url string) {
+	t, _ := ctx.Value(ticketKey{}).(ticket)
+	return t.id, t.url
+}
+
+type severityKey struct{}
+
+// WithSeverity returns a context carrying the severity to route the finding
+// by, such as a failed control raised to the compliance severity floor.
+func WithSeverity(ctx context.Context, severity string) context.Context {
+	return context.WithValue(ctx, severityKey{}, severity)
+}
+
+// Severity returns the routing severity from the context, falling back to the
+// finding's own severity.
+func Severity(ctx context.Context, finding *events.SecurityHubV2Finding) string {
+	if severity, ok := ctx.Value(severityKey{}).(string); ok && severity != "" {
+		return severity
+	}
+	return finding.Severity
+}
@@ -0,0 +1,29 @@
+package notifiers
+
+import (
+	"context"
+	"io"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// RenderNotifier writes each finding's Slack message as JSON instead of
+// sending it, for previewing message formatting.
+type RenderNotifier struct {
+	slack *SlackNotifier
+	w     io.Writer
+}
+
+func NewRenderNotifier(slack *SlackNotifier, w io.Writer) *RenderNotifier {
+	return &RenderNotifier{slack: slack, w: w}
+}
+
+func (r *RenderNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	rendered, err := r.slack.Render(ctx, finding)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.w.Write(append(rendered, '\n'))
+	return err
+}
@@ -0,0 +1,81 @@
+// Package notifiers tests rendering Slack messages without sending them.
+//
+// Tests cover:
+// - Rendered JSON contains the header block and view finding button
+// - RenderNotifier writes one JSON document per finding
+// - Uses fixtures/samples.json for realistic OCSF findings
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newRenderSlackNotifier() *SlackNotifier {
+	return NewSlackNotifier(SlackOptions{
+		Channel:             "C01234TEST",
+		ConsoleURL:          "https://console.aws.amazon.com",
+		SecurityHubv2Region: "us-east-1",
+	})
+}
+
+// TestSlackNotifier_Render validates that the rendered payload contains the
+// header block and the view finding button.
+func TestSlackNotifier_Render(t *testing.T) {
+	finding := loadSampleFinding(t, 1)
+
+	rendered, err := newRenderSlackNotifier().Render(context.Background(), finding)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var payload struct {
+		Channel string           `json:"channel"`
+		Text    string           `json:"text"`
+		Blocks  []map[string]any `json:"blocks"`
+	}
+	if err := json.Unmarshal(rendered, &payload); err != nil {
+		t.Fatalf("rendered output is not valid JSON: %v", err)
+	}
+
+	if payload.Channel != "C01234TEST" {
+		t.Errorf("expected channel C01234TEST, got %q", payload.Channel)
+	}
+	if len(payload.Blocks) == 0 || payload.Blocks[0]["type"] != "header" {
+		t.Fatalf("expected first block to be a header, got %v", payload.Blocks)
+	}
+
+	header, _ := payload.Blocks[0]["text"].(map[string]any)
+	if text, _ := header["text"].(string); !strings.Contains(text, finding.FindingInfo.Title) {
+		t.Errorf("expected header to contain finding title, got %q", text)
+	}
+	if !strings.Contains(string(rendered), `"action_id":"view_finding"`) {
+		t.Errorf("expected rendered blocks to contain view_finding button, got %s", rendered)
+	}
+}
+
+// TestRenderNotifier_Notify validates that each notification is written as a
+// single line of JSON.
+func TestRenderNotifier_Notify(t *testing.T) {
+	var buf bytes.Buffer
+	notifier := NewRenderNotifier(newRenderSlackNotifier(), &buf)
+
+	for _, index := range []int{0, 1} {
+		if err := notifier.Notify(context.Background(), loadSampleFinding(t, index)); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 rendered lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("expected valid JSON line, got %s", line)
+		}
+	}
+}
@@ -1,47 +1,376 @@
 package notifiers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/cockroachdb/errors"
 	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/locale"
 	"github.com/slack-go/slack"
 )
 
+// ChannelRoute sends findings matching every set condition to Channel
+// instead of the default channel. MinSeverity is expanded into Severities
+// when the config is parsed.
+type ChannelRoute struct {
+	Severities  []string `json:"severities,omitempty"`
+	MinSeverity string   `json:"min_severity,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	Channel     string   `json:"channel"`
+}
+
+// matches reports whether the route applies to a finding with the given
+// routing severity.
+func (r *ChannelRoute) matches(finding *events.SecurityHubV2Finding, severity string) bool {
+	if len(r.Severities) > 0 && !slices.ContainsFunc(r.Severities, func(s string) bool {
+		return strings.EqualFold(s, severity)
+	}) {
+		return false
+	}
+	if len(r.Regions) > 0 && !slices.Contains(r.Regions, finding.Cloud.Region) {
+		return false
+	}
+	return true
+}
+
+// specific reports whether the route combines severity and region.
+func (r *ChannelRoute) specific() bool {
+	return len(r.Severities) > 0 && len(r.Regions) > 0
+}
+
 type SlackNotifier struct {
 	client              *slack.Client
 	channel             string
+	channelRoutes       []ChannelRoute
 	consoleURL          string
 	accessPortalURL     string
 	accessRoleName      string
+	accessRoleNames     map[string]string
+	consoleRegion       string
 	securityHubv2Region string
+	footer              string
+	categoryMappings    []events.CategoryMapping
+	fields              []string
+	categoryFields      map[string][]string
+	maxBlocks           int
+	locale              locale.Locale
+
+	// attachRaw uploads the raw finding as a reply to each message, logging
+	// failures to logger
+	attachRaw bool
+	logger    *slog.Logger
 }
 
-func NewSlackNotifier(token, channel, consoleURL, accessPortalURL, accessRoleName, securityHubv2Region string) *SlackNotifier {
+// SlackOptions configures a Slack notifier. AccessRoleNames overrides
+// AccessRoleName per account id, nil CategoryMappings and Fields use the
+// defaults, nil ChannelRoutes send everything to Channel and a nil HTTPClient
+// uses the Slack library default.
+type SlackOptions struct {
+	Token               string
+	Channel             string
+	ConsoleURL          string
+	AccessPortalURL     string
+	AccessRoleName      string
+	AccessRoleNames     map[string]string
+	ConsoleRegion       string
+	SecurityHubv2Region string
+	Footer              string
+	CategoryMappings    []events.CategoryMapping
+	Fields              []string
+	ChannelRoutes       []ChannelRoute
+	HTTPClient          *http.Client
+}
+
+// NewSlackNotifier creates a Slack notifier from opts.
+func NewSlackNotifier(o SlackOptions) *SlackNotifier {
 	// allow overriding slack api url for testing
 	opts := []slack.Option{}
 	if apiURL := os.Getenv("SLACK_API_URL"); apiURL != "" {
 		opts = append(opts, slack.OptionAPIURL(apiURL+"/"))
 	}
+	if o.HTTPClient != nil {
+		opts = append(opts, slack.OptionHTTPClient(o.HTTPClient))
+	}
 
 	return &SlackNotifier{
-		client:              slack.New(token, opts...),
-		channel:             channel,
-		consoleURL:          consoleURL,
-		accessPortalURL:     accessPortalURL,
-		accessRoleName:      accessRoleName,
-		securityHubv2Region: securityHubv2Region,
+		client:              slack.New(o.Token, opts...),
+		channel:             o.Channel,
+		channelRoutes:       o.ChannelRoutes,
+		consoleURL:          o.ConsoleURL,
+		accessPortalURL:     o.AccessPortalURL,
+		accessRoleName:      o.AccessRoleName,
+		accessRoleNames:     o.AccessRoleNames,
+		consoleRegion:       o.ConsoleRegion,
+		securityHubv2Region: o.SecurityHubv2Region,
+		footer:              o.Footer,
+		categoryMappings:    o.CategoryMappings,
+		fields:              o.Fields,
+	}
+}
+
+// Verify checks the token with auth.test so a bad token fails at startup
+// instead of on the first notification.
+func (s *SlackNotifier) Verify(ctx context.Context) error {
+	if _, err := s.client.AuthTestContext(ctx); err != nil {
+		return errors.Wrap(err, "slack auth.test failed")
+	}
+	return nil
+}
+
+// slackChannelID matches conversation ids, which are passed through as-is.
+var slackChannelID = regexp.MustCompile(`^[CGD][A-Z0-9]{8,}$`)
+
+// ResolveChannels replaces channel names in the default channel and routes,
+// such as "#sec-alerts", with their ids from conversations.list. names are
+// looked up once, so it needs the channels:read scope (groups:read for
+// private channels) only when a name is configured.
+func (s *SlackNotifier) ResolveChannels(ctx context.Context) error {
+	names := []*string{&s.channel}
+	for i := range s.channelRoutes {
+		names = append(names, &s.channelRoutes[i].Channel)
 	}
+
+	var ids map[string]string
+	for _, channel := range names {
+		if *channel == "" || slackChannelID.MatchString(*channel) {
+			continue
+		}
+		if ids == nil {
+			var err error
+			if ids, err = s.listChannelIDs(ctx); err != nil {
+				return err
+			}
+		}
+		id, ok := ids[strings.TrimPrefix(*channel, "#")]
+		if !ok {
+			return errors.Newf("slack channel %q not found", *channel)
+		}
+		*channel = id
+	}
+	return nil
+}
+
+// listChannelIDs maps every unarchived channel name the token can see to its
+// id.
+func (s *SlackNotifier) listChannelIDs(ctx context.Context) (map[string]string, error) {
+	ids := make(map[string]string)
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: true,
+		Limit:           1000,
+		Types:           []string{"public_channel", "private_channel"},
+	}
+	for {
+		channels, cursor, err := s.client.GetConversationsContext(ctx, params)
+		if err != nil {
+			return nil, errors.Wrap(err, "slack conversations.list failed")
+		}
+		for _, channel := range channels {
+			ids[channel.Name] = channel.ID
+		}
+		if cursor == "" {
+			return ids, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+func (s *SlackNotifier) messageOptions(ctx context.Context) events.SlackMessageOptions {
+	ticket, ticketURL := Ticket(ctx)
+	return events.SlackMessageOptions{
+		ConsoleLinkOptions: consoleLinks(ctx, events.ConsoleLinkOptions{
+			ConsoleURL:        s.consoleURL,
+			AccessPortalURL:   s.accessPortalURL,
+			AccessRoleName:    s.accessRoleName,
+			AccessRoleNames:   s.accessRoleNames,
+			ConsoleRegion:     s.consoleRegion,
+			SecurityHubRegion: s.securityHubv2Region,
+			CategoryMappings:  s.categoryMappings,
+		}),
+		Footer:         s.footer,
+		Fields:         s.fields,
+		CategoryFields: s.categoryFields,
+		Ticket:         ticket,
+		TicketURL:      ticketURL,
+		MaxBlocks:      s.maxBlocks,
+	}
+}
+
+// channelFor returns the channel for a finding, routed by its severity from
+// the context. a route matching both severity and region wins over
+// single-condition routes, then earlier routes win, and the default channel is
+// used when nothing matches.
+func (s *SlackNotifier) channelFor(ctx context.Context, finding *events.SecurityHubV2Finding) string {
+	severity := Severity(ctx, finding)
+	var fallback *ChannelRoute
+	for i := range s.channelRoutes {
+		route := &s.channelRoutes[i]
+		if !route.matches(finding, severity) {
+			continue
+		}
+		if route.specific() {
+			return route.Channel
+		}
+		if fallback == nil {
+			fallback = route
+		}
+	}
+	if fallback != nil {
+		return fallback.Channel
+	}
+	return s.channel
+}
+
+// AttachRaw makes Notify upload the raw finding json as a snippet in the
+// message's thread, which needs the files:write scope. upload failures are
+// logged to logger since the message itself was sent.
+func (s *SlackNotifier) AttachRaw(logger *slog.Logger) {
+	s.attachRaw = true
+	s.logger = logger
+}
+
+// UseCategoryFields renders findings of the given categories with their own
+// field lists instead of the notifier's fields.
+func (s *SlackNotifier) UseCategoryFields(categoryFields map[string][]string) {
+	s.categoryFields = categoryFields
+}
+
+// UseLocale sets the language of bot-generated phrases such as digest titles.
+func (s *SlackNotifier) UseLocale(loc locale.Locale) {
+	s.locale = loc
+}
+
+// LimitBlocks caps messages below Slack's block limit, dropping
+// lower-priority blocks first. 0 uses Slack's limit.
+func (s *SlackNotifier) LimitBlocks(maxBlocks int) {
+	s.maxBlocks = maxBlocks
 }
 
 func (s *SlackNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
-	m0, m1 := finding.SlackMessage(
-		s.consoleURL,
-		s.accessPortalURL,
-		s.accessRoleName,
-		s.securityHubv2Region,
-	)
-
-	_, _, err := s.client.PostMessage(s.channel, m0, m1)
+	m0, m1 := finding.SlackMessage(s.messageOptions(ctx))
+
+	channel, ts, err := s.client.PostMessageContext(ctx, s.channelFor(ctx, finding), m0, m1)
+	if err != nil {
+		return err
+	}
+
+	if s.attachRaw && len(finding.Raw) > 0 {
+		if err := s.uploadRaw(ctx, channel, ts, finding); err != nil {
+			s.logger.Warn("failed to attach raw finding to slack message",
+				"uid", finding.Metadata.UID,
+				"channel", channel,
+				"error", err)
+		}
+	}
+	return nil
+}
+
+// MaxDigestFindings caps the findings listed in a Slack digest message.
+const MaxDigestFindings = 10
+
+// NotifyDigest posts one message summarizing the findings a rule closed,
+// routed like the digest's first finding.
+func (s *SlackNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	if len(digest.Findings) == 0 {
+		return nil
+	}
+
+	title := fmt.Sprintf(s.locale.Text(locale.DigestTitle), len(digest.Findings), digest.Rule)
+	_, _, err := s.client.PostMessageContext(ctx, s.channelFor(ctx, digest.Findings[0]),
+		slack.MsgOptionText(title, false),
+		slack.MsgOptionBlocks(s.digestBlocks(ctx, title, digest)...))
 	return err
 }
+
+func (s *SlackNotifier) digestBlocks(ctx context.Context, title string, digest Digest) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", title, false, false)),
+	}
+
+	var lines []string
+	size := 0
+	for _, finding := range digest.Findings {
+		line := fmt.Sprintf("%s %s (`%s`)", finding.GetSeverityEmoji(), finding.FindingInfo.Title, finding.Cloud.Account.UID)
+		if len(lines) == MaxDigestFindings || size+len(line)+1 > events.MaxSectionText-len("_and 00000 more_") {
+			break
+		}
+		lines = append(lines, line)
+		size += len(line) + 1
+	}
+	if more := len(digest.Findings) - len(lines); more > 0 {
+		lines = append(lines, fmt.Sprintf("_and %d more_", more))
+	}
+	blocks = append(blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false),
+		nil, nil,
+	))
+
+	details := []string{"*Status:* " + digest.Status}
+	if ticket, ticketURL := Ticket(ctx); ticket != "" {
+		if ticketURL != "" {
+			ticket = fmt.Sprintf("<%s|%s>", ticketURL, ticket)
+		}
+		details = append(details, "*Ticket:* "+ticket)
+	}
+	blocks = append(blocks, slack.NewContextBlock(
+		"digest",
+		slack.NewTextBlockObject("mrkdwn", strings.Join(details, "  |  "), false, false),
+	))
+
+	if s.footer != "" {
+		blocks = append(blocks, slack.NewContextBlock(
+			"footer",
+			slack.NewTextBlockObject("mrkdwn", s.footer, false, false),
+		))
+	}
+	return blocks
+}
+
+// uploadRaw replies to the message at ts with the indented raw finding.
+func (s *SlackNotifier) uploadRaw(ctx context.Context, channel, ts string, finding *events.SecurityHubV2Finding) error {
+	var body bytes.Buffer
+	if err := json.Indent(&body, finding.Raw, "", "  "); err != nil {
+		body.Reset()
+		body.Write(finding.Raw)
+	}
+
+	_, err := s.client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Content:         body.String(),
+		FileSize:        body.Len(),
+		Filename:        "finding.json",
+		Title:           "Raw finding",
+		SnippetType:     "json",
+		Channel:         channel,
+		ThreadTimestamp: ts,
+	})
+	return errors.Wrap(err, "failed to upload raw finding")
+}
+
+// Render returns the chat.postMessage payload for a finding as JSON without
+// calling Slack.
+func (s *SlackNotifier) Render(ctx context.Context, finding *events.SecurityHubV2Finding) ([]byte, error) {
+	m0, m1 := finding.SlackMessage(s.messageOptions(ctx))
+
+	_, values, err := slack.UnsafeApplyMsgOptions("", s.channelFor(ctx, finding), "", m0, m1)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply slack message options")
+	}
+
+	return json.Marshal(struct {
+		Channel string          `json:"channel"`
+		Text    string          `json:"text"`
+		Blocks  json.RawMessage `json:"blocks"`
+	}{
+		Channel: values.Get("channel"),
+		Text:    values.Get("text"),
+		Blocks:  json.RawMessage(values.Get("blocks")),
+	})
+}
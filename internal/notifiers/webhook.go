@@ -0,0 +1,122 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+// WebhookSchemaVersion is the version of the webhook payload contract.
+// additive changes bump the minor version, breaking changes the major.
+const WebhookSchemaVersion = "1.0"
+
+// WebhookPayload is the versioned JSON body posted to webhooks.
+type WebhookPayload struct {
+	SchemaVersion string         `json:"schema_version"`
+	Finding       WebhookFinding `json:"finding"`
+	ConsoleURL    string         `json:"console_url"`
+	MatchedRule   string         `json:"matched_rule,omitempty"`
+	SeverityEmoji string         `json:"severity_emoji"`
+}
+
+// WebhookFinding is the stable subset of finding fields in the payload.
+type WebhookFinding struct {
+	UID         string   `json:"uid"`
+	ProductUID  string   `json:"product_uid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Types       []string `json:"types"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	SeverityID  int      `json:"severity_id"`
+	Status      string   `json:"status"`
+	StatusID    int      `json:"status_id"`
+	ProductName string   `json:"product_name"`
+	AccountUID  string   `json:"account_uid"`
+	Region      string   `json:"region"`
+	Resources   []string `json:"resources"`
+}
+
+type WebhookNotifier struct {
+	url    string
+	links  events.ConsoleLinkOptions
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that posts a WebhookPayload to url,
+// with console links built from links. a nil httpClient uses
+// http.DefaultClient.
+func NewWebhookNotifier(url string, links events.ConsoleLinkOptions, httpClient *http.Client) *WebhookNotifier {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &WebhookNotifier{
+		url:    url,
+		links:  links,
+		client: httpClient,
+	}
+}
+
+// Payload builds the webhook body for a finding.
+func (w *WebhookNotifier) Payload(ctx context.Context, finding *events.SecurityHubV2Finding) WebhookPayload {
+	types := finding.FindingInfo.Types
+	if types == nil {
+		types = []string{}
+	}
+
+	return WebhookPayload{
+		SchemaVersion: WebhookSchemaVersion,
+		Finding: WebhookFinding{
+			UID:         finding.Metadata.UID,
+			ProductUID:  finding.FindingInfo.UID,
+			Title:       finding.FindingInfo.Title,
+			Description: finding.FindingInfo.Desc,
+			Types:       types,
+			Category:    finding.FindingCategory(w.links.CategoryMappings),
+			Severity:    finding.Severity,
+			SeverityID:  finding.SeverityID,
+			Status:      finding.Status,
+			StatusID:    finding.StatusID,
+			ProductName: finding.Metadata.Product.Name,
+			AccountUID:  finding.Cloud.Account.UID,
+			Region:      finding.Cloud.Region,
+			Resources:   finding.ResourceUIDs(),
+		},
+		ConsoleURL:    finding.BuildConsoleUrl(consoleLinks(ctx, w.links)),
+		MatchedRule:   MatchedRule(ctx),
+		SeverityEmoji: finding.GetSeverityEmoji(),
+	}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, finding *events.SecurityHubV2Finding) error {
+	body, err := json.Marshal(w.Payload(ctx, finding))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal webhook payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send webhook")
+	}
+	defer resp.Body.Close()
+	// drained only so the connection can be reused. the status decides the
+	// result, so a failed read is ignored
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Newf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package notifiers tests the webhook notifier and its versioned payload.
+//
+// Tests cover:
+// - Payload includes schema_version and the required finding fields
+// - Computed fields (console_url, matched_rule, severity_emoji)
+// - Non-2xx responses are errors
+// - Uses fixtures/samples.json for realistic OCSF findings
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+func loadSampleFinding(t *testing.T, index int) *events.SecurityHubV2Finding {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "fixtures", "samples.json"))
+	if err != nil {
+		t.Fatalf("failed to read samples: %v", err)
+	}
+
+	var findings []json.RawMessage
+	if err := json.Unmarshal(raw, &findings); err != nil {
+		t.Fatalf("failed to unmarshal samples: %v", err)
+	}
+
+	finding, err := events.NewSecurityHubFinding(findings[index])
+	if err != nil {
+		t.Fatalf("failed to parse finding %d: %v", index, err)
+	}
+	return finding
+}
+
+// TestWebhookNotifier_Payload validates that the posted payload carries the
+// schema version, the stable finding fields and the computed fields.
+func TestWebhookNotifier_Payload(t *testing.T) {
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, events.ConsoleLinkOptions{
+		ConsoleURL:        "https://console.aws.amazon.com",
+		SecurityHubRegion: "us-east-1",
+	}, nil)
+	finding := loadSampleFinding(t, 2)
+
+	ctx := WithMatchedRule(context.Background(), "close-runs-on")
+	if err := notifier.Notify(ctx, finding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("unexpected content type: %s", contentType)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid payload JSON: %v", err)
+	}
+
+	if payload["schema_version"] != WebhookSchemaVersion {
+		t.Errorf("expected schema_version %s, got %v", WebhookSchemaVersion, payload["schema_version"])
+	}
+
+	if payload["matched_rule"] != "close-runs-on" {
+		t.Errorf("unexpected matched_rule: %v", payload["matched_rule"])
+	}
+
+	if payload["severity_emoji"] != finding.GetSeverityEmoji() {
+		t.Errorf("unexpected severity_emoji: %v", payload["severity_emoji"])
+	}
+
+	if url, _ := payload["console_url"].(string); !strings.Contains(url, "findingDetailId="+finding.Metadata.UID) {
+		t.Errorf("unexpected console_url: %v", payload["console_url"])
+	}
+
+	fields, ok := payload["finding"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected finding object, got %v", payload["finding"])
+	}
+
+	required := []string{
+		"uid", "product_uid", "title", "description", "types", "category", "severity",
+		"severity_id", "status", "status_id", "product_name", "account_uid", "region", "resources",
+	}
+	for _, field := range required {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("missing required finding field %s", field)
+		}
+	}
+
+	if fields["uid"] != finding.Metadata.UID || fields["product_name"] != "GuardDuty" {
+		t.Errorf("unexpected finding fields: %v", fields)
+	}
+}
+
+// TestWebhookNotifier_Payload_NoRule validates that matched_rule is omitted
+// for findings notified without a rule match.
+func TestWebhookNotifier_Payload_NoRule(t *testing.T) {
+	notifier := NewWebhookNotifier("http://unused", events.ConsoleLinkOptions{ConsoleURL: "https://console.aws.amazon.com"}, nil)
+
+	body, err := json.Marshal(notifier.Payload(context.Background(), &events.SecurityHubV2Finding{Severity: "High"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(body), "matched_rule") {
+		t.Errorf("expected matched_rule to be omitted, got %s", body)
+	}
+
+	if !strings.Contains(string(body), `"types":[]`) || !strings.Contains(string(body), `"resources":[]`) {
+		t.Errorf("expected empty lists rather than null, got %s", body)
+	}
+}
+
+// TestWebhookNotifier_ErrorStatus validates that non-2xx responses fail the
+// notification.
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, events.ConsoleLinkOptions{}, nil)
+	if err := notifier.Notify(context.Background(), &events.SecurityHubV2Finding{Severity: "High"}); err == nil {
+		t.Error("expected error for 500 response")
+	}
+}
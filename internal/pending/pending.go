@@ -0,0 +1,49 @@
+// Package pending tracks findings held back by a rule's close_after_seconds
+// grace period, keyed by finding uid.
+package pending
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store records when the bot first matched a finding to a delayed-close rule.
+type Store interface {
+	// Get returns the first matched time, and false if the finding isn't pending
+	Get(ctx context.Context, uid string) (time.Time, bool, error)
+	Put(ctx context.Context, uid string, firstMatched time.Time) error
+	Delete(ctx context.Context, uid string) error
+}
+
+// MemoryStore keeps pending findings in memory. in lambda they only survive
+// while the execution environment stays warm.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, uid string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.entries[uid]
+	return t, ok, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, uid string, firstMatched time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[uid] = firstMatched
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, uid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, uid)
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package pending tests the grace period stores for delayed closes.
+//
+// Tests cover:
+// - Memory store get, put and delete
+// - S3 store keys, round-trips and missing objects
+package pending
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const testUID = "arn:aws:guardduty:us-east-1:123456789012:detector/abc/finding/123"
+
+// TestMemoryStore validates that a put time is returned until deleted.
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, ok, _ := store.Get(ctx, testUID); ok {
+		t.Fatal("expected empty store")
+	}
+
+	if err := store.Put(ctx, testUID, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok, _ := store.Get(ctx, testUID); !ok || !got.Equal(now) {
+		t.Errorf("expected %v, got %v (found=%v)", now, got, ok)
+	}
+
+	if err := store.Delete(ctx, testUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := store.Get(ctx, testUID); ok {
+		t.Error("expected finding to be cleared")
+	}
+}
+
+type mockS3Client struct {
+	objects map[string][]byte
+}
+
+func (m *mockS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	body, ok := m.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(params.Body)
+	m.objects[aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(m.objects, aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// TestS3Store validates that pending closes round-trip through hashed object
+// keys and that a missing object means the finding isn't pending.
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	client := &mockS3Client{objects: map[string][]byte{}}
+	store := NewS3Store(client, "state-bucket", "pending/")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	key := store.Key(testUID)
+	if !strings.HasPrefix(key, "pending/") || !strings.HasSuffix(key, ".json") || strings.Contains(strings.TrimPrefix(key, "pending/"), "/") {
+		t.Errorf("unexpected key: %s", key)
+	}
+
+	if _, ok, err := store.Get(ctx, testUID); err != nil || ok {
+		t.Fatalf("expected missing object to not be pending, got found=%v err=%v", ok, err)
+	}
+
+	if err := store.Put(ctx, testUID, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(client.objects[key]), testUID) {
+		t.Errorf("expected object to record the uid, got %s", client.objects[key])
+	}
+
+	got, ok, err := store.Get(ctx, testUID)
+	if err != nil || !ok || !got.Equal(now) {
+		t.Errorf("expected %v, got %v (found=%v, err=%v)", now, got, ok, err)
+	}
+
+	if err := store.Delete(ctx, testUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.objects) != 0 {
+		t.Errorf("expected object to be deleted, got %d objects", len(client.objects))
+	}
+}
@@ -0,0 +1,95 @@
+package pending
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cockroachdb/errors"
+)
+
+type S3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// S3Store keeps one object per pending finding, so the grace period survives
+// across lambda invocations.
+type S3Store struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+func NewS3Store(client S3Client, bucket, prefix string) *S3Store {
+	return &S3Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+type s3Entry struct {
+	UID          string    `json:"uid"`
+	FirstMatched time.Time `json:"first_matched"`
+}
+
+func (s *S3Store) Get(ctx context.Context, uid string) (time.Time, bool, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.Key(uid)),
+	})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, errors.Wrapf(err, "failed to read pending close from s3://%s", s.bucket)
+	}
+	defer out.Body.Close()
+
+	var entry s3Entry
+	if err := json.NewDecoder(out.Body).Decode(&entry); err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "invalid pending close object %s", s.Key(uid))
+	}
+	return entry.FirstMatched, true, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, uid string, firstMatched time.Time) error {
+	data, err := json.Marshal(s3Entry{UID: uid, FirstMatched: firstMatched.UTC()})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal pending close")
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.Key(uid)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write pending close to s3://%s", s.bucket)
+	}
+	return nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, uid string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.Key(uid)),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete pending close from s3://%s", s.bucket)
+	}
+	return nil
+}
+
+// Key returns the object key for a finding uid. uids are arns or similar, so
+// they are hashed rather than used as paths.
+func (s *S3Store) Key(uid string) string {
+	sum := sha256.Sum256([]byte(uid))
+	return s.prefix + hex.EncodeToString(sum[:]) + ".json"
+}
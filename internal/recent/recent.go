@@ -0,0 +1,79 @@
+// Package recent remembers recently seen keys for a TTL in a bounded LRU, so
+// a warm lambda can skip repeat work without an external store.
+package recent
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a size-bounded LRU of keys and when they were added. callers pass
+// the time, so tests can use a fake clock.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List
+	items map[string]*list.Element
+}
+
+type item struct {
+	key   string
+	added time.Time
+}
+
+// NewCache creates a cache holding up to size keys, each for ttl.
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Seen reports whether key was added less than the ttl before now. expired
+// keys are dropped.
+func (c *Cache) Seen(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if now.Sub(el.Value.(*item).added) >= c.ttl {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return false
+	}
+	c.order.MoveToFront(el)
+	return true
+}
+
+// Add records key at now, evicting the least recently used key when full.
+func (c *Cache) Add(key string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).added = now
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&item{key: key, added: now})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
+
+// Len returns the number of keys held, including expired ones not yet dropped.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
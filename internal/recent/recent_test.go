@@ -0,0 +1,60 @@
+// Package recent tests the TTL-bounded LRU cache.
+//
+// Tests cover:
+// - Keys are seen within the ttl and expire after it
+// - Re-adding a key restarts its ttl
+// - The least recently used key is evicted when full
+package recent
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCache_TTL validates that a key is seen until its ttl elapses.
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCache(10, time.Minute)
+
+	if c.Seen("a", now) {
+		t.Fatal("expected empty cache")
+	}
+
+	c.Add("a", now)
+	if !c.Seen("a", now.Add(59*time.Second)) {
+		t.Error("expected key to be seen within the ttl")
+	}
+	if c.Seen("a", now.Add(time.Minute)) {
+		t.Error("expected key to expire after the ttl")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired key to be dropped, got %d keys", c.Len())
+	}
+
+	c.Add("a", now)
+	c.Add("a", now.Add(50*time.Second))
+	if !c.Seen("a", now.Add(90*time.Second)) {
+		t.Error("expected re-adding to restart the ttl")
+	}
+}
+
+// TestCache_Eviction validates that the least recently used key is evicted.
+func TestCache_Eviction(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCache(2, time.Hour)
+
+	c.Add("a", now)
+	c.Add("b", now)
+	c.Seen("a", now)
+	c.Add("c", now)
+
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 keys, got %d", c.Len())
+	}
+	if c.Seen("b", now) {
+		t.Error("expected least recently used key to be evicted")
+	}
+	if !c.Seen("a", now) || !c.Seen("c", now) {
+		t.Error("expected recently used keys to remain")
+	}
+}
@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+const (
+	ScopeName   = "github.com/cruxstack/aws-securityhubv2-bot"
+	ServiceName = "aws-securityhubv2-bot"
+
+	ProcessSpan = "Process"
+	FindingSpan = "ProcessFinding"
+
+	FindingsCounter = "securityhubv2bot.findings"
+	RuleHitsCounter = "securityhubv2bot.rule_hits"
+)
+
+var (
+	AttrEventID         = attribute.Key("event.id")
+	AttrFindingCount    = attribute.Key("finding.count")
+	AttrFindingUID      = attribute.Key("finding.uid")
+	AttrFindingSeverity = attribute.Key("finding.severity")
+	AttrRule            = attribute.Key("rule.name")
+	AttrAction          = attribute.Key("rule.action")
+)
+
+// Telemetry records a span per Process call, a child span per finding and
+// counters for findings and rule hits. a nil Telemetry is a no-op.
+type Telemetry struct {
+	tracer   trace.Tracer
+	findings metric.Int64Counter
+	ruleHits metric.Int64Counter
+	flushers []func(context.Context) error
+}
+
+func NewTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*Telemetry, error) {
+	meter := mp.Meter(ScopeName)
+
+	findings, err := meter.Int64Counter(FindingsCounter,
+		metric.WithDescription("Findings processed"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create findings counter")
+	}
+
+	ruleHits, err := meter.Int64Counter(RuleHitsCounter,
+		metric.WithDescription("Findings matched by a rule, by action"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create rule hits counter")
+	}
+
+	return &Telemetry{
+		tracer:   tp.Tracer(ScopeName),
+		findings: findings,
+		ruleHits: ruleHits,
+	}, nil
+}
+
+// NewOTLP exports spans and metrics over OTLP/HTTP. the exporters read the
+// standard OTEL_EXPORTER_OTLP_* environment variables.
+func NewOTLP(ctx context.Context) (*Telemetry, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build otel resource")
+	}
+
+	spanExporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp trace exporter")
+	}
+
+	metricExporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp metric exporter")
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	t, err := NewTelemetry(tp, mp)
+	if err != nil {
+		return nil, err
+	}
+	t.flushers = []func(context.Context) error{tp.ForceFlush, mp.ForceFlush}
+	return t, nil
+}
+
+func (t *Telemetry) StartProcess(ctx context.Context, eventID string, findings int) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, noop.Span{}
+	}
+	return t.tracer.Start(ctx, ProcessSpan, trace.WithAttributes(
+		AttrEventID.String(eventID),
+		AttrFindingCount.Int(findings),
+	))
+}
+
+func (t *Telemetry) StartFinding(ctx context.Context, finding *events.SecurityHubV2Finding) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, noop.Span{}
+	}
+
+	t.findings.Add(ctx, 1, metric.WithAttributes(AttrFindingSeverity.String(finding.Severity)))
+
+	return t.tracer.Start(ctx, FindingSpan, trace.WithAttributes(
+		AttrFindingUID.String(finding.Metadata.UID),
+		AttrFindingSeverity.String(finding.Severity),
+	))
+}
+
+// RecordRuleHit sets the matched rule and action on the current finding span
+// and counts the hit.
+func (t *Telemetry) RecordRuleHit(ctx context.Context, ruleName, action, severity string) {
+	if t == nil {
+		return
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(AttrRule.String(ruleName), AttrAction.String(action))
+	t.ruleHits.Add(ctx, 1, metric.WithAttributes(
+		AttrRule.String(ruleName),
+		AttrAction.String(action),
+		AttrFindingSeverity.String(severity),
+	))
+}
+
+// Flush exports buffered spans and metrics. lambda may freeze the process
+// between events, so this runs at the end of each one.
+func (t *Telemetry) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, flush := range t.flushers {
+		if err := flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
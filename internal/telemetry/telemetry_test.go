@@ -0,0 +1,143 @@
+// Package telemetry tests OpenTelemetry spans and counters.
+//
+// Tests cover:
+// - Process and finding spans with finding and rule attributes
+// - Findings and rule hit counters
+// - A nil Telemetry is a no-op
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cruxstack/aws-securityhubv2-bot/internal/events"
+)
+
+func newTestTelemetry(t *testing.T) (*Telemetry, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+
+	tel, err := NewTelemetry(
+		sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	if err != nil {
+		t.Fatalf("NewTelemetry failed: %v", err)
+	}
+	return tel, exporter, reader
+}
+
+func spanAttributes(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	out := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+// TestTelemetry_Spans validates that the finding span is a child of the
+// process span and carries the finding and rule attributes.
+func TestTelemetry_Spans(t *testing.T) {
+	tel, exporter, _ := newTestTelemetry(t)
+
+	finding := &events.SecurityHubV2Finding{Severity: "High"}
+	finding.Metadata.UID = "finding-1"
+
+	ctx, process := tel.StartProcess(context.Background(), "event-1", 1)
+	findingCtx, span := tel.StartFinding(ctx, finding)
+	tel.RecordRuleHit(findingCtx, "close-dev", "closed", finding.Severity)
+	span.End()
+	process.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	findingSpan, processSpan := spans[0], spans[1]
+	if findingSpan.Name != FindingSpan || processSpan.Name != ProcessSpan {
+		t.Fatalf("unexpected span names: %q, %q", findingSpan.Name, processSpan.Name)
+	}
+	if findingSpan.Parent.SpanID() != processSpan.SpanContext.SpanID() {
+		t.Error("expected finding span to be a child of the process span")
+	}
+
+	attrs := spanAttributes(findingSpan.Attributes)
+	expected := map[attribute.Key]string{
+		AttrFindingUID:      "finding-1",
+		AttrFindingSeverity: "High",
+		AttrRule:            "close-dev",
+		AttrAction:          "closed",
+	}
+	for key, want := range expected {
+		if got := attrs[key].AsString(); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+
+	if got := spanAttributes(processSpan.Attributes)[AttrEventID].AsString(); got != "event-1" {
+		t.Errorf("expected event id event-1, got %q", got)
+	}
+}
+
+// TestTelemetry_Counters validates that findings and rule hits are counted.
+func TestTelemetry_Counters(t *testing.T) {
+	tel, _, reader := newTestTelemetry(t)
+	ctx := context.Background()
+
+	finding := &events.SecurityHubV2Finding{Severity: "Low"}
+	for range 2 {
+		findingCtx, span := tel.StartFinding(ctx, finding)
+		tel.RecordRuleHit(findingCtx, "close-dev", "skipped", finding.Severity)
+		span.End()
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+
+	totals := map[string]int64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("expected int64 sum for %s", m.Name)
+			}
+			for _, dp := range sum.DataPoints {
+				totals[m.Name] += dp.Value
+			}
+		}
+	}
+
+	if totals[FindingsCounter] != 2 || totals[RuleHitsCounter] != 2 {
+		t.Errorf("unexpected counter totals: %v", totals)
+	}
+}
+
+// TestTelemetry_Nil validates that a nil Telemetry does nothing.
+func TestTelemetry_Nil(t *testing.T) {
+	var tel *Telemetry
+	ctx := context.Background()
+
+	ctx, span := tel.StartProcess(ctx, "event-1", 1)
+	_, findingSpan := tel.StartFinding(ctx, &events.SecurityHubV2Finding{})
+	tel.RecordRuleHit(ctx, "rule", "closed", "Low")
+	findingSpan.End()
+	span.End()
+
+	if span.SpanContext().IsValid() {
+		t.Error("expected a no-op span")
+	}
+	if err := tel.Flush(ctx); err != nil {
+		t.Errorf("expected nil flush error, got %v", err)
+	}
+}